@@ -0,0 +1,9 @@
+data "fastly_current_user" "me" {}
+
+output "fastly_customer_id" {
+  value = data.fastly_current_user.me.customer_id
+}
+
+output "fastly_token_expires_at" {
+  value = data.fastly_current_user.me.token_expires_at
+}
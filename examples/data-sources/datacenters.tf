@@ -9,3 +9,21 @@ output "fastly_datacenters_filtered" {
   # get the shield code of "TYO" POP
   value = one([for pop in data.fastly_datacenters.fastly.pops : pop.shield if pop["code"] == "TYO"])
 }
+
+data "fastly_datacenters" "near_origin" {
+  shield_only = true
+
+  near {
+    cloud_region = "aws:us-east-1"
+  }
+}
+
+resource "fastly_service_vcl" "example" {
+  # ...
+
+  backend {
+    address = "origin.example.com"
+    name    = "origin"
+    shield  = data.fastly_datacenters.near_origin.nearest_shield
+  }
+}
@@ -0,0 +1,11 @@
+data "fastly_mtls_bundle" "clients" {
+  files = [
+    "${path.module}/ca/partner-a.pem",
+    "${path.module}/ca/partner-b.pem",
+  ]
+}
+
+resource "fastly_tls_mutual_authentication" "example" {
+  name        = "clients"
+  cert_bundle = data.fastly_mtls_bundle.clients.cert_bundle
+}
@@ -0,0 +1,42 @@
+data "fastly_ngwaf_workspace_lists" "example" {
+  workspace_id    = fastly_ngwaf_workspace.example.id
+  include_entries = true
+}
+
+data "fastly_ngwaf_rule_test" "example" {
+  expression = <<-EOT
+    ip in list("site.blocked") and (path ~ "^/admin" or method == "DELETE")
+  EOT
+
+  action {
+    type = "block"
+  }
+
+  dynamic "list" {
+    for_each = data.fastly_ngwaf_workspace_lists.example.lists
+    content {
+      reference_id = list.value.reference_id
+      type         = list.value.type
+      entries      = list.value.entries
+    }
+  }
+
+  request {
+    method = "DELETE"
+    path   = "/api/users/1"
+    ip     = "192.0.2.10"
+  }
+
+  request {
+    path    = "/admin"
+    ip      = "198.51.100.1"
+    country = "US"
+    headers = {
+      "User-Agent" = "curl/8.0"
+    }
+  }
+}
+
+output "blocked" {
+  value = [for r in data.fastly_ngwaf_rule_test.example.results : r.matched]
+}
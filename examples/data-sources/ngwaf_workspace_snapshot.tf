@@ -0,0 +1,11 @@
+data "fastly_ngwaf_workspace_snapshot" "staging" {
+  workspace_id = fastly_ngwaf_workspace.staging.id
+}
+
+output "fastly_ngwaf_workspace_snapshot_json" {
+  value = data.fastly_ngwaf_workspace_snapshot.staging.json
+}
+
+output "fastly_ngwaf_workspace_snapshot_list_names" {
+  value = [for list in data.fastly_ngwaf_workspace_snapshot.staging.lists : list.name]
+}
@@ -0,0 +1,17 @@
+data "fastly_service" "shared" {
+  name = "shared-edge"
+}
+
+resource "fastly_service_dynamic_snippet_content" "redirects" {
+  for_each = {
+    for s in data.fastly_service.shared.dynamic_snippets : s.name => s if s.name == "redirects"
+  }
+
+  service_id = data.fastly_service.shared.id
+  snippet_id = each.value.snippet_id
+  content    = file("${path.module}/redirects.vcl")
+}
+
+output "shared_domains" {
+  value = data.fastly_service.shared.domains[*].name
+}
@@ -8,3 +8,13 @@ output "fastly_services_filtered" {
   # get the service with the name "Example Service"
   value = one([for service in data.fastly_services.services.details : service.id if service.name == "Example Service"])
 }
+
+data "fastly_services" "team_edge" {
+  name_regex   = "^edge-"
+  type         = "vcl"
+  with_domains = true
+}
+
+output "fastly_services_team_edge_domains" {
+  value = { for service in data.fastly_services.team_edge.details : service.name => service.domains }
+}
@@ -0,0 +1,3 @@
+provider "fastly" {
+  api_key_command = "vault kv get -field=token secret/fastly"
+}
@@ -0,0 +1,3 @@
+provider "fastly" {
+  profile = "staging"
+}
@@ -0,0 +1,31 @@
+resource "fastly_ngwaf_workspace" "example" {
+  name                            = "example"
+  description                     = "Workspace with a large blocklist"
+  mode                            = "block"
+  ip_anonymization                = "hashed"
+  client_ip_headers               = ["X-Forwarded-For", "X-Real-IP"]
+  default_blocking_response_code = 403
+
+  attack_signal_thresholds {
+    one_minute  = 100
+    ten_minutes = 500
+    one_hour    = 1000
+    immediate   = true
+  }
+}
+
+resource "fastly_ngwaf_workspace_list" "example" {
+  workspace_id = fastly_ngwaf_workspace.example.id
+  name         = "blocklist"
+  description  = "IP blocklist synced from a threat feed"
+  type         = "ip"
+
+  # The entries are managed by fastly_ngwaf_list_entries.
+}
+
+resource "fastly_ngwaf_list_entries" "example" {
+  workspace_id = fastly_ngwaf_workspace.example.id
+  list_id      = fastly_ngwaf_workspace_list.example.id
+  source_file  = "${path.module}/blocklist.txt"
+  chunk_size   = 5000
+}
@@ -0,0 +1,27 @@
+resource "fastly_ngwaf_workspace" "example" {
+  name                            = "example"
+  description                     = "Test NGWAF Workspace"
+  mode                            = "block"
+  ip_anonymization                = "hashed"
+  client_ip_headers               = ["X-Forwarded-For", "X-Real-IP"]
+  default_blocking_response_code = 429
+
+  attack_signal_thresholds {}
+}
+
+resource "fastly_ngwaf_workspace_rule" "example" {
+  workspace_id    = fastly_ngwaf_workspace.example.id
+  type            = "request"
+  description     = "Block admin deletes from blocked IPs"
+  enabled         = true
+  request_logging = "sampled"
+  expression      = <<-EOT
+    ip in list("site.blocked")
+      and (path ~ "^/admin" or method == "DELETE")
+      and request_header exists (name == "X-API-Key" and value contains "test")
+  EOT
+
+  action {
+    type = "block"
+  }
+}
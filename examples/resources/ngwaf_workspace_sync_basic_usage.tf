@@ -0,0 +1,21 @@
+data "fastly_ngwaf_workspace_snapshot" "staging" {
+  workspace_id = fastly_ngwaf_workspace.staging.id
+}
+
+# Promote the lists, rules, signals, thresholds, redactions and virtual patch
+# settings of the staging workspace to the production workspace.
+resource "fastly_ngwaf_workspace_sync" "production" {
+  workspace_id = fastly_ngwaf_workspace.production.id
+  snapshot     = data.fastly_ngwaf_workspace_snapshot.staging.json
+}
+
+# A snapshot can also be kept in the repository, e.g. after reviewing the
+# output of `terraform output -raw staging_snapshot`.
+resource "fastly_ngwaf_workspace_sync" "canary" {
+  workspace_id = fastly_ngwaf_workspace.canary.id
+  snapshot     = file("${path.module}/snapshots/staging.json")
+}
+
+output "staging_snapshot" {
+  value = data.fastly_ngwaf_workspace_snapshot.staging.json
+}
@@ -0,0 +1,38 @@
+locals {
+  domains = ["example.com", "www.example.com", "api.example.com"]
+}
+
+resource "fastly_service_vcl" "demo" {
+  name = "my-service"
+
+  dynamic "domain" {
+    for_each = local.domains
+    content {
+      name = domain.value
+    }
+  }
+
+  backend {
+    address = "127.0.0.1"
+    name    = "localhost"
+  }
+
+  force_destroy = true
+}
+
+resource "fastly_tls_private_key" "demo" {
+  key_pem = "..."
+  name    = "demo-key"
+}
+
+resource "fastly_tls_certificate" "demo" {
+  certificate_body = "..."
+  name             = "demo-cert"
+  depends_on       = [fastly_tls_private_key.demo]
+}
+
+resource "fastly_tls_activations" "demo" {
+  certificate_id = fastly_tls_certificate.demo.id
+  domains        = local.domains
+  depends_on     = [fastly_service_vcl.demo]
+}
@@ -0,0 +1,187 @@
+package fastly
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// activationWindowDayNames are the day names accepted by activation_window,
+// in the order they are described in.
+var activationWindowDayNames = []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+
+// activationWindowDays maps the day names accepted by activation_window to
+// weekdays.
+var activationWindowDays = map[string]time.Weekday{
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+	"sun": time.Sunday,
+}
+
+func activationWindowSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "Restricts when new versions are activated. Outside the window, versioned changes are applied to a draft version which is validated but not activated, and a warning is shown. The pending version is activated by the next apply inside the window, without needing another configuration change. Only applies when `activate` is `true`",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"range": {
+					Type:        schema.TypeList,
+					Required:    true,
+					MinItems:    1,
+					Description: "A weekly time range in which activations are allowed. Activations are allowed if any range matches",
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"days": {
+								Type:        schema.TypeSet,
+								Required:    true,
+								MinItems:    1,
+								Description: "The days of the week on which the range starts. One or more of `mon`, `tue`, `wed`, `thu`, `fri`, `sat` and `sun`",
+								Elem: &schema.Schema{
+									Type:             schema.TypeString,
+									ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(activationWindowDayNames, false)),
+								},
+							},
+							"end": {
+								Type:             schema.TypeString,
+								Required:         true,
+								Description:      "The time of day at which the range ends, in 24-hour `HH:MM` format. If it is not after `start`, the range ends on the following day",
+								ValidateDiagFunc: validateActivationWindowTime(),
+							},
+							"start": {
+								Type:             schema.TypeString,
+								Required:         true,
+								Description:      "The time of day at which the range starts, in 24-hour `HH:MM` format",
+								ValidateDiagFunc: validateActivationWindowTime(),
+							},
+						},
+					},
+				},
+				"timezone": {
+					Type:             schema.TypeString,
+					Optional:         true,
+					Default:          "UTC",
+					Description:      "The IANA time zone that `start` and `end` are in, e.g. `Europe/London`. Default `UTC`",
+					ValidateDiagFunc: validateTimezone(),
+				},
+			},
+		},
+	}
+}
+
+// activationWindow is the expanded form of an activation_window block.
+type activationWindow struct {
+	location *time.Location
+	ranges   []activationWindowRange
+}
+
+// activationWindowRange is a weekly time range. start and end are minutes
+// since midnight, and the range ends on the following day when end is not
+// after start.
+type activationWindowRange struct {
+	days       map[time.Weekday]bool
+	start, end int
+}
+
+// expandActivationWindow expands the activation_window block. It returns nil
+// when no window is configured, in which case activations are always allowed.
+func expandActivationWindow(raw []any) (*activationWindow, error) {
+	if len(raw) == 0 || raw[0] == nil {
+		return nil, nil
+	}
+	resource := raw[0].(map[string]any)
+
+	w := &activationWindow{location: time.UTC}
+	if tz, ok := resource["timezone"].(string); ok && tz != "" {
+		location, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("invalid activation_window timezone %q: %w", tz, err)
+		}
+		w.location = location
+	}
+
+	for _, r := range resource["range"].([]any) {
+		rr := r.(map[string]any)
+
+		start, err := parseActivationWindowTime(rr["start"].(string))
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseActivationWindowTime(rr["end"].(string))
+		if err != nil {
+			return nil, err
+		}
+
+		days := map[time.Weekday]bool{}
+		for _, day := range rr["days"].(*schema.Set).List() {
+			weekday, ok := activationWindowDays[day.(string)]
+			if !ok {
+				return nil, fmt.Errorf("invalid activation_window day %q", day)
+			}
+			days[weekday] = true
+		}
+
+		w.ranges = append(w.ranges, activationWindowRange{days: days, start: start, end: end})
+	}
+
+	return w, nil
+}
+
+// open reports whether activations are allowed at t. A nil window is always
+// open.
+func (w *activationWindow) open(t time.Time) bool {
+	if w == nil {
+		return true
+	}
+
+	t = t.In(w.location)
+	minute := t.Hour()*60 + t.Minute()
+	yesterday := (t.Weekday() + 6) % 7
+
+	for _, r := range w.ranges {
+		if r.end > r.start {
+			if r.days[t.Weekday()] && minute >= r.start && minute < r.end {
+				return true
+			}
+			continue
+		}
+		// The range ends on the following day.
+		if (r.days[t.Weekday()] && minute >= r.start) || (r.days[yesterday] && minute < r.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// String describes the window for use in diagnostics.
+func (w *activationWindow) String() string {
+	ranges := make([]string, 0, len(w.ranges))
+	for _, r := range w.ranges {
+		var days []string
+		for _, day := range activationWindowDayNames {
+			if r.days[activationWindowDays[day]] {
+				days = append(days, day)
+			}
+		}
+		ranges = append(ranges, fmt.Sprintf("%s %02d:%02d-%02d:%02d", strings.Join(days, ","), r.start/60, r.start%60, r.end/60, r.end%60))
+	}
+	return fmt.Sprintf("%s (%s)", strings.Join(ranges, "; "), w.location)
+}
+
+// parseActivationWindowTime parses a 24-hour `HH:MM` time of day into
+// minutes since midnight.
+func parseActivationWindowTime(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid activation_window time %q, expected HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
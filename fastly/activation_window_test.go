@@ -0,0 +1,66 @@
+package fastly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestActivationWindowOpen(t *testing.T) {
+	window, err := expandActivationWindow([]any{
+		map[string]any{
+			"timezone": "Europe/London",
+			"range": []any{
+				map[string]any{
+					"days":  schema.NewSet(schema.HashString, []any{"tue", "thu"}),
+					"start": "09:00",
+					"end":   "17:00",
+				},
+				map[string]any{
+					"days":  schema.NewSet(schema.HashString, []any{"sat"}),
+					"start": "22:00",
+					"end":   "02:00",
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		time string
+		want bool
+	}{
+		// British Summer Time, UTC+1.
+		{time: "2026-06-02T08:00:00Z", want: true},  // Tue 09:00
+		{time: "2026-06-02T15:59:00Z", want: true},  // Tue 16:59
+		{time: "2026-06-02T16:00:00Z", want: false}, // Tue 17:00
+		{time: "2026-06-03T10:00:00Z", want: false}, // Wed 11:00
+		{time: "2026-06-06T20:59:00Z", want: false}, // Sat 21:59
+		{time: "2026-06-06T21:00:00Z", want: true},  // Sat 22:00
+		{time: "2026-06-07T00:30:00Z", want: true},  // Sun 01:30
+		{time: "2026-06-07T01:00:00Z", want: false}, // Sun 02:00
+		// Greenwich Mean Time, UTC+0.
+		{time: "2026-12-01T08:30:00Z", want: false}, // Tue 08:30
+		{time: "2026-12-01T09:00:00Z", want: true},  // Tue 09:00
+	} {
+		now, err := time.Parse(time.RFC3339, tc.time)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := window.open(now); got != tc.want {
+			t.Errorf("expected window open at %s to be %t, got %t", tc.time, tc.want, got)
+		}
+	}
+
+	if want, got := "tue,thu 09:00-17:00; sat 22:00-02:00 (Europe/London)", window.String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	var none *activationWindow
+	if !none.open(time.Now()) {
+		t.Error("expected no activation window to always be open")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
 	gofastly "github.com/fastly/go-fastly/v17/fastly"
 )
@@ -25,6 +26,28 @@ const (
 	ServiceTypeCompute = "wasm"
 )
 
+const (
+	// onConflictError fails the apply when the service changed since the plan.
+	onConflictError = "error"
+	// onConflictOverwrite applies the plan even if the service changed since
+	// the plan.
+	onConflictOverwrite = "overwrite"
+)
+
+const (
+	// draftPolicyClone clones cloned_version for every apply with versioned
+	// changes.
+	draftPolicyClone = "clone"
+	// draftPolicyReuse applies versioned changes to the newest draft version
+	// created by the provider, when there is one.
+	draftPolicyReuse = "reuse"
+
+	// draftVersionMarker is appended to the comment of the draft versions
+	// created by the provider when draft_policy is "reuse", so they can be
+	// told apart from drafts created outside of Terraform.
+	draftVersionMarker = "[terraform-draft]"
+)
+
 // ServiceDefinition defines the data model for service definitions
 // There are two types of service: VCL and Compute. This interface specifies the data object from which service resources
 // are constructed.
@@ -75,17 +98,28 @@ func resourceService(serviceDef ServiceDefinition) *schema.Resource {
 			}),
 			customdiff.ComputedIf("active_version", func(_ context.Context, d *schema.ResourceDiff, _ any) bool {
 				// If cloned_version is recomputed and we are automatically activating new versions (controlled with the
-				// activate flag) then the active_version will be recomputed too.
-				return d.HasChange("cloned_version") && d.Get("activate").(bool)
+				// activate flag) then the active_version will be recomputed too. The same applies to a version whose
+				// activation was deferred, once the activation window opens.
+				return d.Get("activate").(bool) && (d.HasChange("cloned_version") || pendingActivationDue(d))
+			}),
+			customdiff.ComputedIf("pending_version", func(_ context.Context, d *schema.ResourceDiff, _ any) bool {
+				return d.Get("activate").(bool) && (d.HasChange("cloned_version") || pendingActivationDue(d))
 			}),
+			warnDeferredActivation,
 			customdiff.ComputedIf("staged_version", func(_ context.Context, d *schema.ResourceDiff, _ any) bool {
 				// If cloned_version is recomputed and we are automatically staging new versions (controlled with the
 				// stage flag) then the staged_version will be recomputed too.
 				return d.HasChange("cloned_version") && d.Get("stage").(bool)
 			}),
+			customdiff.ComputedIf("latest_version", func(_ context.Context, d *schema.ResourceDiff, _ any) bool {
+				// If cloned_version is recomputed then a new version will be created.
+				return d.HasChange("cloned_version")
+			}),
 			validateUniqueNames("backend"),
 			validateUniqueNames("rate_limiter"),
 			validateUniqueNames("snippet"),
+			validateShieldPOPs("backend", "director"),
+			validateServiceGuardrails,
 		),
 		Schema: map[string]*schema.Schema{
 			"activate": {
@@ -98,6 +132,7 @@ func resourceService(serviceDef ServiceDefinition) *schema.Resource {
 			// Terraform, we abstract this number away from the users and manage
 			// creation and activating. It's used internally, but also exported for
 			// users to see.
+			"activation_window": activationWindowSchema(),
 			"active_version": {
 				Type:        schema.TypeInt,
 				Computed:    true,
@@ -121,6 +156,13 @@ func resourceService(serviceDef ServiceDefinition) *schema.Resource {
 				Default:     "Managed by Terraform",
 				Description: "Description field for the service. This versionless attribute is updated regardless of the `activate` and `stage` settings. Default `Managed by Terraform`",
 			},
+			"draft_policy": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          draftPolicyClone,
+				Description:      "Controls how draft versions are created when `activate` is `false`. `clone` clones `cloned_version` for every apply with versioned changes. `reuse` applies the changes to the newest unlocked, never activated version created by the provider instead, resetting it to match the configuration, and only clones when there is no such version. Drafts created by the provider are tracked by appending `" + draftVersionMarker + "` to their version comment. Default `clone`",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{draftPolicyClone, draftPolicyReuse}, false)),
+			},
 			"force_destroy": {
 				Type:          schema.TypeBool,
 				Optional:      true,
@@ -137,11 +179,37 @@ func resourceService(serviceDef ServiceDefinition) *schema.Resource {
 				Computed:    true,
 				Description: "Used internally by the provider to temporarily indicate if the service is being imported, and is reset to false once the import is finished",
 			},
+			// Latest Version represents the most recent version of the service,
+			// whether or not it was created by Terraform. Together with Active
+			// Version it records what the plan observed, so that versions created
+			// or activated outside of Terraform between plan and apply can be
+			// detected before cloning.
+			"latest_version": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The latest version of your Fastly Service, including versions created outside of Terraform",
+			},
 			"name": {
 				Type:        schema.TypeString,
 				Required:    true,
 				Description: "The unique name for the Service to create. This versionless attribute is updated regardless of the `activate` and `stage` settings",
 			},
+			"on_conflict": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          onConflictError,
+				Description:      "What to do when the service's active or latest version has changed outside of Terraform since the plan was created, e.g. a new version was created or activated in the Fastly UI. `error` fails the apply and lists the versions that appeared. `overwrite` clones `cloned_version` anyway, discarding any changes made in those versions. Default `error`",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{onConflictError, onConflictOverwrite}, false)),
+			},
+			// Pending Version represents a version whose activation was deferred
+			// because the apply happened outside of the activation window. It is
+			// activated by the next apply inside the window.
+			"override_guardrails": overrideGuardrailsSchema(),
+			"pending_version": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "A validated version waiting to be activated by the next apply inside the `activation_window`, or `0` if there is none",
+			},
 			"reuse": {
 				Type:          schema.TypeBool,
 				Optional:      true,
@@ -281,6 +349,73 @@ func resourceImport() *schema.ResourceImporter {
 	}
 }
 
+// validateShieldPOPs checks the `shield` attribute of every element of the
+// given blocks against the Fastly POPs, so that typos are caught at plan time
+// rather than by the API after a new version has been cloned. The POP list is
+// fetched once per provider instance and validation is skipped if it cannot
+// be fetched.
+func validateShieldPOPs(blocks ...string) func(ctx context.Context, rd *schema.ResourceDiff, meta any) error {
+	return func(ctx context.Context, rd *schema.ResourceDiff, meta any) error {
+		c := rd.GetRawConfig()
+		if c.IsNull() || !c.IsKnown() {
+			return nil
+		}
+
+		type shieldRef struct {
+			block, name, shield string
+		}
+		var refs []shieldRef
+
+		m := c.AsValueMap()
+		for _, block := range blocks {
+			s, ok := m[block]
+			if !ok || s.IsNull() || !s.IsKnown() {
+				continue
+			}
+			for _, v := range s.AsValueSet().Values() {
+				if v.IsNull() || !v.IsKnown() {
+					continue
+				}
+				attrs := v.AsValueMap()
+				shield, ok := attrs["shield"]
+				if !ok || shield.IsNull() || !shield.IsKnown() || shield.AsString() == "" {
+					continue
+				}
+				var name string
+				if n, ok := attrs["name"]; ok && !n.IsNull() && n.IsKnown() {
+					name = n.AsString()
+				}
+				refs = append(refs, shieldRef{block, name, shield.AsString()})
+			}
+		}
+		if len(refs) == 0 {
+			return nil
+		}
+
+		client, ok := meta.(*APIClient)
+		if !ok || client == nil {
+			return nil
+		}
+		pops, err := client.allDatacenters(ctx)
+		if err != nil {
+			log.Printf("[WARN] Skipping shield validation, error fetching datacenters: %s", err)
+			return nil
+		}
+
+		var errs []error
+		for _, ref := range refs {
+			warning, err := checkShieldPOP(pops, ref.shield)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s %q: invalid shield: %w", ref.block, ref.name, err))
+			}
+			if warning != "" {
+				addPlanWarning(ctx, fmt.Sprintf("Shield POP for %s %q is not a shield", ref.block, ref.name), warning)
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
+
 // resourceServiceCreate provides service resource Create functionality.
 func resourceServiceCreate(ctx context.Context, d *schema.ResourceData, meta any, serviceDef ServiceDefinition) diag.Diagnostics {
 	if err := validateVCLs(d); err != nil {
@@ -349,11 +484,13 @@ func resourceServiceUpdate(ctx context.Context, d *schema.ResourceData, meta any
 	}
 
 	// Update the cloned version's comment. No new version is required for this.
-	if d.HasChange("version_comment") && (!needsChange || d.IsNewResource()) {
+	// The initial version of a new service is also marked as a draft created by
+	// the provider, so it can be reused.
+	if (d.HasChange("version_comment") && (!needsChange || d.IsNewResource())) || (d.IsNewResource() && reuseDrafts(d)) {
 		opts := gofastly.UpdateVersionInput{
 			ServiceID:      d.Id(),
 			ServiceVersion: d.Get("cloned_version").(int),
-			Comment:        gofastly.ToPointer(d.Get("version_comment").(string)),
+			Comment:        gofastly.ToPointer(draftVersionComment(d)),
 		}
 
 		log.Printf("[DEBUG] Update Version opts: %#v", opts)
@@ -397,8 +534,21 @@ func resourceServiceUpdate(ctx context.Context, d *schema.ResourceData, meta any
 			// expects the provider to apply *more* changes to that existing draft
 			// version rather than creating another draft version. In this case there
 			// is no need to clone the latest version.
+			//
+			// If 'draft_policy = "reuse"' and the latest version is a draft created
+			// by the provider, then the changes are applied to it as well. Read
+			// points cloned_version at the newest such draft and refreshes the state
+			// from it, so the planned changes reset it to match the configuration.
+			reuseDraft := reuseDrafts(d) && isProviderDraft(existingVersion)
+			if reuseDraft {
+				log.Printf("[DEBUG] Reusing draft version (%d) for updates", latestVersion)
+			}
+
+			if (!shouldStage || *existingVersion.Locked) && !reuseDraft {
+				if diags := checkServiceVersionConflict(ctx, d, conn); diags.HasError() {
+					return diags
+				}
 
-			if !shouldStage || *existingVersion.Locked {
 				// Clone the latest version, giving us an unlocked version we can modify.
 				log.Printf("[DEBUG] Creating clone of version (%d) for updates", latestVersion)
 				newVersion, err := conn.CloneVersion(gofastly.NewContextForResourceID(ctx, d.Id()), &gofastly.CloneVersionInput{
@@ -425,11 +575,11 @@ func resourceServiceUpdate(ctx context.Context, d *schema.ResourceData, meta any
 				time.Sleep(7 * time.Second)
 
 				// Update the cloned version's comment.
-				if d.Get("version_comment").(string) != "" {
+				if d.Get("version_comment").(string) != "" || reuseDrafts(d) {
 					opts := gofastly.UpdateVersionInput{
 						ServiceID:      d.Id(),
 						ServiceVersion: latestVersion,
-						Comment:        gofastly.ToPointer(d.Get("version_comment").(string)),
+						Comment:        gofastly.ToPointer(draftVersionComment(d)),
 					}
 
 					log.Printf("[DEBUG] Update Version opts: %#v", opts)
@@ -512,9 +662,32 @@ func resourceServiceUpdate(ctx context.Context, d *schema.ResourceData, meta any
 		}
 	}
 
+	window, err := expandActivationWindow(d.Get("activation_window").([]any))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var diags diag.Diagnostics
+
 	versionNotYetActivated := d.Get("cloned_version") != d.Get("active_version")
 	latestVersion := d.Get("cloned_version").(int)
-	if shouldActivate && versionNotYetActivated {
+	if shouldActivate && versionNotYetActivated && !window.open(time.Now()) {
+		log.Printf("[INFO] Deferring activation of Fastly Service (%s), Version (%v) until the activation window opens", d.Id(), latestVersion)
+
+		err = d.Set("pending_version", latestVersion)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Activation deferred until the activation window opens",
+			Detail: fmt.Sprintf(
+				"Version %d of Fastly Service (%s) has been validated but was not activated, as activations are only allowed %s. It will be activated by the next apply inside the activation window.",
+				latestVersion, d.Id(), window,
+			),
+		})
+	} else if shouldActivate && versionNotYetActivated {
 		log.Printf("[DEBUG] Activating Fastly Service (%s), Version (%v)", d.Id(), latestVersion)
 		_, err := conn.ActivateVersion(gofastly.NewContextForResourceID(ctx, d.Id()), &gofastly.ActivateVersionInput{
 			ServiceID:      d.Id(),
@@ -530,6 +703,11 @@ func resourceServiceUpdate(ctx context.Context, d *schema.ResourceData, meta any
 		if err != nil {
 			return diag.FromErr(err)
 		}
+
+		err = d.Set("pending_version", 0)
+		if err != nil {
+			return diag.FromErr(err)
+		}
 	} else {
 		log.Printf("[INFO] Skipping activation of Fastly Service (%s), Version (%v)", d.Id(), latestVersion)
 		log.Print("[INFO] The Terraform definition is explicitly specified to not activate the changes on Fastly")
@@ -537,7 +715,162 @@ func resourceServiceUpdate(ctx context.Context, d *schema.ResourceData, meta any
 		log.Printf("[INFO] Visit https://manage.fastly.com/configure/services/%s/versions/%v and activate it manually", d.Id(), latestVersion)
 	}
 
-	return resourceServiceRead(ctx, d, meta, serviceDef)
+	return append(diags, resourceServiceRead(ctx, d, meta, serviceDef)...)
+}
+
+// pendingActivationDue reports whether the activation of a pending version
+// is due, i.e. the activation window has opened since it was deferred.
+func pendingActivationDue(d *schema.ResourceDiff) bool {
+	if d.Get("pending_version").(int) == 0 {
+		return false
+	}
+	window, err := expandActivationWindow(d.Get("activation_window").([]any))
+	if err != nil {
+		log.Printf("[WARN] Unable to check activation window: %s", err)
+		return false
+	}
+	return window.open(time.Now())
+}
+
+// warnDeferredActivation warns when a new version would be activated but the
+// activation window is closed.
+func warnDeferredActivation(ctx context.Context, d *schema.ResourceDiff, _ any) error {
+	if !d.Get("activate").(bool) || !d.HasChange("cloned_version") {
+		return nil
+	}
+	window, err := expandActivationWindow(d.Get("activation_window").([]any))
+	if err != nil {
+		return err
+	}
+	if !window.open(time.Now()) {
+		addPlanWarning(ctx, "Activation will be deferred",
+			fmt.Sprintf("The new version will be validated but not activated, as activations are only allowed %s. It will be activated by the next apply inside the activation window.", window))
+	}
+	return nil
+}
+
+// reuseDrafts reports whether versioned changes should be applied to an
+// existing draft version created by the provider rather than a new clone.
+// Drafts are only ever reused when the provider is not activating versions.
+func reuseDrafts(d *schema.ResourceData) bool {
+	return d.Get("draft_policy").(string) == draftPolicyReuse && !d.Get("activate").(bool)
+}
+
+// draftVersionComment returns the comment for the version being updated,
+// marking it as a draft created by the provider when drafts are reused.
+func draftVersionComment(d *schema.ResourceData) string {
+	comment := d.Get("version_comment").(string)
+	if !reuseDrafts(d) {
+		return comment
+	}
+	if comment == "" {
+		return draftVersionMarker
+	}
+	return comment + " " + draftVersionMarker
+}
+
+// isProviderDraft reports whether v is an unlocked, never activated version
+// that was created by the provider. Activating a version locks it, so an
+// unlocked version has never been activated.
+func isProviderDraft(v *gofastly.Version) bool {
+	if v == nil || v.Number == nil || v.Comment == nil {
+		return false
+	}
+	if v.Locked == nil || *v.Locked || (v.Active != nil && *v.Active) {
+		return false
+	}
+	return strings.HasSuffix(*v.Comment, draftVersionMarker)
+}
+
+// newestProviderDraft returns the newest draft version created by the
+// provider, or nil if there is none.
+func newestProviderDraft(versions []*gofastly.Version) *gofastly.Version {
+	var newest *gofastly.Version
+	for _, v := range versions {
+		if isProviderDraft(v) && (newest == nil || *v.Number > *newest.Number) {
+			newest = v
+		}
+	}
+	return newest
+}
+
+// checkServiceVersionConflict ensures the service has not changed since the
+// plan was created. The prior state holds the active and latest versions that
+// were observed when planning, so if a colleague has since created or
+// activated a version (e.g. in the Fastly UI), cloning cloned_version would
+// either discard their work or build on an outdated version.
+func checkServiceVersionConflict(ctx context.Context, d *schema.ResourceData, conn *gofastly.Client) diag.Diagnostics {
+	s, err := conn.GetServiceDetails(gofastly.NewContextForResourceID(ctx, d.Id()), &gofastly.GetServiceDetailsInput{
+		ServiceID: d.Id(),
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var currentActive, currentLatest int
+	if s.ActiveVersion != nil && s.ActiveVersion.Number != nil {
+		currentActive = *s.ActiveVersion.Number
+	}
+	if s.Version != nil && s.Version.Number != nil {
+		currentLatest = *s.Version.Number
+	}
+
+	plannedActive, _ := d.GetChange("active_version")
+	plannedLatest, _ := d.GetChange("latest_version")
+
+	err = serviceVersionConflict(plannedActive.(int), plannedLatest.(int), currentActive, currentLatest)
+	if err == nil {
+		return nil
+	}
+
+	if d.Get("on_conflict").(string) == onConflictOverwrite {
+		log.Printf("[WARN] Fastly Service (%s) changed since plan, overwriting as on_conflict is %q: %s", d.Id(), onConflictOverwrite, err)
+		return nil
+	}
+
+	return diag.Diagnostics{
+		{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Fastly Service (%s) changed outside of Terraform since plan", d.Id()),
+			Detail: fmt.Sprintf(
+				"%s.\n\nApplying this plan would clone version %d and discard those changes. Run terraform plan again to review the current state of the service, or set on_conflict = %q to apply anyway.",
+				err, d.Get("cloned_version").(int), onConflictOverwrite,
+			),
+		},
+	}
+}
+
+// serviceVersionConflict compares the active and latest versions observed at
+// plan time with the current ones. An observed latest version of 0 means the
+// state predates latest_version, in which case only the active version can be
+// checked.
+func serviceVersionConflict(plannedActive, plannedLatest, currentActive, currentLatest int) error {
+	var changes []string
+
+	if plannedLatest != 0 && currentLatest > plannedLatest {
+		if currentLatest == plannedLatest+1 {
+			changes = append(changes, fmt.Sprintf("version %d was created", currentLatest))
+		} else {
+			versions := make([]string, 0, currentLatest-plannedLatest)
+			for v := plannedLatest + 1; v <= currentLatest; v++ {
+				versions = append(versions, strconv.Itoa(v))
+			}
+			changes = append(changes, fmt.Sprintf("versions %s were created", strings.Join(versions, ", ")))
+		}
+	}
+
+	if currentActive != plannedActive {
+		if currentActive == 0 {
+			changes = append(changes, fmt.Sprintf("version %d was deactivated", plannedActive))
+		} else {
+			changes = append(changes, fmt.Sprintf("version %d was activated (the plan observed version %d)", currentActive, plannedActive))
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(changes, " and "))
 }
 
 // resourceServiceRead provides service resource Read functionality.
@@ -593,7 +926,7 @@ func resourceServiceRead(ctx context.Context, d *schema.ResourceData, meta any,
 	}
 
 	if s.ActiveVersion.Comment != nil {
-		err = d.Set("version_comment", s.ActiveVersion.Comment)
+		err = d.Set("version_comment", strings.TrimSpace(strings.TrimSuffix(*s.ActiveVersion.Comment, draftVersionMarker)))
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -633,6 +966,13 @@ func resourceServiceRead(ctx context.Context, d *schema.ResourceData, meta any,
 		}
 	}
 
+	if s.Version != nil && s.Version.Number != nil {
+		err = d.Set("latest_version", s.Version.Number)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	// If cloned_version is not set, and there is no active version, temporarily
 	// set the service.ActiveVersion number to the latest version supplied via
 	// the get service version details call. This is to ensure we still read all
@@ -654,10 +994,41 @@ func resourceServiceRead(ctx context.Context, d *schema.ResourceData, meta any,
 		}
 	}
 
-	// If activate is false, then read the state from cloned_version instead of
-	// the active version.
+	// If draft_policy is "reuse", then the newest draft created by the provider
+	// is the version that will be updated, so track it with cloned_version.
+	// This picks up drafts created by earlier applies that failed before
+	// cloned_version could be recorded.
+	if reuseDrafts(d) {
+		versions, err := conn.ListVersions(gofastly.NewContextForResourceID(ctx, d.Id()), &gofastly.ListVersionsInput{
+			ServiceID: d.Id(),
+		})
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if v := newestProviderDraft(versions); v != nil && *v.Number > d.Get("cloned_version").(int) {
+			log.Printf("[DEBUG] Tracking draft version (%d) created by the provider", *v.Number)
+			err = d.Set("cloned_version", *v.Number)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+		}
+	}
+
+	// A version whose activation was deferred by activation_window remains
+	// pending until it, or a later version, has been activated.
+	pendingVersion := d.Get("pending_version").(int)
+	if pendingVersion != 0 && s.ActiveVersion.Number != nil && *s.ActiveVersion.Number >= pendingVersion {
+		pendingVersion = 0
+		err = d.Set("pending_version", 0)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	// If activate is false, or activation of cloned_version has been deferred,
+	// then read the state from cloned_version instead of the active version.
 	// Otherwise, cloned_version should track the active version
-	if !d.Get("activate").(bool) {
+	if !d.Get("activate").(bool) || pendingVersion != 0 {
 		s.ActiveVersion.Number = gofastly.ToPointer(d.Get("cloned_version").(int))
 	} else if s.ActiveVersion.Number != nil {
 		err := d.Set("cloned_version", s.ActiveVersion.Number)
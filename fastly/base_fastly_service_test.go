@@ -0,0 +1,117 @@
+package fastly
+
+import (
+	"testing"
+
+	gofastly "github.com/fastly/go-fastly/v17/fastly"
+)
+
+func TestServiceVersionConflict(t *testing.T) {
+	for _, tc := range []struct {
+		name                                                       string
+		plannedActive, plannedLatest, currentActive, currentLatest int
+		want                                                       string
+	}{
+		{
+			name:          "unchanged",
+			plannedActive: 3, plannedLatest: 4, currentActive: 3, currentLatest: 4,
+		},
+		{
+			name:          "version created",
+			plannedActive: 3, plannedLatest: 4, currentActive: 3, currentLatest: 5,
+			want: "version 5 was created",
+		},
+		{
+			name:          "versions created",
+			plannedActive: 3, plannedLatest: 4, currentActive: 3, currentLatest: 7,
+			want: "versions 5, 6, 7 were created",
+		},
+		{
+			name:          "version created and activated",
+			plannedActive: 3, plannedLatest: 4, currentActive: 5, currentLatest: 5,
+			want: "version 5 was created and version 5 was activated (the plan observed version 3)",
+		},
+		{
+			name:          "older version activated",
+			plannedActive: 3, plannedLatest: 4, currentActive: 2, currentLatest: 4,
+			want: "version 2 was activated (the plan observed version 3)",
+		},
+		{
+			name:          "deactivated",
+			plannedActive: 3, plannedLatest: 4, currentActive: 0, currentLatest: 4,
+			want: "version 3 was deactivated",
+		},
+		{
+			name:          "state without latest_version",
+			plannedActive: 3, plannedLatest: 0, currentActive: 3, currentLatest: 9,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := serviceVersionConflict(tc.plannedActive, tc.plannedLatest, tc.currentActive, tc.currentLatest)
+			switch {
+			case tc.want == "" && err != nil:
+				t.Errorf("expected no conflict, got %q", err)
+			case tc.want != "" && err == nil:
+				t.Errorf("expected conflict %q, got none", tc.want)
+			case tc.want != "" && err.Error() != tc.want:
+				t.Errorf("expected conflict %q, got %q", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestNewestProviderDraft(t *testing.T) {
+	version := func(number int, locked, active bool, comment string) *gofastly.Version {
+		return &gofastly.Version{
+			Number:  gofastly.ToPointer(number),
+			Locked:  gofastly.ToPointer(locked),
+			Active:  gofastly.ToPointer(active),
+			Comment: gofastly.ToPointer(comment),
+		}
+	}
+
+	for _, tc := range []struct {
+		name     string
+		versions []*gofastly.Version
+		want     int
+	}{
+		{
+			name: "no versions",
+		},
+		{
+			name: "no drafts created by the provider",
+			versions: []*gofastly.Version{
+				version(1, true, false, "Managed by Terraform"),
+				version(2, false, false, "work in progress"),
+			},
+		},
+		{
+			name: "newest unlocked draft",
+			versions: []*gofastly.Version{
+				version(3, false, false, draftVersionMarker),
+				version(5, false, false, "release notes "+draftVersionMarker),
+				version(4, false, false, draftVersionMarker),
+			},
+			want: 5,
+		},
+		{
+			name: "locked and active drafts are skipped",
+			versions: []*gofastly.Version{
+				version(3, false, false, draftVersionMarker),
+				version(4, true, false, draftVersionMarker),
+				version(5, true, true, draftVersionMarker),
+			},
+			want: 3,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var got int
+			if v := newestProviderDraft(tc.versions); v != nil {
+				got = *v.Number
+			}
+			if got != tc.want {
+				t.Errorf("expected version %d, got %d", tc.want, got)
+			}
+		})
+	}
+}
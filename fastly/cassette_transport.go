@@ -0,0 +1,343 @@
+package fastly
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	// cassetteRecordEnv enables recording of API interactions into cassettes.
+	cassetteRecordEnv = "FASTLY_TEST_RECORD"
+	// cassetteReplayEnv enables replaying API interactions from cassettes.
+	cassetteReplayEnv = "FASTLY_TEST_REPLAY"
+
+	cassetteRedacted = "[REDACTED]"
+)
+
+type cassetteMode int
+
+const (
+	cassetteOff cassetteMode = iota
+	cassetteRecord
+	cassetteReplay
+)
+
+// cassetteModeFromEnv returns the cassette mode requested via the environment.
+func cassetteModeFromEnv() cassetteMode {
+	switch {
+	case os.Getenv(cassetteReplayEnv) == "1":
+		return cassetteReplay
+	case os.Getenv(cassetteRecordEnv) == "1":
+		return cassetteRecord
+	}
+	return cassetteOff
+}
+
+type cassetteContextKey struct{}
+
+// withCassette returns a context that instructs Config.Client to record to, or
+// replay from, the given cassette.
+func withCassette(ctx context.Context, c *cassette) context.Context {
+	return context.WithValue(ctx, cassetteContextKey{}, c)
+}
+
+// cassetteFromContext returns the cassette stored by withCassette, or nil.
+func cassetteFromContext(ctx context.Context) *cassette {
+	if ctx == nil {
+		return nil
+	}
+	c, _ := ctx.Value(cassetteContextKey{}).(*cassette)
+	return c
+}
+
+// cassetteSecretPattern matches form fields and JSON keys whose values must
+// never be written to a cassette.
+var cassetteSecretPattern = regexp.MustCompile(`(?i)(token|secret|password|private_key|access_key|api_key|credentials|auth_key|key_pem|cert_bundle|sas_token|user_key|license_key)`)
+
+// cassetteInteraction is a single recorded request/response pair.
+type cassetteInteraction struct {
+	Request  cassetteRequest  `json:"request"`
+	Response cassetteResponse `json:"response"`
+
+	used bool
+}
+
+type cassetteRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Body   string `json:"body,omitempty"`
+}
+
+type cassetteResponse struct {
+	StatusCode int               `json:"status_code"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body,omitempty"`
+}
+
+// cassetteResponseHeaders are the response headers retained in a cassette.
+// Everything else is dropped to keep fixtures small and free of request IDs.
+var cassetteResponseHeaders = []string{"Content-Type", "Link"}
+
+// cassette holds the API interactions of one acceptance test, recorded to or
+// replayed from a JSON fixture file.
+//
+// Terraform configures the provider afresh for every command a test runs, so
+// a cassette is shared by all of the API clients of a test, each wrapping its
+// own transport in a cassetteTransport. Recorded interactions accumulate
+// across commands and are written out by save, and replayed interactions are
+// consumed in order across commands.
+type cassette struct {
+	mode cassetteMode
+	path string
+
+	mu           sync.Mutex
+	interactions []*cassetteInteraction
+}
+
+// openCassette returns a cassette for the given mode. In replay mode the
+// cassette is loaded from path immediately.
+func openCassette(mode cassetteMode, path string) (*cassette, error) {
+	c := &cassette{
+		mode: mode,
+		path: path,
+	}
+
+	if mode == cassetteReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading cassette: %w", err)
+		}
+		if err := json.Unmarshal(data, &c.interactions); err != nil {
+			return nil, fmt.Errorf("error parsing cassette (%s): %w", path, err)
+		}
+	}
+
+	return c, nil
+}
+
+// transport returns a transport that records the interactions of underlying
+// into the cassette or, in replay mode, replays them without using
+// underlying.
+func (c *cassette) transport(underlying http.RoundTripper) http.RoundTripper {
+	return &cassetteTransport{
+		cassette:   c,
+		underlying: underlying,
+	}
+}
+
+// save writes the recorded interactions to the cassette file.
+func (c *cassette) save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := json.MarshalIndent(c.interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("error creating cassette directory: %w", err)
+	}
+	if err := os.WriteFile(c.path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("error writing cassette: %w", err)
+	}
+	return nil
+}
+
+// unused returns the number of interactions that have not been replayed.
+func (c *cassette) unused() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := 0
+	for _, i := range c.interactions {
+		if !i.used {
+			n++
+		}
+	}
+	return n
+}
+
+// cassetteTransport records API interactions into, or replays them from, a
+// cassette.
+type cassetteTransport struct {
+	cassette   *cassette
+	underlying http.RoundTripper
+}
+
+func (ct *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewBuffer(body)) // Reset body
+	}
+
+	key := cassetteRequest{
+		Method: req.Method,
+		Path:   normalizeCassettePath(req.URL),
+		Body:   normalizeCassetteBody(req.Header.Get("Content-Type"), body),
+	}
+
+	if ct.cassette.mode == cassetteReplay {
+		return ct.cassette.replay(req, key)
+	}
+
+	resp, err := ct.underlying.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewBuffer(respBody)) // Reset body
+
+	interaction := &cassetteInteraction{
+		Request: key,
+		Response: cassetteResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    map[string]string{},
+			Body:       scrubCassetteJSON(respBody),
+		},
+	}
+	for _, h := range cassetteResponseHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			interaction.Response.Headers[h] = v
+		}
+	}
+
+	ct.cassette.record(interaction)
+
+	return resp, nil
+}
+
+// replay returns the first unused recorded interaction matching the request
+// on method, path and normalized body. Interactions are consumed in order so
+// that repeated identical requests (e.g. polling service details) replay the
+// responses in the sequence they were recorded.
+func (c *cassette) replay(req *http.Request, key cassetteRequest) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, i := range c.interactions {
+		if i.used || i.Request != key {
+			continue
+		}
+		i.used = true
+
+		resp := &http.Response{
+			Status:        fmt.Sprintf("%d %s", i.Response.StatusCode, http.StatusText(i.Response.StatusCode)),
+			StatusCode:    i.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{},
+			Body:          io.NopCloser(strings.NewReader(i.Response.Body)),
+			ContentLength: int64(len(i.Response.Body)),
+			Request:       req,
+		}
+		for k, v := range i.Response.Headers {
+			resp.Header.Set(k, v)
+		}
+		return resp, nil
+	}
+
+	return nil, fmt.Errorf("no recorded interaction in cassette (%s) for %s %s", c.path, key.Method, key.Path)
+}
+
+// record appends an interaction to the cassette.
+func (c *cassette) record(i *cassetteInteraction) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.interactions = append(c.interactions, i)
+}
+
+// normalizeCassettePath returns the request path with its query parameters
+// sorted, so that parameter order does not affect matching.
+func normalizeCassettePath(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.Path
+	}
+	return u.Path + "?" + scrubCassetteForm(u.Query()).Encode()
+}
+
+// normalizeCassetteBody returns a canonical, scrubbed representation of a
+// request body. Form bodies are re-encoded with sorted keys and JSON bodies
+// are re-marshalled with sorted keys.
+func normalizeCassetteBody(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	switch {
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return string(body)
+		}
+		return scrubCassetteForm(values).Encode()
+	case strings.Contains(contentType, "json"):
+		return scrubCassetteJSON(body)
+	}
+
+	return string(body)
+}
+
+func scrubCassetteForm(values url.Values) url.Values {
+	for k := range values {
+		if cassetteSecretPattern.MatchString(k) {
+			values[k] = []string{cassetteRedacted}
+		}
+	}
+	return values
+}
+
+// scrubCassetteJSON replaces the values of secret keys anywhere in a JSON
+// document. Bodies that are not valid JSON are returned unchanged.
+func scrubCassetteJSON(body []byte) string {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return string(body)
+	}
+	out, err := json.Marshal(scrubCassetteValue(v))
+	if err != nil {
+		return string(body)
+	}
+	return string(out)
+}
+
+func scrubCassetteValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			if s, ok := val.(string); ok && s != "" && cassetteSecretPattern.MatchString(k) {
+				t[k] = cassetteRedacted
+				continue
+			}
+			t[k] = scrubCassetteValue(val)
+		}
+		return t
+	case []any:
+		for i := range t {
+			t[i] = scrubCassetteValue(t[i])
+		}
+		return t
+	}
+	return v
+}
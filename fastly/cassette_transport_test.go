@@ -0,0 +1,128 @@
+package fastly
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestCassetteTransport_RecordAndReplay(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_ = r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Fastly-Request-Id", "abc")
+		_, _ = io.WriteString(w, `{"number":`+r.PostForm.Get("number")+`,"token":"s3cr3t"}`)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	recording, err := openCassette(cassetteRecord, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post := func(rt http.RoundTripper, body string) string {
+		req, _ := http.NewRequest(http.MethodPut, srv.URL+"/service/abc/version/1/clone?b=2&a=1", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return string(b)
+	}
+
+	// Each Terraform command configures a new API client, so interactions
+	// recorded through different transports must all end up in the cassette.
+	post(recording.transport(http.DefaultTransport), "number=1&api_key=hunter2")
+	post(recording.transport(http.DefaultTransport), "number=2&api_key=hunter2")
+	if err := recording.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"hunter2", "s3cr3t", "Fastly-Request-Id"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %q:\n%s", secret, data)
+		}
+	}
+
+	replaying, err := openCassette(cassetteReplay, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := replaying.unused(); n != 2 {
+		t.Errorf("expected 2 interactions in the cassette, got %d", n)
+	}
+
+	// Form field order and secret values must not affect matching.
+	if got := post(replaying.transport(nil), "api_key=other&number=2"); !strings.Contains(got, `"number":2`) {
+		t.Errorf("unexpected replayed body: %s", got)
+	}
+	if got := post(replaying.transport(nil), "number=1&api_key=other"); !strings.Contains(got, `"number":1`) {
+		t.Errorf("unexpected replayed body: %s", got)
+	}
+	if calls != 2 {
+		t.Errorf("expected replay not to reach the server, got %d calls", calls)
+	}
+
+	req, _ := http.NewRequest(http.MethodPut, srv.URL+"/service/abc/version/1/clone?a=1&b=2", strings.NewReader("number=1&api_key=x"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// Each interaction is only replayed once, across all transports.
+	if _, err := replaying.transport(nil).RoundTrip(req); err == nil {
+		t.Error("expected an error replaying an exhausted interaction")
+	}
+}
+
+func TestOpenCassette_ReplayRequiresCassette(t *testing.T) {
+	_, err := openCassette(cassetteReplay, filepath.Join(t.TempDir(), "missing.json"))
+	if err == nil || !strings.Contains(err.Error(), "error reading cassette") {
+		t.Errorf("expected an error for a missing cassette, got %v", err)
+	}
+}
+
+func TestTestAccCassette_replay(t *testing.T) {
+	t.Setenv(cassetteReplayEnv, "1")
+	t.Setenv("FASTLY_API_KEY", "")
+
+	path := filepath.Join("test_fixtures", "cassettes", t.Name()+".json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("[]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Remove(path) })
+
+	ct := testAccCassetteFor(t)
+
+	// Names are derived from the test name when replaying.
+	if a, b := ct.RandString(10), testAccCassetteFor(t).RandString(10); a != b {
+		t.Errorf("expected the same random string when replaying, got %q and %q", a, b)
+	}
+
+	// Replaying needs no API key, and every configure shares the cassette.
+	p, err := ct.Providers["fastly"]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diags := p.Configure(testCtx(), terraform.NewResourceConfigRaw(map[string]any{})); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if p != ct.Provider {
+		t.Error("expected the factory to return the cassette's provider")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -17,30 +18,67 @@ import (
 //
 // NOTE: The fields correlate to the root TCL schema.
 type Config struct {
-	APIKey     string
-	BaseURL    string
-	ForceHTTP2 bool
-	NoAuth     bool
-	UserAgent  string
-	Context    context.Context
+	APIKey             string
+	APIKeyFromEnv      bool // APIKey is from FASTLY_API_KEY rather than api_key
+	APIKeyCommand      string
+	BaseURL            string
+	ConfigFile         string
+	ExpectedCustomerID string
+	ForceHTTP2         bool
+	Guardrails         Guardrails
+	NoAuth             bool
+	Profile            string
+	RequiredScopes     []string
+	TokenExpiryWarning time.Duration
+	UserAgent          string
+	Context            context.Context
 }
 
 // APIClient is a HTTP API Client.
 type APIClient struct {
 	conn *gofastly.Client
+
+	// guardrails are enforced by the CustomizeDiff functions of the resources
+	// they apply to.
+	guardrails Guardrails
+
+	// datacenters caches the response of GET /datacenters for the lifetime
+	// of the provider instance. See APIClient.allDatacenters.
+	datacentersMu sync.Mutex
+	datacenters   []gofastly.Datacenter
+
+	// identity caches the API token's identity for the lifetime of the
+	// provider instance. See APIClient.currentIdentity.
+	identityMu sync.Mutex
+	identity   *tokenIdentity
 }
 
 // Client returns a FastlyClient.
 func (c *Config) Client() (*APIClient, diag.Diagnostics) {
 	var client APIClient
 
-	if !c.NoAuth && c.APIKey == "" {
-		return nil, diag.FromErr(fmt.Errorf("no API key for Fastly"))
+	// Acceptance tests replaying a cassette never reach the Fastly API, so
+	// they do not need an API key.
+	cassette := cassetteFromContext(c.Context)
+	replaying := cassette != nil && cassette.mode == cassetteReplay
+
+	var apiKey string
+	var diags diag.Diagnostics
+	if !c.NoAuth && !replaying {
+		var err error
+		if apiKey, diags, err = c.resolveAPIKey(); err != nil {
+			return nil, diag.FromErr(fmt.Errorf("unable to get an API key for Fastly: %w", err))
+		}
+		if apiKey == "" {
+			return nil, diag.FromErr(fmt.Errorf("no API key for Fastly: set api_key (or the FASTLY_API_KEY environment variable), api_key_command, or profile to use a Fastly CLI profile"))
+		}
+	} else {
+		apiKey = c.APIKey
 	}
 
 	gofastly.UserAgent = c.UserAgent
 
-	fastlyClient, err := gofastly.NewClientForEndpoint(c.APIKey, c.BaseURL)
+	fastlyClient, err := gofastly.NewClientForEndpoint(apiKey, c.BaseURL)
 	if err != nil {
 		return nil, diag.FromErr(err)
 	}
@@ -94,8 +132,55 @@ func (c *Config) Client() (*APIClient, diag.Diagnostics) {
 		}
 	}
 
+	// NOTE: Cassettes are only used by acceptance tests that opt in with a
+	// per-test cassette (see testAccCassetteFor).
+	if cassette != nil {
+		transport = cassette.transport(transport)
+	}
+
 	fastlyClient.HTTPClient.Transport = transport
 
 	client.conn = fastlyClient
-	return &client, nil
+	client.guardrails = c.Guardrails
+
+	// Verifying the token up front means a token for the wrong account, or
+	// without the scopes a configuration needs, fails before anything is
+	// changed rather than halfway through an apply.
+	verify := c.ExpectedCustomerID != "" || len(c.RequiredScopes) > 0 || c.TokenExpiryWarning > 0
+	if verify && !c.NoAuth && !replaying {
+		ctx := c.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		id, err := client.currentIdentity(ctx)
+		if err != nil {
+			return nil, diag.Errorf("unable to verify the API token: %s", err)
+		}
+		diags = append(diags, id.verify(c.ExpectedCustomerID, c.RequiredScopes, c.TokenExpiryWarning, time.Now())...)
+		if diags.HasError() {
+			return nil, diags
+		}
+	}
+
+	return &client, diags
+}
+
+// allDatacenters returns the Fastly POPs. The list rarely changes, so it is
+// only fetched once per provider instance and shared by the
+// fastly_datacenters data source and plan-time shield validation. Errors are
+// not cached, so a failed request is retried on the next call.
+func (c *APIClient) allDatacenters(ctx context.Context) ([]gofastly.Datacenter, error) {
+	c.datacentersMu.Lock()
+	defer c.datacentersMu.Unlock()
+
+	if c.datacenters != nil {
+		return c.datacenters, nil
+	}
+
+	datacenters, err := c.conn.AllDatacenters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.datacenters = datacenters
+	return datacenters, nil
 }
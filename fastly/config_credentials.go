@@ -0,0 +1,181 @@
+package fastly
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// cliConfig is the subset of the Fastly CLI's config.toml that holds the
+// profiles created with `fastly profile create`.
+type cliConfig struct {
+	Profiles map[string]cliProfile `toml:"profile"`
+}
+
+type cliProfile struct {
+	Default bool   `toml:"default"`
+	Email   string `toml:"email"`
+	Token   string `toml:"token"`
+}
+
+// defaultCLIConfigFile returns the location of the Fastly CLI's config file.
+func defaultCLIConfigFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to locate the Fastly CLI config file, set config_file: %w", err)
+	}
+	return filepath.Join(dir, "fastly", "config.toml"), nil
+}
+
+// cliProfileToken returns the token of the named profile in the Fastly CLI
+// config file at path, or of the default profile if name is empty.
+func cliProfileToken(path, name string) (string, error) {
+	var config cliConfig
+	if _, err := toml.DecodeFile(path, &config); err != nil {
+		return "", fmt.Errorf("unable to read Fastly CLI config file %s: %w", path, err)
+	}
+
+	names := make([]string, 0, len(config.Profiles))
+	for n := range config.Profiles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	if name == "" {
+		for _, n := range names {
+			if config.Profiles[n].Default {
+				name = n
+				break
+			}
+		}
+		if name == "" {
+			return "", fmt.Errorf("no default profile in Fastly CLI config file %s, set profile to one of: %s", path, strings.Join(names, ", "))
+		}
+	}
+
+	profile, ok := config.Profiles[name]
+	if !ok {
+		if len(names) == 0 {
+			return "", fmt.Errorf("profile %q not found in Fastly CLI config file %s, which has no profiles", name, path)
+		}
+		return "", fmt.Errorf("profile %q not found in Fastly CLI config file %s, available profiles: %s", name, path, strings.Join(names, ", "))
+	}
+	if profile.Token == "" {
+		return "", fmt.Errorf("profile %q in Fastly CLI config file %s has no token", name, path)
+	}
+
+	log.Printf("[DEBUG] Using Fastly API key from profile %q in %s", name, path)
+	return profile.Token, nil
+}
+
+// apiKeyCommandCache caches the output of api_key_command for the lifetime of
+// the provider process, so a credential helper runs once per plan or apply
+// rather than once per provider configuration.
+var apiKeyCommandCache = struct {
+	sync.Mutex
+	tokens map[string]string
+}{tokens: map[string]string{}}
+
+// apiKeyCommandToken runs the credential helper command and returns the token
+// it prints to stdout. Only successful results are cached.
+func apiKeyCommandToken(ctx context.Context, command string) (string, error) {
+	apiKeyCommandCache.Lock()
+	defer apiKeyCommandCache.Unlock()
+
+	if token, ok := apiKeyCommandCache.tokens[command]; ok {
+		return token, nil
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	log.Print("[DEBUG] Running api_key_command to fetch the Fastly API key")
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("api_key_command failed: %w: %s", err, msg)
+		}
+		return "", fmt.Errorf("api_key_command failed: %w", err)
+	}
+
+	token := strings.TrimSpace(stdout.String())
+	if token == "" {
+		return "", errors.New("api_key_command printed no API key")
+	}
+	if strings.ContainsAny(token, "\r\n") {
+		return "", errors.New("api_key_command printed more than one line, expected only the API key")
+	}
+
+	apiKeyCommandCache.tokens[command] = token
+	return token, nil
+}
+
+// resolveAPIKey returns the API key to use, from the first of these that is
+// set:
+//
+//  1. api_key.
+//  2. api_key_command.
+//  3. profile and/or config_file, selecting the named profile, or the
+//     default profile, from the Fastly CLI config file.
+//  4. The FASTLY_API_KEY environment variable.
+//
+// It returns an empty key if none is set. The schema makes the arguments
+// conflict, so only FASTLY_API_KEY can be ignored, which is returned as a
+// warning.
+func (c *Config) resolveAPIKey() (string, diag.Diagnostics, error) {
+	profileSet := c.Profile != "" || c.ConfigFile != ""
+
+	if c.APIKey != "" && !c.APIKeyFromEnv {
+		return c.APIKey, nil, nil
+	}
+
+	var diags diag.Diagnostics
+	if c.APIKey != "" && (c.APIKeyCommand != "" || profileSet) {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Ignoring the FASTLY_API_KEY environment variable",
+			Detail:   "The API key from api_key_command, profile or config_file takes precedence over the FASTLY_API_KEY environment variable. Unset FASTLY_API_KEY to remove this warning.",
+		})
+	}
+
+	if c.APIKeyCommand != "" {
+		ctx := c.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		key, err := apiKeyCommandToken(ctx, c.APIKeyCommand)
+		return key, diags, err
+	}
+
+	if profileSet {
+		path := c.ConfigFile
+		if path == "" {
+			var err error
+			if path, err = defaultCLIConfigFile(); err != nil {
+				return "", nil, err
+			}
+		}
+		key, err := cliProfileToken(path, c.Profile)
+		return key, diags, err
+	}
+
+	return c.APIKey, nil, nil
+}
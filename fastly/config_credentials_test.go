@@ -0,0 +1,198 @@
+package fastly
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+const testCLIConfig = `
+config_version = 5
+
+[fastly]
+api_endpoint = "https://api.fastly.com"
+
+[profile]
+
+[profile.personal]
+default = true
+email = "dev@example.com"
+token = "personal-token"
+
+[profile.staging]
+default = false
+email = "dev@example.com"
+token = "staging-token"
+
+[profile.empty]
+email = "dev@example.com"
+`
+
+func writeTestCLIConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCLIProfileToken(t *testing.T) {
+	path := writeTestCLIConfig(t, testCLIConfig)
+
+	for _, tc := range []struct {
+		name    string
+		profile string
+		want    string
+		wantErr string
+	}{
+		{name: "default profile", want: "personal-token"},
+		{name: "named profile", profile: "staging", want: "staging-token"},
+		{name: "unknown profile", profile: "prod", wantErr: `profile "prod" not found in Fastly CLI config file ` + path + `, available profiles: empty, personal, staging`},
+		{name: "profile without token", profile: "empty", wantErr: `profile "empty" in Fastly CLI config file ` + path + ` has no token`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := cliProfileToken(path, tc.profile)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("expected error %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("expected token %q, got %q", tc.want, got)
+			}
+		})
+	}
+
+	noDefault := writeTestCLIConfig(t, "[profile.staging]\ntoken = \"staging-token\"\n")
+	if _, err := cliProfileToken(noDefault, ""); err == nil || !strings.Contains(err.Error(), "no default profile") {
+		t.Errorf("expected a missing default profile error, got %v", err)
+	}
+
+	if _, err := cliProfileToken(filepath.Join(t.TempDir(), "missing.toml"), ""); err == nil || !strings.Contains(err.Error(), "unable to read Fastly CLI config file") {
+		t.Errorf("expected a read error, got %v", err)
+	}
+}
+
+func TestConfigResolveAPIKey(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("api_key_command tests use a POSIX shell")
+	}
+	path := writeTestCLIConfig(t, testCLIConfig)
+
+	for _, tc := range []struct {
+		name        string
+		config      Config
+		want        string
+		wantWarning string
+		wantErr     string
+	}{
+		{
+			name: "nothing set",
+		},
+		{
+			name:   "api_key",
+			config: Config{APIKey: "key"},
+			want:   "key",
+		},
+		{
+			name:   "FASTLY_API_KEY",
+			config: Config{APIKey: "env-key", APIKeyFromEnv: true},
+			want:   "env-key",
+		},
+		{
+			name:        "api_key_command takes precedence over FASTLY_API_KEY",
+			config:      Config{APIKey: "env-key", APIKeyFromEnv: true, APIKeyCommand: "echo command-token"},
+			want:        "command-token",
+			wantWarning: "Ignoring the FASTLY_API_KEY environment variable",
+		},
+		{
+			name:        "profile takes precedence over FASTLY_API_KEY",
+			config:      Config{APIKey: "env-key", APIKeyFromEnv: true, ConfigFile: path, Profile: "staging"},
+			want:        "staging-token",
+			wantWarning: "Ignoring the FASTLY_API_KEY environment variable",
+		},
+		{
+			name:   "profile",
+			config: Config{ConfigFile: path, Profile: "staging"},
+			want:   "staging-token",
+		},
+		{
+			name:   "config_file default profile",
+			config: Config{ConfigFile: path},
+			want:   "personal-token",
+		},
+		{
+			name:    "failing api_key_command",
+			config:  Config{APIKeyCommand: "echo denied >&2; exit 3"},
+			wantErr: "api_key_command failed: exit status 3: denied",
+		},
+		{
+			name:    "empty api_key_command output",
+			config:  Config{APIKeyCommand: "true"},
+			wantErr: "api_key_command printed no API key",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.config.Context = context.Background()
+			got, diags, err := tc.config.resolveAPIKey()
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("expected error %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("expected API key %q, got %q", tc.want, got)
+			}
+			var warning string
+			for _, d := range diags {
+				if d.Severity == diag.Warning {
+					warning = d.Summary
+				}
+			}
+			if warning != tc.wantWarning {
+				t.Errorf("expected warning %q, got %q", tc.wantWarning, warning)
+			}
+		})
+	}
+}
+
+func TestAPIKeyCommandTokenCached(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("api_key_command tests use a POSIX shell")
+	}
+
+	counter := filepath.Join(t.TempDir(), "runs")
+	command := "echo run >> " + counter + "; echo cached-token"
+
+	for range 3 {
+		token, err := apiKeyCommandToken(context.Background(), command)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "cached-token" {
+			t.Fatalf("expected token %q, got %q", "cached-token", token)
+		}
+	}
+
+	runs, err := os.ReadFile(counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(runs), "run"); n != 1 {
+		t.Errorf("expected api_key_command to run once, ran %d times", n)
+	}
+}
@@ -3,11 +3,16 @@ package fastly
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
+	"math"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
 	gofastly "github.com/fastly/go-fastly/v17/fastly"
 
@@ -19,10 +24,62 @@ func dataSourceFastlyDatacenters() *schema.Resource {
 		ReadContext: dataSourceFastlyDatacentersRead,
 
 		Schema: map[string]*schema.Schema{
+			"group": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return POPs in this region of the world, e.g. `Europe` or `North America`.",
+			},
+			"near": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Rank `pops` by great-circle distance from a location. Exactly one of `latitude` and `longitude`, `country` or `cloud_region` must be set.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"cloud_region": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ExactlyOneOf: []string{"near.0.cloud_region", "near.0.country", "near.0.latitude"},
+							Description:  "A cloud provider region in the format `<provider>:<region>`, e.g. `aws:us-east-1`, `gcp:europe-west1` or `azure:westeurope`.",
+						},
+						"country": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ExactlyOneOf: []string{"near.0.cloud_region", "near.0.country", "near.0.latitude"},
+							Description:  "An ISO 3166-1 alpha-2 country code, e.g. `GB`. The country's largest population centre is used as its location.",
+						},
+						"latitude": {
+							Type:         schema.TypeFloat,
+							Optional:     true,
+							RequiredWith: []string{"near.0.longitude"},
+							ExactlyOneOf: []string{"near.0.cloud_region", "near.0.country", "near.0.latitude"},
+							ValidateFunc: validation.FloatBetween(-90, 90),
+							Description:  "Latitude in decimal degrees.",
+						},
+						"longitude": {
+							Type:         schema.TypeFloat,
+							Optional:     true,
+							RequiredWith: []string{"near.0.latitude"},
+							ValidateFunc: validation.FloatBetween(-180, 180),
+							Description:  "Longitude in decimal degrees.",
+						},
+					},
+				},
+			},
+			"nearest_shield": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The shield name of the closest POP available for shielding, suitable for `backend.shield`. Only set when `near` is set.",
+			},
+			"shield_only": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Only return POPs that are available for shielding.",
+			},
 			"pops": {
 				Type:        schema.TypeList,
 				Computed:    true,
-				Description: "A list of all Fastly POPs.",
+				Description: "A list of Fastly POPs matching the filters, ordered by distance when `near` is set.",
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"code": {
@@ -30,11 +87,26 @@ func dataSourceFastlyDatacenters() *schema.Resource {
 							Computed:    true,
 							Description: "A code representing the POP location.",
 						},
+						"distance_km": {
+							Type:        schema.TypeFloat,
+							Computed:    true,
+							Description: "The great-circle distance in kilometres between the POP and the `near` location.",
+						},
 						"group": {
 							Type:        schema.TypeString,
 							Computed:    true,
 							Description: "A code representing the general region of the world in which the POP location resides.",
 						},
+						"latitude": {
+							Type:        schema.TypeFloat,
+							Computed:    true,
+							Description: "The latitude of the POP.",
+						},
+						"longitude": {
+							Type:        schema.TypeFloat,
+							Computed:    true,
+							Description: "The longitude of the POP.",
+						},
 						"name": {
 							Type:        schema.TypeString,
 							Computed:    true,
@@ -53,28 +125,119 @@ func dataSourceFastlyDatacenters() *schema.Resource {
 }
 
 func dataSourceFastlyDatacentersRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
-	conn := meta.(*APIClient).conn
-
 	log.Printf("[DEBUG] Reading datacenters")
 
-	remoteState, err := conn.AllDatacenters(ctx)
+	remoteState, err := meta.(*APIClient).allDatacenters(ctx)
 	if err != nil {
 		return diag.Errorf("error fetching datacenters: %s", err)
 	}
 
-	hashBase, _ := json.Marshal(remoteState)
+	near, err := expandDatacentersNear(d.Get("near").([]any))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	pops := filterDatacenters(remoteState, d.Get("group").(string), d.Get("shield_only").(bool))
+	if near != nil {
+		pops = sortDatacentersByDistance(pops, *near)
+	}
+
+	hashBase, _ := json.Marshal(pops)
 	hashString := strconv.Itoa(hashcode.String(string(hashBase)))
 	d.SetId(hashString)
 
-	if err := d.Set("pops", flattenDatacenters(remoteState)); err != nil {
+	if err := d.Set("pops", flattenDatacenters(pops, near)); err != nil {
 		return diag.Errorf("error setting datacenters: %s", err)
 	}
 
+	var nearestShield string
+	if near != nil {
+		for _, pop := range pops {
+			if s := gofastly.ToValue(pop.Shield); s != "" {
+				nearestShield = s
+				break
+			}
+		}
+	}
+	if err := d.Set("nearest_shield", nearestShield); err != nil {
+		return diag.Errorf("error setting nearest_shield: %s", err)
+	}
+
 	return nil
 }
 
+// expandDatacentersNear resolves the `near` block to a location. It returns
+// nil if the block is not set. The schema guarantees that only one kind of
+// location is set.
+func expandDatacentersNear(near []any) (*location, error) {
+	if len(near) == 0 || near[0] == nil {
+		return nil, nil
+	}
+	m := near[0].(map[string]any)
+
+	var (
+		l   location
+		err error
+	)
+	switch {
+	case m["cloud_region"].(string) != "":
+		l, err = lookupCloudRegion(m["cloud_region"].(string))
+	case m["country"].(string) != "":
+		l, err = lookupCountry(m["country"].(string))
+	default:
+		l = location{Latitude: m["latitude"].(float64), Longitude: m["longitude"].(float64)}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// filterDatacenters returns the POPs in the given group (if set) that are
+// available for shielding (if shieldOnly is set).
+func filterDatacenters(pops []gofastly.Datacenter, group string, shieldOnly bool) []gofastly.Datacenter {
+	result := make([]gofastly.Datacenter, 0, len(pops))
+	for _, pop := range pops {
+		if group != "" && !strings.EqualFold(gofastly.ToValue(pop.Group), group) {
+			continue
+		}
+		if shieldOnly && gofastly.ToValue(pop.Shield) == "" {
+			continue
+		}
+		result = append(result, pop)
+	}
+	return result
+}
+
+// datacenterLocation returns the location of a POP, if the API reported one.
+func datacenterLocation(pop gofastly.Datacenter) (location, bool) {
+	if pop.Coordinates == nil || pop.Coordinates.Latitude == nil || pop.Coordinates.Longtitude == nil {
+		return location{}, false
+	}
+	return location{Latitude: *pop.Coordinates.Latitude, Longitude: *pop.Coordinates.Longtitude}, true
+}
+
+// sortDatacentersByDistance returns the POPs ordered by distance from near.
+// POPs without coordinates are placed last.
+func sortDatacentersByDistance(pops []gofastly.Datacenter, near location) []gofastly.Datacenter {
+	result := append([]gofastly.Datacenter(nil), pops...)
+
+	distance := func(pop gofastly.Datacenter) float64 {
+		if l, ok := datacenterLocation(pop); ok {
+			return near.distanceKm(l)
+		}
+		return math.Inf(1)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return distance(result[i]) < distance(result[j])
+	})
+	return result
+}
+
 // flattenDatacenters models data into format suitable for saving to Terraform state.
-func flattenDatacenters(remoteState []gofastly.Datacenter) []map[string]any {
+// Distances are only included when near is set.
+func flattenDatacenters(remoteState []gofastly.Datacenter, near *location) []map[string]any {
 	result := make([]map[string]any, len(remoteState))
 	if len(remoteState) == 0 {
 		return result
@@ -95,6 +258,13 @@ func flattenDatacenters(remoteState []gofastly.Datacenter) []map[string]any {
 		if resource.Shield != nil {
 			data["shield"] = *resource.Shield
 		}
+		if l, ok := datacenterLocation(resource); ok {
+			data["latitude"] = l.Latitude
+			data["longitude"] = l.Longitude
+			if near != nil {
+				data["distance_km"] = math.Round(near.distanceKm(l))
+			}
+		}
 
 		// Prune any empty values that come from the default string value in structs.
 		for k, v := range data {
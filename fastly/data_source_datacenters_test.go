@@ -3,11 +3,15 @@ package fastly
 import (
 	"context"
 	"fmt"
+	"math"
+	"reflect"
 	"strconv"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	gofastly "github.com/fastly/go-fastly/v17/fastly"
 )
 
 func TestAccFastlyDataSource_Datacenters(t *testing.T) {
@@ -37,6 +41,102 @@ func TestAccFastlyDataSource_Datacenters(t *testing.T) {
 	})
 }
 
+func TestSortDatacentersByDistance(t *testing.T) {
+	pop := func(code, shield string, lat, lon float64) gofastly.Datacenter {
+		return gofastly.Datacenter{
+			Code:        gofastly.ToPointer(code),
+			Group:       gofastly.ToPointer("Europe"),
+			Shield:      gofastly.ToPointer(shield),
+			Coordinates: &gofastly.Coordinates{Latitude: gofastly.ToPointer(lat), Longtitude: gofastly.ToPointer(lon)},
+		}
+	}
+	pops := []gofastly.Datacenter{
+		pop("FRA", "frankfurt-de", 50.11, 8.68),
+		{Code: gofastly.ToPointer("XXX")},
+		pop("LHR", "", 51.47, -0.45),
+		pop("LCY", "london_city-uk", 51.51, 0.05),
+		pop("CDG", "cdg-par-fr", 49.01, 2.55),
+	}
+
+	near, err := expandDatacentersNear([]any{map[string]any{"cloud_region": "aws:eu-west-2", "country": "", "latitude": 0.0, "longitude": 0.0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, p := range sortDatacentersByDistance(pops, *near) {
+		got = append(got, gofastly.ToValue(p.Code))
+	}
+	if want := []string{"LCY", "LHR", "CDG", "FRA", "XXX"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected order: got %v, want %v", got, want)
+	}
+
+	var shields []string
+	for _, p := range filterDatacenters(pops, "europe", true) {
+		shields = append(shields, gofastly.ToValue(p.Code))
+	}
+	if want := []string{"FRA", "LCY", "CDG"}; !reflect.DeepEqual(shields, want) {
+		t.Errorf("unexpected shield POPs: got %v, want %v", shields, want)
+	}
+}
+
+func TestExpandDatacentersNear(t *testing.T) {
+	for name, testcase := range map[string]struct {
+		near    map[string]any
+		want    location
+		wantErr string
+	}{
+		"coordinates": {
+			near: map[string]any{"cloud_region": "", "country": "", "latitude": 1.5, "longitude": -2.5},
+			want: location{1.5, -2.5},
+		},
+		"country": {
+			near: map[string]any{"cloud_region": "", "country": "gb", "latitude": 0.0, "longitude": 0.0},
+			want: countryLocations["GB"],
+		},
+		"cloud region alias": {
+			near: map[string]any{"cloud_region": "Google:europe-west1", "country": "", "latitude": 0.0, "longitude": 0.0},
+			want: cloudRegionLocations["gcp:europe-west1"],
+		},
+		"unknown cloud region": {
+			near:    map[string]any{"cloud_region": "aws:us-east-9", "country": "", "latitude": 0.0, "longitude": 0.0},
+			wantErr: `unknown cloud_region "aws:us-east-9", did you mean "aws:us-east-1", "aws:us-east-2", "aws:ap-east-1"?`,
+		},
+		"unknown provider": {
+			near:    map[string]any{"cloud_region": "oci:us-ashburn-1", "country": "", "latitude": 0.0, "longitude": 0.0},
+			wantErr: `unsupported cloud provider "oci" in cloud_region, expected one of aws, gcp or azure`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := expandDatacentersNear([]any{testcase.near})
+			if testcase.wantErr != "" {
+				if err == nil || err.Error() != testcase.wantErr {
+					t.Fatalf("expected error %q, got %v", testcase.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != testcase.want {
+				t.Errorf("got %v, want %v", *got, testcase.want)
+			}
+		})
+	}
+}
+
+func TestLocationDistanceKm(t *testing.T) {
+	london := location{51.51, -0.13}
+	paris := location{48.86, 2.35}
+
+	if d := london.distanceKm(paris); math.Abs(d-344) > 5 {
+		t.Errorf("expected London to Paris to be about 344km, got %.0fkm", d)
+	}
+	if d := london.distanceKm(london); d != 0 {
+		t.Errorf("expected zero distance, got %f", d)
+	}
+}
+
 func testAccFastlyDataSourceDatacentersState(n string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		r := s.RootModule().Resources[n]
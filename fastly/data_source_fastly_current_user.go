@@ -0,0 +1,93 @@
+package fastly
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceFastlyCurrentUser() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceFastlyCurrentUserRead,
+		Schema: map[string]*schema.Schema{
+			"customer_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The ID of the customer the user belongs to.",
+			},
+			"login": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The login associated with the user (typically, an email address).",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The real life name of the user.",
+			},
+			"role": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The role of the user, e.g. `user`, `billing`, `engineer` or `superuser`.",
+			},
+			"token_expires_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Timestamp (RFC 3339) when the API token expires. Empty if the token does not expire.",
+			},
+			"token_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The ID of the API token the provider is configured with.",
+			},
+			"token_name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The name of the API token the provider is configured with.",
+			},
+			"token_scopes": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The scopes of the API token the provider is configured with.",
+			},
+		},
+	}
+}
+
+func dataSourceFastlyCurrentUserRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	log.Printf("[DEBUG] Reading current user")
+
+	id, err := meta.(*APIClient).currentIdentity(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(id.UserID)
+
+	var expiresAt string
+	if id.TokenExpiresAt != nil {
+		expiresAt = id.TokenExpiresAt.UTC().Format(time.RFC3339)
+	}
+
+	attrs := map[string]any{
+		"customer_id":      id.CustomerID,
+		"login":            id.Login,
+		"name":             id.Name,
+		"role":             id.Role,
+		"token_expires_at": expiresAt,
+		"token_id":         id.TokenID,
+		"token_name":       id.TokenName,
+		"token_scopes":     id.TokenScopes,
+	}
+	for k, v := range attrs {
+		if err := d.Set(k, v); err != nil {
+			return diag.Errorf("error setting %s: %s", k, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package fastly
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccFastlyDataSourceCurrentUser_Config(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProviderFactories: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `data "fastly_current_user" "example" {}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.fastly_current_user.example", "customer_id"),
+					resource.TestCheckResourceAttrSet("data.fastly_current_user.example", "login"),
+					resource.TestCheckResourceAttrSet("data.fastly_current_user.example", "token_id"),
+				),
+			},
+		},
+	})
+}
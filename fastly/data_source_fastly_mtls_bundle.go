@@ -0,0 +1,88 @@
+package fastly
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceFastlyMTLSBundle() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceFastlyMTLSBundleRead,
+
+		Schema: map[string]*schema.Schema{
+			"cert_bundle": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The merged PEM-formatted bundle, for use as `cert_bundle` of `fastly_tls_mutual_authentication`.",
+			},
+			"certificates": mtlsBundleCertificatesSchema(),
+			"contents": {
+				Type:         schema.TypeList,
+				Optional:     true,
+				Description:  "PEM-formatted certificate bundles to merge, e.g. from a secret store. At least one of `contents` and `files` must be set.",
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				AtLeastOneOf: []string{"contents", "files"},
+			},
+			"files": {
+				Type:         schema.TypeList,
+				Optional:     true,
+				Description:  "Paths to files containing PEM-formatted certificate bundles to merge. At least one of `contents` and `files` must be set.",
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				AtLeastOneOf: []string{"contents", "files"},
+			},
+		},
+	}
+}
+
+func dataSourceFastlyMTLSBundleRead(_ context.Context, d *schema.ResourceData, _ any) diag.Diagnostics {
+	log.Printf("[DEBUG] Merging mTLS certificate bundles")
+
+	var bundles []string
+	for _, path := range d.Get("files").([]any) {
+		// G304 (CWE-22): Potential file inclusion via variable
+		// #nosec
+		data, err := os.ReadFile(path.(string))
+		if err != nil {
+			return diag.Errorf("failed to read certificate bundle '%s': %s", path, err)
+		}
+		bundles = append(bundles, string(data))
+	}
+	for _, content := range d.Get("contents").([]any) {
+		bundles = append(bundles, content.(string))
+	}
+
+	bundle, certs, err := mergeMTLSBundle(bundles)
+	if err != nil {
+		return diag.Errorf("failed to merge certificate bundles: %s", err)
+	}
+
+	sum := sha256.Sum256([]byte(bundle))
+	d.SetId(hex.EncodeToString(sum[:]))
+
+	if err := d.Set("cert_bundle", bundle); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("certificates", flattenMTLSBundleCertificates(certs)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Report problems here as well as when planning the mutual
+	// authentication, as the bundle may be used elsewhere.
+	if err := checkMTLSBundle(certs, time.Now()); err != nil {
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  "Certificate bundle cannot be used for mutual TLS",
+			Detail:   fmt.Sprint(err),
+		}}
+	}
+
+	return nil
+}
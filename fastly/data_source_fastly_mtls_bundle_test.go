@@ -0,0 +1,47 @@
+package fastly
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccFastlyDataSourceMTLSBundle_Config(t *testing.T) {
+	_, ca1, err := generateKeyAndCert("a.example.com")
+	require.NoError(t, err)
+	_, ca2, err := generateKeyAndCert("b.example.com")
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "bundle.pem")
+	require.NoError(t, os.WriteFile(file, []byte(ca1+"\n"+ca2+"\n"), 0o600))
+
+	dataSourceName := "data.fastly_mtls_bundle.example"
+	resource.ParallelTest(t, resource.TestCase{
+		ProviderFactories: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+        data "fastly_mtls_bundle" "example" {
+          files    = ["%s"]
+          contents = ["%s"]
+        }
+        `, file, strings.ReplaceAll(ca2, "\n", `\n`)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "certificates.#", "2"),
+					resource.TestCheckResourceAttr(dataSourceName, "certificates.0.is_ca", "true"),
+					resource.TestCheckResourceAttr(dataSourceName, "certificates.1.is_ca", "true"),
+					resource.TestMatchResourceAttr(dataSourceName, "certificates.0.subject", regexp.MustCompile(`CN=[ab]\.example\.com`)),
+					resource.TestCheckResourceAttrSet(dataSourceName, "certificates.0.fingerprint"),
+					resource.TestCheckResourceAttrSet(dataSourceName, "certificates.1.not_after"),
+					resource.TestCheckResourceAttrSet(dataSourceName, "cert_bundle"),
+				),
+			},
+		},
+	})
+}
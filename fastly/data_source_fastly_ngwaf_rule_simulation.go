@@ -0,0 +1,209 @@
+package fastly
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/fastly/terraform-provider-fastly/fastly/hashcode"
+)
+
+// dataSourceFastlyNGWAFRuleTest returns the fastly_ngwaf_rule_test data
+// source, which evaluates a rule against sample requests locally. It lives in
+// this file as Go treats files ending in _test.go as tests.
+func dataSourceFastlyNGWAFRuleTest() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"list": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Lists referenced by `in_list` and `not_in_list` conditions, such as those returned by the `fastly_ngwaf_workspace_lists` data source with `include_entries = true`.",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"entries": {
+						Type:        schema.TypeList,
+						Required:    true,
+						Description: "The values in the list.",
+						Elem: &schema.Schema{
+							Type: schema.TypeString,
+						},
+					},
+					"reference_id": {
+						Type:        schema.TypeString,
+						Required:    true,
+						Description: "The reference ID of the list, as used in conditions (e.g., `site.blocked`).",
+					},
+					"type": {
+						Type:             schema.TypeString,
+						Optional:         true,
+						Default:          "string",
+						Description:      "The type of list. Accepted values are `string`, `wildcard`, `ip`, `country`, and `signal`. Entries of `ip` lists may be CIDR ranges.",
+						ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{"string", "wildcard", "ip", "country", "signal"}, false)),
+					},
+				},
+			},
+		},
+		"request": {
+			Type:        schema.TypeList,
+			Required:    true,
+			MinItems:    1,
+			Description: "Sample requests to evaluate the rule against.",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"country": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						Description: "The two-letter country code of the client.",
+					},
+					"headers": {
+						Type:        schema.TypeMap,
+						Optional:    true,
+						Description: "The request headers. The `domain` and `user_agent` fields are taken from the `Host` and `User-Agent` headers.",
+						Elem: &schema.Schema{
+							Type: schema.TypeString,
+						},
+					},
+					"ip": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						Description: "The IP address of the client.",
+					},
+					"method": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						Default:     "GET",
+						Description: "The request method. Default `GET`.",
+					},
+					"path": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						Default:     "/",
+						Description: "The request path, optionally with a query string. Default `/`.",
+					},
+				},
+			},
+		},
+		"results": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "The result for each request, in the order of the `request` blocks.",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"actions": {
+						Type:        schema.TypeList,
+						Computed:    true,
+						Description: "The types of the rule's actions if the request matched, otherwise empty.",
+						Elem: &schema.Schema{
+							Type: schema.TypeString,
+						},
+					},
+					"matched": {
+						Type:        schema.TypeBool,
+						Computed:    true,
+						Description: "Whether the request matched the rule's conditions.",
+					},
+					"signals": {
+						Type:        schema.TypeList,
+						Computed:    true,
+						Description: "The signals of the rule's actions if the request matched, otherwise empty.",
+						Elem: &schema.Schema{
+							Type: schema.TypeString,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// The rule is defined the same way as for the rule resources.
+	rule := resourceFastlyNGWAFRuleBase()
+	for _, k := range []string{"action", "condition", "expression", "group_condition", "group_operator", "multival_condition"} {
+		s[k] = rule.Schema[k]
+	}
+
+	return &schema.Resource{
+		ReadContext: dataSourceFastlyNGWAFRuleTestRead,
+		Schema:      s,
+	}
+}
+
+func dataSourceFastlyNGWAFRuleTestRead(_ context.Context, d *schema.ResourceData, _ any) diag.Diagnostics {
+	conditions, err := expandNGWAFRuleConditions(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if len(conditions.conditions)+len(conditions.groupConditions)+len(conditions.multivalConditions) == 0 {
+		return diag.Errorf("rule must define at least one 'condition', 'group_condition', or 'multival_condition', or an 'expression'")
+	}
+
+	lists := map[string]ngwafRuleList{}
+	for _, raw := range d.Get("list").([]any) {
+		m := raw.(map[string]any)
+		lists[m["reference_id"].(string)] = ngwafRuleList{
+			listType: m["type"].(string),
+			entries:  expandStringList(m["entries"].([]any)),
+		}
+	}
+
+	var actions, signals []string
+	for _, raw := range d.Get("action").([]any) {
+		m := raw.(map[string]any)
+		actions = append(actions, m["type"].(string))
+		if signal, ok := m["signal"].(string); ok && signal != "" {
+			signals = append(signals, signal)
+		}
+	}
+
+	e := newNGWAFRuleEvaluator(lists)
+	var results []map[string]any
+	for i, raw := range d.Get("request").([]any) {
+		m := raw.(map[string]any)
+		req := &ngwafSampleRequest{
+			method:  m["method"].(string),
+			path:    m["path"].(string),
+			headers: map[string]string{},
+			ip:      m["ip"].(string),
+			country: m["country"].(string),
+		}
+		for k, v := range m["headers"].(map[string]any) {
+			req.headers[k] = v.(string)
+		}
+
+		matched, err := e.matches(conditions, req)
+		if err != nil {
+			return diag.Errorf("error evaluating request %d: %s", i, err)
+		}
+		result := map[string]any{
+			"actions": []string{},
+			"matched": matched,
+			"signals": []string{},
+		}
+		if matched {
+			result["actions"] = actions
+			result["signals"] = signals
+		}
+		results = append(results, result)
+	}
+
+	if err := d.Set("results", results); err != nil {
+		return diag.FromErr(err)
+	}
+
+	parsed, _ := json.Marshal([]any{d.Get("expression"), d.Get("condition"), d.Get("group_condition"), d.Get("multival_condition"), d.Get("request"), results})
+	d.SetId(strconv.Itoa(hashcode.String(string(parsed))))
+
+	if fields := e.unsupportedFields(); len(fields) > 0 {
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  "Some conditions cannot be evaluated locally",
+			Detail:   fmt.Sprintf("Conditions on %s never match, as they depend on more than the method, path, headers, IP and country of a request.", strings.Join(fields, ", ")),
+		}}
+	}
+
+	return nil
+}
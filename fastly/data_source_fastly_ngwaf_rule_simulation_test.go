@@ -0,0 +1,109 @@
+package fastly
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccFastlyDataSourceNGWAFRuleTest_Config(t *testing.T) {
+	dataSourceName := "data.fastly_ngwaf_rule_test.example"
+	resource.ParallelTest(t, resource.TestCase{
+		ProviderFactories: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+        data "fastly_ngwaf_rule_test" "example" {
+          expression = "ip in list(\"site.blocked\") and (path ~ \"^/admin\" or method == \"DELETE\")"
+
+          action {
+            type = "block"
+          }
+
+          list {
+            reference_id = "site.blocked"
+            type         = "ip"
+            entries      = ["192.0.2.0/24"]
+          }
+
+          request {
+            method = "DELETE"
+            path   = "/api/users/1"
+            ip     = "192.0.2.10"
+          }
+
+          request {
+            path = "/admin"
+            ip   = "198.51.100.1"
+          }
+        }
+        `,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "results.#", "2"),
+					resource.TestCheckResourceAttr(dataSourceName, "results.0.matched", "true"),
+					resource.TestCheckResourceAttr(dataSourceName, "results.0.actions.0", "block"),
+					resource.TestCheckResourceAttr(dataSourceName, "results.1.matched", "false"),
+					resource.TestCheckResourceAttr(dataSourceName, "results.1.actions.#", "0"),
+				),
+			},
+			{
+				Config: `
+        data "fastly_ngwaf_rule_test" "example" {
+          group_operator = "any"
+
+          action {
+            type   = "add_signal"
+            signal = "site.admin-access"
+          }
+
+          condition {
+            field    = "path"
+            operator = "like"
+            value    = "/admin*"
+          }
+
+          multival_condition {
+            field          = "request_header"
+            operator       = "exists"
+            group_operator = "all"
+
+            condition {
+              field    = "name"
+              operator = "equals"
+              value    = "X-Admin-Token"
+            }
+          }
+
+          request {
+            path    = "/"
+            headers = {
+              "x-admin-token" = "1"
+            }
+          }
+        }
+        `,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "results.0.matched", "true"),
+					resource.TestCheckResourceAttr(dataSourceName, "results.0.signals.0", "site.admin-access"),
+				),
+			},
+			{
+				Config: `
+        data "fastly_ngwaf_rule_test" "example" {
+          expression = "ip in list(\"site.missing\")"
+
+          action {
+            type = "block"
+          }
+
+          request {
+            ip = "192.0.2.10"
+          }
+        }
+        `,
+				ExpectError: regexp.MustCompile(`list "site.missing" is not defined in a list block`),
+			},
+		},
+	})
+}
@@ -19,6 +19,12 @@ func dataSourceFastlyNGWAFWorkspaceLists() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceFastlyNGWAFWorkspaceListsRead,
 		Schema: map[string]*schema.Schema{
+			"include_entries": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Set to `true` to include the entries of each list, which stores them all in the state. Default `false`.",
+			},
 			"lists": {
 				Type:        schema.TypeList,
 				Computed:    true,
@@ -35,6 +41,14 @@ func dataSourceFastlyNGWAFWorkspaceLists() *schema.Resource {
 							Computed:    true,
 							Description: "The description of the list.",
 						},
+						"entries": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "The values in the list. Only set when `include_entries` is `true`.",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
 						"id": {
 							Type:        schema.TypeString,
 							Computed:    true,
@@ -100,14 +114,14 @@ func dataSourceFastlyNGWAFWorkspaceListsRead(ctx context.Context, d *schema.Reso
 		listPtrs = append(listPtrs, &remoteState.Data[i])
 	}
 
-	if err := d.Set("lists", flattenNGWAFWorkspaceLists(listPtrs)); err != nil {
+	if err := d.Set("lists", flattenNGWAFWorkspaceLists(listPtrs, d.Get("include_entries").(bool))); err != nil {
 		return diag.Errorf("error setting lists: %s", err)
 	}
 
 	return nil
 }
 
-func flattenNGWAFWorkspaceLists(remoteState []*lists.List) []map[string]any {
+func flattenNGWAFWorkspaceLists(remoteState []*lists.List, includeEntries bool) []map[string]any {
 	result := make([]map[string]any, len(remoteState))
 
 	for i, list := range remoteState {
@@ -120,6 +134,9 @@ func flattenNGWAFWorkspaceLists(remoteState []*lists.List) []map[string]any {
 			"type":         list.Type,
 			"updated_at":   list.UpdatedAt.Format("2006-01-02T15:04:05Z"),
 		}
+		if includeEntries {
+			result[i]["entries"] = list.Entries
+		}
 	}
 
 	return result
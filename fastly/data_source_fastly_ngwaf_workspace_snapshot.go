@@ -0,0 +1,465 @@
+package fastly
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/fastly/terraform-provider-fastly/fastly/hashcode"
+)
+
+func dataSourceFastlyNGWAFWorkspaceSnapshot() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceFastlyNGWAFWorkspaceSnapshotRead,
+		Schema: map[string]*schema.Schema{
+			"json": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The snapshot as JSON, as accepted by the `snapshot` argument of `fastly_ngwaf_workspace_sync`.",
+			},
+			"lists": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The lists of the workspace, sorted by name.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"description": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The description of the list.",
+						},
+						"entries": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "The values in the list, sorted.",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the list.",
+						},
+						"reference_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The reference ID of the list.",
+						},
+						"type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The type of the list.",
+						},
+					},
+				},
+			},
+			"redactions": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The redactions of the workspace, sorted by type and field.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"field": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the field that is being redacted.",
+						},
+						"type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The type of field being redacted.",
+						},
+					},
+				},
+			},
+			"rules": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The rules of the workspace.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"action": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "The actions of the rule.",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"allow_interactive": {
+										Type:     schema.TypeBool,
+										Computed: true,
+									},
+									"deception_type": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"redirect_url": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"response_code": {
+										Type:     schema.TypeInt,
+										Computed: true,
+									},
+									"signal": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"type": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+								},
+							},
+						},
+						"description": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The description of the rule.",
+						},
+						"enabled": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the rule is enabled.",
+						},
+						"expression": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The conditions of the rule as an expression.",
+						},
+						"rate_limit": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "The rate limit of a `rate_limit` rule.",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"client_identifiers": {
+										Type:     schema.TypeList,
+										Computed: true,
+										Elem: &schema.Resource{
+											Schema: map[string]*schema.Schema{
+												"key": {
+													Type:     schema.TypeString,
+													Computed: true,
+												},
+												"name": {
+													Type:     schema.TypeString,
+													Computed: true,
+												},
+												"type": {
+													Type:     schema.TypeString,
+													Computed: true,
+												},
+											},
+										},
+									},
+									"duration": {
+										Type:     schema.TypeInt,
+										Computed: true,
+									},
+									"interval": {
+										Type:     schema.TypeInt,
+										Computed: true,
+									},
+									"signal": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"threshold": {
+										Type:     schema.TypeInt,
+										Computed: true,
+									},
+								},
+							},
+						},
+						"request_logging": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Logging behavior for matching requests.",
+						},
+						"type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The type of the rule.",
+						},
+					},
+				},
+			},
+			"signals": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The custom signals of the workspace, sorted by name.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"description": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The description of the signal.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the signal.",
+						},
+						"reference_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The reference ID of the signal.",
+						},
+					},
+				},
+			},
+			"thresholds": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The thresholds of the workspace, sorted by name.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"action": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Action to take when the threshold is exceeded.",
+						},
+						"dont_notify": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether notifications are silenced when action is taken.",
+						},
+						"duration": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Duration the action is in place, in seconds.",
+						},
+						"enabled": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the threshold is active.",
+						},
+						"interval": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Threshold interval in seconds.",
+						},
+						"limit": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Threshold limit.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the threshold.",
+						},
+						"signal": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the signal the threshold is acting on.",
+						},
+					},
+				},
+			},
+			"virtual_patches": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The virtual patches of the workspace, sorted by ID.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"enabled": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the virtual patch is enabled.",
+						},
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The ID of the virtual patch.",
+						},
+						"mode": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Action to take when a signal for the virtual patch is detected.",
+						},
+					},
+				},
+			},
+			"workspace_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The ID of the workspace.",
+			},
+		},
+	}
+}
+
+func dataSourceFastlyNGWAFWorkspaceSnapshotRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	workspaceID := d.Get("workspace_id").(string)
+
+	log.Printf("[DEBUG] Reading NGWAF workspace snapshot for workspace: %s", workspaceID)
+
+	snapshot, err := readNGWAFWorkspaceSnapshot(ctx, conn, workspaceID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	raw, err := snapshot.json()
+	if err != nil {
+		return diag.Errorf("error encoding snapshot: %s", err)
+	}
+	d.SetId(strconv.Itoa(hashcode.String(raw)))
+
+	if err := d.Set("json", raw); err != nil {
+		return diag.Errorf("error setting json: %s", err)
+	}
+	if err := d.Set("lists", flattenNGWAFSnapshotLists(snapshot.Lists)); err != nil {
+		return diag.Errorf("error setting lists: %s", err)
+	}
+	if err := d.Set("redactions", flattenNGWAFSnapshotRedactions(snapshot.Redactions)); err != nil {
+		return diag.Errorf("error setting redactions: %s", err)
+	}
+	if err := d.Set("rules", flattenNGWAFSnapshotRules(snapshot.Rules)); err != nil {
+		return diag.Errorf("error setting rules: %s", err)
+	}
+	if err := d.Set("signals", flattenNGWAFSnapshotSignals(snapshot.Signals)); err != nil {
+		return diag.Errorf("error setting signals: %s", err)
+	}
+	if err := d.Set("thresholds", flattenNGWAFSnapshotThresholds(snapshot.Thresholds)); err != nil {
+		return diag.Errorf("error setting thresholds: %s", err)
+	}
+	if err := d.Set("virtual_patches", flattenNGWAFSnapshotVirtualPatches(snapshot.VirtualPatches)); err != nil {
+		return diag.Errorf("error setting virtual_patches: %s", err)
+	}
+
+	return nil
+}
+
+func flattenNGWAFSnapshotLists(remoteState []ngwafSnapshotList) []map[string]any {
+	result := make([]map[string]any, len(remoteState))
+
+	for i, l := range remoteState {
+		result[i] = map[string]any{
+			"description":  l.Description,
+			"entries":      l.Entries,
+			"name":         l.Name,
+			"reference_id": l.ReferenceID,
+			"type":         l.Type,
+		}
+	}
+
+	return result
+}
+
+func flattenNGWAFSnapshotRedactions(remoteState []ngwafSnapshotRedaction) []map[string]any {
+	result := make([]map[string]any, len(remoteState))
+
+	for i, r := range remoteState {
+		result[i] = map[string]any{
+			"field": r.Field,
+			"type":  r.Type,
+		}
+	}
+
+	return result
+}
+
+func flattenNGWAFSnapshotRules(remoteState []ngwafSnapshotRule) []map[string]any {
+	result := make([]map[string]any, len(remoteState))
+
+	for i, r := range remoteState {
+		actions := make([]map[string]any, len(r.Action))
+		for j, a := range r.Action {
+			actions[j] = map[string]any{
+				"allow_interactive": a.AllowInteractive,
+				"deception_type":    a.DeceptionType,
+				"redirect_url":      a.RedirectURL,
+				"response_code":     a.ResponseCode,
+				"signal":            a.Signal,
+				"type":              a.Type,
+			}
+		}
+
+		var rateLimit []map[string]any
+		if rl := r.RateLimit; rl != nil {
+			clientIdentifiers := make([]map[string]any, len(rl.ClientIdentifiers))
+			for j, ci := range rl.ClientIdentifiers {
+				clientIdentifiers[j] = map[string]any{
+					"key":  ci.Key,
+					"name": ci.Name,
+					"type": ci.Type,
+				}
+			}
+			rateLimit = []map[string]any{
+				{
+					"client_identifiers": clientIdentifiers,
+					"duration":           rl.Duration,
+					"interval":           rl.Interval,
+					"signal":             rl.Signal,
+					"threshold":          rl.Threshold,
+				},
+			}
+		}
+
+		result[i] = map[string]any{
+			"action":          actions,
+			"description":     r.Description,
+			"enabled":         r.Enabled,
+			"expression":      r.Expression,
+			"rate_limit":      rateLimit,
+			"request_logging": r.RequestLogging,
+			"type":            r.Type,
+		}
+	}
+
+	return result
+}
+
+func flattenNGWAFSnapshotSignals(remoteState []ngwafSnapshotSignal) []map[string]any {
+	result := make([]map[string]any, len(remoteState))
+
+	for i, s := range remoteState {
+		result[i] = map[string]any{
+			"description":  s.Description,
+			"name":         s.Name,
+			"reference_id": s.ReferenceID,
+		}
+	}
+
+	return result
+}
+
+func flattenNGWAFSnapshotThresholds(remoteState []ngwafSnapshotThreshold) []map[string]any {
+	result := make([]map[string]any, len(remoteState))
+
+	for i, t := range remoteState {
+		result[i] = map[string]any{
+			"action":      t.Action,
+			"dont_notify": t.DontNotify,
+			"duration":    t.Duration,
+			"enabled":     t.Enabled,
+			"interval":    t.Interval,
+			"limit":       t.Limit,
+			"name":        t.Name,
+			"signal":      t.Signal,
+		}
+	}
+
+	return result
+}
+
+func flattenNGWAFSnapshotVirtualPatches(remoteState []ngwafSnapshotVirtualPatch) []map[string]any {
+	result := make([]map[string]any, len(remoteState))
+
+	for i, vp := range remoteState {
+		result[i] = map[string]any{
+			"enabled": vp.Enabled,
+			"id":      vp.ID,
+			"mode":    vp.Mode,
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,89 @@
+package fastly
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccFastlyNGWAFWorkspaceSnapshot_Config(t *testing.T) {
+	h := generateHex()
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProviderFactories: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFastlyDataSourceNGWAFWorkspaceSnapshotConfig(h),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.fastly_ngwaf_workspace_snapshot.test", "lists.#", "1"),
+					resource.TestCheckResourceAttr("data.fastly_ngwaf_workspace_snapshot.test", "lists.0.entries.#", "2"),
+					resource.TestCheckResourceAttr("data.fastly_ngwaf_workspace_snapshot.test", "lists.0.entries.0", "10.0.0.1"),
+					resource.TestCheckResourceAttr("data.fastly_ngwaf_workspace_snapshot.test", "signals.#", "1"),
+					resource.TestCheckResourceAttr("data.fastly_ngwaf_workspace_snapshot.test", "rules.#", "1"),
+					resource.TestCheckResourceAttr("data.fastly_ngwaf_workspace_snapshot.test", "rules.0.action.0.type", "add_signal"),
+					resource.TestCheckResourceAttr("data.fastly_ngwaf_workspace_snapshot.test", "rules.0.expression", `path ~ "^/admin" and method == "POST"`),
+					resource.TestCheckResourceAttrPair(
+						"data.fastly_ngwaf_workspace_snapshot.test", "rules.0.action.0.signal",
+						"fastly_ngwaf_workspace_signal.example", "reference_id",
+					),
+					func(s *terraform.State) error {
+						a := s.RootModule().Resources["data.fastly_ngwaf_workspace_snapshot.test"].Primary.Attributes
+						snapshot, err := parseNGWAFWorkspaceSnapshot(a["json"])
+						if err != nil {
+							return err
+						}
+						if len(snapshot.Lists) != 1 || snapshot.Lists[0].ReferenceID != a["lists.0.reference_id"] {
+							return fmt.Errorf("expected the JSON to match the lists attribute, got %#v", snapshot.Lists)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func testAccFastlyDataSourceNGWAFWorkspaceSnapshotConfig(h string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "fastly_ngwaf_workspace_list" "example" {
+  workspace_id = fastly_ngwaf_workspace.example.id
+  name         = "blocked-%s"
+  description  = "Blocked addresses"
+  type         = "ip"
+  entries      = ["10.0.0.2", "10.0.0.1"]
+}
+
+resource "fastly_ngwaf_workspace_signal" "example" {
+  workspace_id = fastly_ngwaf_workspace.example.id
+  name         = "admin-%s"
+  description  = "Admin access"
+}
+
+resource "fastly_ngwaf_workspace_rule" "example" {
+  workspace_id    = fastly_ngwaf_workspace.example.id
+  type            = "request"
+  description     = "Flag admin access"
+  enabled         = true
+  request_logging = "sampled"
+  expression      = "path ~ \"^/admin\" and method == \"POST\""
+
+  action {
+    type   = "add_signal"
+    signal = fastly_ngwaf_workspace_signal.example.reference_id
+  }
+}
+
+data "fastly_ngwaf_workspace_snapshot" "test" {
+  workspace_id = fastly_ngwaf_workspace.example.id
+
+  depends_on = [fastly_ngwaf_workspace_rule.example]
+}
+`, testAccNGWAFWorkspaceConfig(fmt.Sprintf("tf_%s", h)), h, h)
+}
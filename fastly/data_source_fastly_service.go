@@ -0,0 +1,440 @@
+package fastly
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	gofastly "github.com/fastly/go-fastly/v17/fastly"
+)
+
+// dataSourceServiceBlocks maps the list attributes of the fastly_service data
+// source to the service resource blocks they are read from, and the fields of
+// each block they include.
+var dataSourceServiceBlocks = []struct {
+	attribute string
+	key       string
+	fields    []string
+}{
+	{attribute: "acls", key: "acl", fields: []string{"acl_id", "name"}},
+	{attribute: "backends", key: "backend", fields: []string{"address", "name", "override_host", "port", "shield"}},
+	{attribute: "dictionaries", key: "dictionary", fields: []string{"dictionary_id", "name", "write_only"}},
+	{attribute: "domains", key: "domain", fields: []string{"comment", "name"}},
+	{attribute: "dynamic_snippets", key: "dynamicsnippet", fields: []string{"name", "priority", "snippet_id", "type"}},
+	{attribute: "snippets", key: "snippet", fields: []string{"name", "priority", "type"}},
+}
+
+func dataSourceFastlyService() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceFastlyServiceRead,
+		Schema: map[string]*schema.Schema{
+			"acls": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The ACLs of the service version, sorted by name.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"acl_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The ID of the ACL.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the ACL.",
+						},
+					},
+				},
+			},
+			"active_version": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The currently active version of the service. `0` if no version is active.",
+			},
+			"backends": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The backends of the service version, sorted by name.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"address": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "An IPv4, hostname, or IPv6 address for the backend.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the backend.",
+						},
+						"override_host": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The hostname to override the Host header.",
+						},
+						"port": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The port number on which the backend responds.",
+						},
+						"shield": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The POP of the shield designated to reduce inbound load.",
+						},
+					},
+				},
+			},
+			"comment": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "A freeform descriptive note.",
+			},
+			"dictionaries": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The dictionaries of the service version, sorted by name.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"dictionary_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The ID of the dictionary.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the dictionary.",
+						},
+						"write_only": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the dictionary's items are hidden from the API and UI.",
+						},
+					},
+				},
+			},
+			"domains": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The domains of the service version, sorted by name.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"comment": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "An optional comment about the domain.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The domain name.",
+						},
+					},
+				},
+			},
+			"dynamic_snippets": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The dynamic VCL snippets of the service version, sorted by name.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the dynamic snippet.",
+						},
+						"priority": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The priority of the dynamic snippet.",
+						},
+						"snippet_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The ID of the dynamic snippet, e.g. for use with `fastly_service_dynamic_snippet_content`.",
+						},
+						"type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The location in generated VCL where the snippet is placed.",
+						},
+					},
+				},
+			},
+			"id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "Alphanumeric string identifying the service. Exactly one of `id` or `name` must be set.",
+				ExactlyOneOf: []string{"id", "name"},
+			},
+			"logging": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The logging endpoints of the service version, sorted by type and name.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the logging endpoint.",
+						},
+						"type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the service block that configures the logging endpoint, e.g. `logging_s3`.",
+						},
+					},
+				},
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "The name of the service. Exactly one of `id` or `name` must be set.",
+				ExactlyOneOf: []string{"id", "name"},
+			},
+			"snippets": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The VCL snippets of the service version, sorted by name.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the snippet.",
+						},
+						"priority": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The priority of the snippet.",
+						},
+						"type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The location in generated VCL where the snippet is placed.",
+						},
+					},
+				},
+			},
+			"type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The type of the service. One of `vcl`, `wasm`.",
+			},
+			"version": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Computed:    true,
+				Description: "The service version to read. Defaults to the active version, or the latest version if no version is active.",
+			},
+		},
+	}
+}
+
+func dataSourceFastlyServiceRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	serviceID := d.Get("id").(string)
+	if serviceID == "" {
+		var err error
+		if serviceID, err = lookupServiceIDByName(ctx, conn, d.Get("name").(string)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	log.Printf("[DEBUG] Reading service (%s)", serviceID)
+
+	s, err := conn.GetServiceDetails(gofastly.NewContextForResourceID(ctx, serviceID), &gofastly.GetServiceDetailsInput{
+		ServiceID: serviceID,
+	})
+	if err != nil {
+		return diag.Errorf("error fetching service (%s): %s", serviceID, err)
+	}
+	if s.DeletedAt != nil {
+		return diag.Errorf("service (%s) has been deleted", serviceID)
+	}
+
+	var activeVersion, latestVersion int
+	if s.ActiveVersion != nil {
+		activeVersion = gofastly.ToValue(s.ActiveVersion.Number)
+	}
+	if s.Version != nil {
+		latestVersion = gofastly.ToValue(s.Version.Number)
+	}
+
+	version := d.Get("version").(int)
+	if version == 0 {
+		version = activeVersion
+	}
+	if version == 0 {
+		version = latestVersion
+	}
+	if version == 0 {
+		return diag.Errorf("service (%s) has no versions", serviceID)
+	}
+
+	// The service resource's attribute handlers read each block, so the data
+	// source reports blocks exactly as the resource would after an import.
+	serviceType := gofastly.ToValue(s.Type)
+	var resource *schema.Resource
+	var serviceDef ServiceDefinition
+	switch serviceType {
+	case ServiceTypeVCL:
+		resource, serviceDef = resourceServiceVCL(), vclService
+	case ServiceTypeCompute:
+		resource, serviceDef = resourceServiceCompute(), computeService
+	default:
+		return diag.Errorf("service (%s) has unsupported type %q", serviceID, serviceType)
+	}
+
+	blocks, err := readServiceBlocks(ctx, conn, resource, serviceDef, serviceID, version)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(serviceID)
+
+	attrs := map[string]any{
+		"active_version": activeVersion,
+		"comment":        gofastly.ToValue(s.Comment),
+		"name":           gofastly.ToValue(s.Name),
+		"type":           serviceType,
+		"version":        version,
+	}
+	for _, b := range dataSourceServiceBlocks {
+		attrs[b.attribute] = summarizeServiceBlocks(blocks.Get(b.key), b.fields)
+	}
+
+	var logging []map[string]any
+	for _, a := range serviceDef.GetAttributeHandler() {
+		key := serviceAttributeKey(a)
+		if !strings.HasPrefix(key, "logging_") {
+			continue
+		}
+		for _, l := range summarizeServiceBlocks(blocks.Get(key), []string{"name"}) {
+			l["type"] = key
+			logging = append(logging, l)
+		}
+	}
+	sort.SliceStable(logging, func(i, j int) bool {
+		return logging[i]["type"].(string) < logging[j]["type"].(string)
+	})
+	attrs["logging"] = logging
+
+	for k, v := range attrs {
+		if err := d.Set(k, v); err != nil {
+			return diag.Errorf("error setting %s: %s", k, err)
+		}
+	}
+
+	return nil
+}
+
+// lookupServiceIDByName returns the ID of the service with the given name.
+func lookupServiceIDByName(ctx context.Context, conn *gofastly.Client, name string) (string, error) {
+	services, err := conn.ListServices(ctx, &gofastly.ListServicesInput{})
+	if err != nil {
+		return "", fmt.Errorf("error fetching services: %w", err)
+	}
+
+	var ids []string
+	for _, s := range services {
+		if gofastly.ToValue(s.Name) == name {
+			ids = append(ids, gofastly.ToValue(s.ServiceID))
+		}
+	}
+
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("no service named %q found", name)
+	case 1:
+		return ids[0], nil
+	default:
+		sort.Strings(ids)
+		return "", fmt.Errorf("%d services named %q found (%s), set id instead", len(ids), name, strings.Join(ids, ", "))
+	}
+}
+
+// readServiceBlocks reads the domains, backends, dictionaries, ACLs, snippets
+// and logging endpoints of a service version using the attribute handlers of
+// the service resource. The blocks are read into a ResourceData that is never
+// persisted, so only the handlers this data source needs are called.
+func readServiceBlocks(ctx context.Context, conn *gofastly.Client, resource *schema.Resource, serviceDef ServiceDefinition, serviceID string, version int) (*schema.ResourceData, error) {
+	keys := map[string]bool{}
+	for _, b := range dataSourceServiceBlocks {
+		keys[b.key] = true
+	}
+
+	blocks := resource.Data(nil)
+	blocks.SetId(serviceID)
+	// Attribute handlers only read blocks that are already in state, unless
+	// the service is being imported.
+	if err := blocks.Set("imported", true); err != nil {
+		return nil, err
+	}
+
+	s := &gofastly.ServiceDetail{
+		ActiveVersion: &gofastly.Version{Number: gofastly.ToPointer(version)},
+	}
+	for _, a := range serviceDef.GetAttributeHandler() {
+		key := serviceAttributeKey(a)
+		if !keys[key] && !strings.HasPrefix(key, "logging_") {
+			continue
+		}
+		if err := a.Read(ctx, blocks, s, conn); err != nil {
+			return nil, err
+		}
+	}
+
+	return blocks, nil
+}
+
+// serviceAttributeKey returns the name of the block managed by an attribute
+// handler, or an empty string for handlers that do not manage a set of
+// blocks.
+func serviceAttributeKey(a ServiceAttributeDefinition) string {
+	if h, ok := a.(*blockSetAttributeHandler); ok {
+		return h.handler.Key()
+	}
+	return ""
+}
+
+// summarizeServiceBlocks returns the given fields of a set of service blocks,
+// sorted by name.
+func summarizeServiceBlocks(v any, fields []string) []map[string]any {
+	set, ok := v.(*schema.Set)
+	if !ok {
+		return nil
+	}
+
+	result := make([]map[string]any, 0, set.Len())
+	for _, raw := range set.List() {
+		block, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		summary := map[string]any{}
+		for _, f := range fields {
+			if v, ok := block[f]; ok {
+				summary[f] = v
+			}
+		}
+		result = append(result, summary)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		ni, _ := result[i]["name"].(string)
+		nj, _ := result[j]["name"].(string)
+		return ni < nj
+	})
+	return result
+}
@@ -0,0 +1,114 @@
+package fastly
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestSummarizeServiceBlocks(t *testing.T) {
+	hash := func(v any) int {
+		return schema.HashString(v.(map[string]any)["name"])
+	}
+	set := schema.NewSet(hash, []any{
+		map[string]any{"name": "origin-b", "address": "b.example.com", "port": 443},
+		map[string]any{"name": "origin-a", "address": "a.example.com", "port": 80},
+	})
+
+	want := []map[string]any{
+		{"name": "origin-a", "address": "a.example.com"},
+		{"name": "origin-b", "address": "b.example.com"},
+	}
+	if diff := cmp.Diff(want, summarizeServiceBlocks(set, []string{"address", "name", "shield"})); diff != "" {
+		t.Errorf("unexpected summary (-want +got):\n%s", diff)
+	}
+
+	if got := summarizeServiceBlocks(nil, []string{"name"}); got != nil {
+		t.Errorf("expected no blocks for a missing attribute, got %v", got)
+	}
+}
+
+func TestAccFastlyDataSourceService_Config(t *testing.T) {
+	name := acctest.RandomWithPrefix(testResourcePrefix)
+	domain := fmt.Sprintf("fastly-test.%s.com", name)
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProviderFactories: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFastlyDataSourceServiceConfig(name, domain),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.fastly_service.by_name", "id", "fastly_service_vcl.example", "id"),
+					resource.TestCheckResourceAttrPair("data.fastly_service.by_id", "version", "fastly_service_vcl.example", "active_version"),
+					resource.TestCheckResourceAttr("data.fastly_service.by_id", "type", "vcl"),
+					resource.TestCheckResourceAttr("data.fastly_service.by_id", "domains.#", "1"),
+					resource.TestCheckResourceAttr("data.fastly_service.by_id", "domains.0.name", domain),
+					resource.TestCheckResourceAttr("data.fastly_service.by_id", "backends.0.name", "origin"),
+					resource.TestCheckResourceAttr("data.fastly_service.by_id", "backends.0.address", "httpbin.org"),
+					resource.TestCheckResourceAttr("data.fastly_service.by_id", "dictionaries.0.name", "settings"),
+					resource.TestCheckResourceAttrSet("data.fastly_service.by_id", "dictionaries.0.dictionary_id"),
+					resource.TestCheckResourceAttr("data.fastly_service.by_id", "acls.0.name", "allowlist"),
+					resource.TestCheckResourceAttrSet("data.fastly_service.by_id", "acls.0.acl_id"),
+					resource.TestCheckResourceAttr("data.fastly_service.by_id", "dynamic_snippets.0.name", "redirects"),
+					resource.TestCheckResourceAttr("data.fastly_service.by_id", "logging.0.type", "logging_syslog"),
+					resource.TestCheckResourceAttr("data.fastly_service.by_id", "logging.0.name", "syslog"),
+				),
+			},
+		},
+	})
+}
+
+func testAccFastlyDataSourceServiceConfig(name, domain string) string {
+	return fmt.Sprintf(`
+resource "fastly_service_vcl" "example" {
+  name = "%s"
+
+  domain {
+    name = "%s"
+  }
+
+  backend {
+    address = "httpbin.org"
+    name    = "origin"
+  }
+
+  dictionary {
+    name = "settings"
+  }
+
+  acl {
+    name = "allowlist"
+  }
+
+  dynamicsnippet {
+    name     = "redirects"
+    type     = "recv"
+    priority = 100
+  }
+
+  logging_syslog {
+    name    = "syslog"
+    address = "syslog.example.com"
+  }
+
+  force_destroy = true
+}
+
+data "fastly_service" "by_id" {
+  id = fastly_service_vcl.example.id
+}
+
+data "fastly_service" "by_name" {
+  name = fastly_service_vcl.example.name
+
+  depends_on = [fastly_service_vcl.example]
+}
+`, name, domain)
+}
@@ -57,6 +57,16 @@ func dataSourceFastlyTLSCertificate() *schema.Resource {
 				Computed:      true,
 				ConflictsWith: []string{"id"},
 			},
+			"not_after": {
+				Type:        schema.TypeString,
+				Description: "Timestamp (RFC 3339) after which the certificate is no longer valid",
+				Computed:    true,
+			},
+			"not_before": {
+				Type:        schema.TypeString,
+				Description: "Timestamp (RFC 3339) before which the certificate is not yet valid",
+				Computed:    true,
+			},
 			"replace": {
 				Type:        schema.TypeBool,
 				Description: "A recommendation from Fastly indicating the key associated with this certificate is in need of rotation",
@@ -215,6 +225,12 @@ func dataSourceFastlyTLSCertificateSetAttributes(certificate *fastly.CustomTLSCe
 	if err := d.Set("issuer", certificate.Issuer); err != nil {
 		return err
 	}
+	if err := d.Set("not_before", certificate.NotBefore.UTC().Format(time.RFC3339)); err != nil {
+		return err
+	}
+	if err := d.Set("not_after", certificate.NotAfter.UTC().Format(time.RFC3339)); err != nil {
+		return err
+	}
 	if err := d.Set("replace", certificate.Replace); err != nil {
 		return err
 	}
@@ -46,6 +46,24 @@ func dataSourceFastlyKVStoresRead(ctx context.Context, d *schema.ResourceData, m
 
 	log.Printf("[DEBUG] Reading KV Stores")
 
+	stores, err := listKVStores(ctx, conn)
+	if err != nil {
+		return diag.Errorf("error fetching KV Stores: %s", err)
+	}
+
+	hashBase, _ := json.Marshal(stores)
+	hashString := strconv.Itoa(hashcode.String(string(hashBase)))
+	d.SetId(hashString)
+
+	if err := d.Set("stores", flattenDataSourceKVStores(stores)); err != nil {
+		return diag.Errorf("error setting stores: %s", err)
+	}
+
+	return nil
+}
+
+// listKVStores returns all KV Stores, following the pagination cursor.
+func listKVStores(ctx context.Context, conn *gofastly.Client) ([]gofastly.KVStore, error) {
 	var (
 		cursor string
 		stores []gofastly.KVStore
@@ -56,28 +74,21 @@ func dataSourceFastlyKVStoresRead(ctx context.Context, d *schema.ResourceData, m
 			Cursor: cursor,
 		})
 		if err != nil {
-			return diag.Errorf("error fetching KV Stores: %s", err)
+			return nil, err
 		}
-
-		if remoteState != nil {
-			stores = append(stores, remoteState.Data...)
-			c, ok := remoteState.Meta["next_cursor"]
-			if !ok || c == "" || c == cursor {
-				break
-			}
-			cursor = c
+		if remoteState == nil {
+			break
 		}
-	}
 
-	hashBase, _ := json.Marshal(stores)
-	hashString := strconv.Itoa(hashcode.String(string(hashBase)))
-	d.SetId(hashString)
-
-	if err := d.Set("stores", flattenDataSourceKVStores(stores)); err != nil {
-		return diag.Errorf("error setting stores: %s", err)
+		stores = append(stores, remoteState.Data...)
+		c, ok := remoteState.Meta["next_cursor"]
+		if !ok || c == "" || c == cursor {
+			break
+		}
+		cursor = c
 	}
 
-	return nil
+	return stores, nil
 }
 
 // flattenDataSourceKVStores models data into format suitable for saving to
@@ -46,6 +46,24 @@ func dataSourceFastlySecretStoresRead(ctx context.Context, d *schema.ResourceDat
 
 	log.Printf("[DEBUG] Reading Secrets Stores")
 
+	stores, err := listSecretStores(ctx, conn)
+	if err != nil {
+		return diag.Errorf("error fetching Secrets Stores: %s", err)
+	}
+
+	hashBase, _ := json.Marshal(stores)
+	hashString := strconv.Itoa(hashcode.String(string(hashBase)))
+	d.SetId(hashString)
+
+	if err := d.Set("stores", flattenDataSourceSecretStores(stores)); err != nil {
+		return diag.Errorf("error setting stores: %s", err)
+	}
+
+	return nil
+}
+
+// listSecretStores returns all Secret Stores, following the pagination cursor.
+func listSecretStores(ctx context.Context, conn *gofastly.Client) ([]gofastly.SecretStore, error) {
 	var (
 		cursor string
 		stores []gofastly.SecretStore
@@ -56,28 +74,21 @@ func dataSourceFastlySecretStoresRead(ctx context.Context, d *schema.ResourceDat
 			Cursor: cursor,
 		})
 		if err != nil {
-			return diag.Errorf("error fetching Secrets Stores: %s", err)
+			return nil, err
 		}
-
-		if remoteState != nil {
-			stores = append(stores, remoteState.Data...)
-			c := remoteState.Meta.NextCursor
-			if c == "" || c == cursor {
-				break
-			}
-			cursor = c
+		if remoteState == nil {
+			break
 		}
-	}
 
-	hashBase, _ := json.Marshal(stores)
-	hashString := strconv.Itoa(hashcode.String(string(hashBase)))
-	d.SetId(hashString)
-
-	if err := d.Set("stores", flattenDataSourceSecretStores(stores)); err != nil {
-		return diag.Errorf("error setting stores: %s", err)
+		stores = append(stores, remoteState.Data...)
+		c := remoteState.Meta.NextCursor
+		if c == "" || c == cursor {
+			break
+		}
+		cursor = c
 	}
 
-	return nil
+	return stores, nil
 }
 
 // flattenDataSourceSecretStores models data into format suitable for saving to
@@ -3,21 +3,43 @@ package fastly
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
 	gofastly "github.com/fastly/go-fastly/v17/fastly"
 
 	"github.com/fastly/terraform-provider-fastly/fastly/hashcode"
 )
 
+const (
+	// servicesPageSize is the number of services requested per page.
+	servicesPageSize = 100
+
+	// servicesDomainLookupConcurrency bounds the number of concurrent
+	// requests made to look up domains when with_domains is set.
+	servicesDomainLookupConcurrency = 8
+)
+
 func dataSourceFastlyServices() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceFastlyServicesRead,
 		Schema: map[string]*schema.Schema{
+			"comment_contains": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only include services whose comment contains this string.",
+			},
 			"details": {
 				Type:        schema.TypeSet,
 				Computed:    true,
@@ -39,6 +61,14 @@ func dataSourceFastlyServices() *schema.Resource {
 							Computed:    true,
 							Description: "Alphanumeric string identifying the customer.",
 						},
+						"domains": {
+							Type:        schema.TypeSet,
+							Computed:    true,
+							Description: "The domains of the currently activated version. Only set when `with_domains` is `true`.",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
 						"id": {
 							Type:        schema.TypeString,
 							Computed:    true,
@@ -75,6 +105,30 @@ func dataSourceFastlyServices() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
+			"name_regex": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "Only include services whose name matches this regular expression.",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsValidRegExp),
+			},
+			"type": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "Only include services of this type. One of `vcl`, `wasm`.",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{ServiceTypeVCL, ServiceTypeCompute}, false)),
+			},
+			"updated_since": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "Only include services updated at or after this time, in RFC 3339 format, e.g. `2025-01-31T00:00:00Z`.",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsRFC3339Time),
+			},
+			"with_domains": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Set to `true` to look up the domains of each service's currently activated version, which requires one API request per service. Default `false`.",
+			},
 		},
 	}
 }
@@ -82,18 +136,49 @@ func dataSourceFastlyServices() *schema.Resource {
 func dataSourceFastlyServicesRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	conn := meta.(*APIClient).conn
 
+	filter, err := expandServicesFilter(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
 	log.Printf("[DEBUG] Reading services")
 
-	remoteState, err := conn.ListServices(ctx, &gofastly.ListServicesInput{})
-	if err != nil {
-		return diag.Errorf("error fetching services: %s", err)
+	// Services are filtered page by page, so only matching services are kept
+	// in memory.
+	var remoteState []*gofastly.Service
+	paginator := conn.GetServices(ctx, &gofastly.GetServicesInput{
+		PerPage: gofastly.ToPointer(servicesPageSize),
+	})
+	for paginator.HasNext() {
+		services, err := paginator.GetNext()
+		if err != nil {
+			return diag.Errorf("error fetching services: %s", err)
+		}
+		for _, s := range services {
+			if filter.match(s) {
+				remoteState = append(remoteState, s)
+			}
+		}
+	}
+
+	// Sort by ID so the data source ID does not depend on the order the API
+	// returns services in.
+	sort.Slice(remoteState, func(i, j int) bool {
+		return gofastly.ToValue(remoteState[i].ServiceID) < gofastly.ToValue(remoteState[j].ServiceID)
+	})
+
+	var domains map[string][]string
+	if d.Get("with_domains").(bool) {
+		if domains, err = listActiveServiceDomains(ctx, conn, remoteState); err != nil {
+			return diag.FromErr(err)
+		}
 	}
 
 	hashBase, _ := json.Marshal(remoteState)
 	hashString := strconv.Itoa(hashcode.String(string(hashBase)))
 	d.SetId(hashString)
 
-	if err := d.Set("details", flattenServiceDetails(remoteState)); err != nil {
+	if err := d.Set("details", flattenServiceDetails(remoteState, domains)); err != nil {
 		return diag.Errorf("error setting services: %s", err)
 	}
 
@@ -104,6 +189,106 @@ func dataSourceFastlyServicesRead(ctx context.Context, d *schema.ResourceData, m
 	return nil
 }
 
+// servicesFilter holds the filters of the fastly_services data source.
+type servicesFilter struct {
+	commentContains string
+	nameRegex       *regexp.Regexp
+	serviceType     string
+	updatedSince    *time.Time
+}
+
+// expandServicesFilter expands the filter arguments of the fastly_services
+// data source.
+func expandServicesFilter(d *schema.ResourceData) (servicesFilter, error) {
+	f := servicesFilter{
+		commentContains: d.Get("comment_contains").(string),
+		serviceType:     d.Get("type").(string),
+	}
+	if v := d.Get("name_regex").(string); v != "" {
+		re, err := regexp.Compile(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid name_regex %q: %w", v, err)
+		}
+		f.nameRegex = re
+	}
+	if v := d.Get("updated_since").(string); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return f, fmt.Errorf("invalid updated_since %q: %w", v, err)
+		}
+		f.updatedSince = &t
+	}
+	return f, nil
+}
+
+// match reports whether a service matches all of the filters.
+func (f servicesFilter) match(s *gofastly.Service) bool {
+	if f.commentContains != "" && !strings.Contains(gofastly.ToValue(s.Comment), f.commentContains) {
+		return false
+	}
+	if f.nameRegex != nil && !f.nameRegex.MatchString(gofastly.ToValue(s.Name)) {
+		return false
+	}
+	if f.serviceType != "" && gofastly.ToValue(s.Type) != f.serviceType {
+		return false
+	}
+	if f.updatedSince != nil && (s.UpdatedAt == nil || s.UpdatedAt.Before(*f.updatedSince)) {
+		return false
+	}
+	return true
+}
+
+// listActiveServiceDomains returns the domain names of the active version of
+// each service, keyed by service ID. Services without an active version are
+// omitted.
+func listActiveServiceDomains(ctx context.Context, conn *gofastly.Client, services []*gofastly.Service) (map[string][]string, error) {
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs []error
+	)
+	result := map[string][]string{}
+	sem := make(chan struct{}, servicesDomainLookupConcurrency)
+
+	for _, s := range services {
+		serviceID, version := gofastly.ToValue(s.ServiceID), gofastly.ToValue(s.ActiveVersion)
+		if version == 0 {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			remoteState, err := conn.ListDomains(gofastly.NewContextForResourceID(ctx, serviceID), &gofastly.ListDomainsInput{
+				ServiceID:      serviceID,
+				ServiceVersion: version,
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("error looking up Domains for (%s), version (%v): %w", serviceID, version, err))
+				return
+			}
+			names := make([]string, 0, len(remoteState))
+			for _, domain := range remoteState {
+				names = append(names, gofastly.ToValue(domain.Name))
+			}
+			sort.Strings(names)
+			result[serviceID] = names
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // flattenServiceIDs models data into format suitable for saving to Terraform state.
 func flattenServiceIDs(remoteState []*gofastly.Service) []string {
 	result := make([]string, len(remoteState))
@@ -116,7 +301,8 @@ func flattenServiceIDs(remoteState []*gofastly.Service) []string {
 }
 
 // flattenServiceDetails models data into format suitable for saving to Terraform state.
-func flattenServiceDetails(remoteState []*gofastly.Service) []map[string]any {
+// The domains of each service are only included if domains is not nil.
+func flattenServiceDetails(remoteState []*gofastly.Service, domains map[string][]string) []map[string]any {
 	result := make([]map[string]any, len(remoteState))
 	if len(remoteState) == 0 {
 		return result
@@ -148,6 +334,9 @@ func flattenServiceDetails(remoteState []*gofastly.Service) []map[string]any {
 		if resource.ActiveVersion != nil {
 			result[i]["version"] = *resource.ActiveVersion
 		}
+		if domains != nil {
+			result[i]["domains"] = domains[gofastly.ToValue(resource.ServiceID)]
+		}
 	}
 
 	return result
@@ -4,11 +4,49 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+
+	gofastly "github.com/fastly/go-fastly/v17/fastly"
 )
 
+func TestServicesFilterMatch(t *testing.T) {
+	updatedAt := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	service := &gofastly.Service{
+		Comment:   gofastly.ToPointer("owned by team-edge"),
+		Name:      gofastly.ToPointer("prod-www"),
+		Type:      gofastly.ToPointer(ServiceTypeVCL),
+		UpdatedAt: &updatedAt,
+	}
+	before := updatedAt.Add(-time.Hour)
+	after := updatedAt.Add(time.Hour)
+
+	for _, tc := range []struct {
+		name   string
+		filter servicesFilter
+		want   bool
+	}{
+		{name: "no filters", want: true},
+		{name: "comment matches", filter: servicesFilter{commentContains: "team-edge"}, want: true},
+		{name: "comment does not match", filter: servicesFilter{commentContains: "team-api"}},
+		{name: "name matches", filter: servicesFilter{nameRegex: regexp.MustCompile("^prod-")}, want: true},
+		{name: "name does not match", filter: servicesFilter{nameRegex: regexp.MustCompile("^staging-")}},
+		{name: "type matches", filter: servicesFilter{serviceType: ServiceTypeVCL}, want: true},
+		{name: "type does not match", filter: servicesFilter{serviceType: ServiceTypeCompute}},
+		{name: "updated since", filter: servicesFilter{updatedSince: &before}, want: true},
+		{name: "updated at", filter: servicesFilter{updatedSince: &updatedAt}, want: true},
+		{name: "not updated since", filter: servicesFilter{updatedSince: &after}},
+		{name: "all match", filter: servicesFilter{commentContains: "edge", nameRegex: regexp.MustCompile("www"), serviceType: ServiceTypeVCL, updatedSince: &before}, want: true},
+	} {
+		if got := tc.filter.match(service); got != tc.want {
+			t.Errorf("%s: expected match to be %t, got %t", tc.name, tc.want, got)
+		}
+	}
+}
+
 func TestAccFastlyDataSourceServices_Config(t *testing.T) {
 	resourceName := "data.fastly_services.some"
 	serviceName := "fastly_service_vcl.example_service_for_data_sources"
@@ -28,6 +66,12 @@ func TestAccFastlyDataSourceServices_Config(t *testing.T) {
 						"comment": "example_comment",
 						"type":    "vcl",
 					}),
+					resource.TestCheckTypeSetElemAttrPair("data.fastly_services.filtered", "ids.*", serviceName, "id"),
+					resource.TestCheckResourceAttr("data.fastly_services.filtered", "details.#", "1"),
+					resource.TestCheckTypeSetElemNestedAttrs("data.fastly_services.filtered", "details.*", map[string]string{
+						"name":      "example_service_for_data_sources",
+						"domains.#": "1",
+					}),
 				),
 			},
 		},
@@ -50,6 +94,15 @@ resource "fastly_service_vcl" "example_service_for_data_sources" {
 data "fastly_services" "some" {
 	depends_on = [ fastly_service_vcl.example_service_for_data_sources ]
 }
+
+data "fastly_services" "filtered" {
+	name_regex       = "^example_service_for_data_sources$"
+	type             = "vcl"
+	comment_contains = "example"
+	with_domains     = true
+
+	depends_on = [ fastly_service_vcl.example_service_for_data_sources ]
+}
 `
 
 	b := make([]byte, 16)
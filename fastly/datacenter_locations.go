@@ -0,0 +1,262 @@
+package fastly
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// location is a point on the Earth's surface in decimal degrees.
+type location struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// earthRadiusKm is the mean radius of the Earth.
+const earthRadiusKm = 6371.0
+
+// distanceKm returns the great-circle distance between two locations using
+// the haversine formula.
+func (l location) distanceKm(o location) float64 {
+	lat1, lat2 := l.Latitude*math.Pi/180, o.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (o.Longitude - l.Longitude) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// countryLocations maps ISO 3166-1 alpha-2 country codes to a representative
+// location, usually the country's largest population centre, which is a
+// better proxy for where traffic comes from than its geographic centre.
+var countryLocations = map[string]location{
+	"AE": {25.20, 55.27},
+	"AR": {-34.60, -58.38},
+	"AT": {48.21, 16.37},
+	"AU": {-33.87, 151.21},
+	"BD": {23.81, 90.41},
+	"BE": {50.85, 4.35},
+	"BG": {42.70, 23.32},
+	"BH": {26.07, 50.56},
+	"BR": {-23.55, -46.63},
+	"CA": {43.65, -79.38},
+	"CH": {47.37, 8.54},
+	"CL": {-33.45, -70.67},
+	"CN": {31.23, 121.47},
+	"CO": {4.71, -74.07},
+	"CZ": {50.08, 14.44},
+	"DE": {50.11, 8.68},
+	"DK": {55.68, 12.57},
+	"EE": {59.44, 24.75},
+	"EG": {30.04, 31.24},
+	"ES": {40.42, -3.70},
+	"FI": {60.17, 24.94},
+	"FR": {48.86, 2.35},
+	"GB": {51.51, -0.13},
+	"GH": {5.60, -0.19},
+	"GR": {37.98, 23.73},
+	"HK": {22.32, 114.17},
+	"HR": {45.81, 15.98},
+	"HU": {47.50, 19.04},
+	"ID": {-6.21, 106.85},
+	"IE": {53.35, -6.26},
+	"IL": {32.09, 34.78},
+	"IN": {19.08, 72.88},
+	"IS": {64.15, -21.94},
+	"IT": {45.46, 9.19},
+	"JP": {35.68, 139.69},
+	"KE": {-1.29, 36.82},
+	"KR": {37.57, 126.98},
+	"KW": {29.38, 47.99},
+	"LT": {54.69, 25.28},
+	"LU": {49.61, 6.13},
+	"LV": {56.95, 24.11},
+	"MA": {33.57, -7.59},
+	"MX": {19.43, -99.13},
+	"MY": {3.14, 101.69},
+	"NG": {6.52, 3.38},
+	"NL": {52.37, 4.90},
+	"NO": {59.91, 10.75},
+	"NZ": {-36.85, 174.76},
+	"OM": {23.59, 58.41},
+	"PE": {-12.05, -77.04},
+	"PH": {14.60, 120.98},
+	"PK": {24.86, 67.01},
+	"PL": {52.23, 21.01},
+	"PT": {38.72, -9.14},
+	"QA": {25.29, 51.53},
+	"RO": {44.43, 26.10},
+	"RS": {44.79, 20.45},
+	"SA": {24.71, 46.68},
+	"SE": {59.33, 18.07},
+	"SG": {1.35, 103.82},
+	"SI": {46.06, 14.51},
+	"SK": {48.15, 17.11},
+	"TH": {13.76, 100.50},
+	"TR": {41.01, 28.98},
+	"TW": {25.03, 121.57},
+	"UA": {50.45, 30.52},
+	"US": {40.71, -74.01},
+	"VN": {10.82, 106.63},
+	"ZA": {-26.20, 28.05},
+}
+
+// cloudRegionLocations maps `<provider>:<region>` cloud region identifiers
+// to the approximate location of the region's datacenters.
+var cloudRegionLocations = map[string]location{
+	// Amazon Web Services
+	"aws:af-south-1":     {-33.92, 18.42},
+	"aws:ap-east-1":      {22.32, 114.17},
+	"aws:ap-northeast-1": {35.68, 139.69},
+	"aws:ap-northeast-2": {37.57, 126.98},
+	"aws:ap-northeast-3": {34.69, 135.50},
+	"aws:ap-south-1":     {19.08, 72.88},
+	"aws:ap-south-2":     {17.39, 78.49},
+	"aws:ap-southeast-1": {1.35, 103.82},
+	"aws:ap-southeast-2": {-33.87, 151.21},
+	"aws:ap-southeast-3": {-6.21, 106.85},
+	"aws:ap-southeast-4": {-37.81, 144.96},
+	"aws:ap-southeast-5": {3.14, 101.69},
+	"aws:ca-central-1":   {45.50, -73.57},
+	"aws:ca-west-1":      {51.05, -114.07},
+	"aws:eu-central-1":   {50.11, 8.68},
+	"aws:eu-central-2":   {47.37, 8.54},
+	"aws:eu-north-1":     {59.33, 18.07},
+	"aws:eu-south-1":     {45.46, 9.19},
+	"aws:eu-south-2":     {41.65, -0.88},
+	"aws:eu-west-1":      {53.35, -6.26},
+	"aws:eu-west-2":      {51.51, -0.13},
+	"aws:eu-west-3":      {48.86, 2.35},
+	"aws:il-central-1":   {32.09, 34.78},
+	"aws:me-central-1":   {25.20, 55.27},
+	"aws:me-south-1":     {26.07, 50.56},
+	"aws:sa-east-1":      {-23.55, -46.63},
+	"aws:us-east-1":      {39.04, -77.49},
+	"aws:us-east-2":      {39.96, -83.00},
+	"aws:us-west-1":      {37.77, -122.42},
+	"aws:us-west-2":      {45.84, -119.70},
+
+	// Google Cloud
+	"gcp:africa-south1":           {-26.20, 28.05},
+	"gcp:asia-east1":              {24.05, 120.52},
+	"gcp:asia-east2":              {22.32, 114.17},
+	"gcp:asia-northeast1":         {35.68, 139.69},
+	"gcp:asia-northeast2":         {34.69, 135.50},
+	"gcp:asia-northeast3":         {37.57, 126.98},
+	"gcp:asia-south1":             {19.08, 72.88},
+	"gcp:asia-south2":             {28.61, 77.21},
+	"gcp:asia-southeast1":         {1.35, 103.82},
+	"gcp:asia-southeast2":         {-6.21, 106.85},
+	"gcp:australia-southeast1":    {-33.87, 151.21},
+	"gcp:australia-southeast2":    {-37.81, 144.96},
+	"gcp:europe-central2":         {52.23, 21.01},
+	"gcp:europe-north1":           {60.57, 27.20},
+	"gcp:europe-southwest1":       {40.42, -3.70},
+	"gcp:europe-west1":            {50.45, 3.82},
+	"gcp:europe-west2":            {51.51, -0.13},
+	"gcp:europe-west3":            {50.11, 8.68},
+	"gcp:europe-west4":            {53.44, 6.83},
+	"gcp:europe-west6":            {47.37, 8.54},
+	"gcp:europe-west8":            {45.46, 9.19},
+	"gcp:europe-west9":            {48.86, 2.35},
+	"gcp:me-west1":                {32.09, 34.78},
+	"gcp:northamerica-northeast1": {45.50, -73.57},
+	"gcp:northamerica-northeast2": {43.65, -79.38},
+	"gcp:southamerica-east1":      {-23.55, -46.63},
+	"gcp:southamerica-west1":      {-33.45, -70.67},
+	"gcp:us-central1":             {41.26, -95.86},
+	"gcp:us-east1":                {33.20, -80.01},
+	"gcp:us-east4":                {39.04, -77.49},
+	"gcp:us-east5":                {39.96, -83.00},
+	"gcp:us-south1":               {32.78, -96.80},
+	"gcp:us-west1":                {45.59, -121.18},
+	"gcp:us-west2":                {34.05, -118.24},
+	"gcp:us-west3":                {40.76, -111.89},
+	"gcp:us-west4":                {36.17, -115.14},
+
+	// Microsoft Azure
+	"azure:australiaeast":      {-33.87, 151.21},
+	"azure:australiasoutheast": {-37.81, 144.96},
+	"azure:brazilsouth":        {-23.55, -46.63},
+	"azure:canadacentral":      {43.65, -79.38},
+	"azure:canadaeast":         {46.81, -71.21},
+	"azure:centralindia":       {18.52, 73.86},
+	"azure:centralus":          {41.59, -93.60},
+	"azure:eastasia":           {22.32, 114.17},
+	"azure:eastus":             {36.67, -78.39},
+	"azure:eastus2":            {36.67, -78.39},
+	"azure:francecentral":      {48.86, 2.35},
+	"azure:germanywestcentral": {50.11, 8.68},
+	"azure:italynorth":         {45.46, 9.19},
+	"azure:japaneast":          {35.68, 139.69},
+	"azure:japanwest":          {34.69, 135.50},
+	"azure:koreacentral":       {37.57, 126.98},
+	"azure:northcentralus":     {41.88, -87.63},
+	"azure:northeurope":        {53.35, -6.26},
+	"azure:norwayeast":         {59.91, 10.75},
+	"azure:polandcentral":      {52.23, 21.01},
+	"azure:southafricanorth":   {-26.20, 28.05},
+	"azure:southcentralus":     {29.42, -98.49},
+	"azure:southeastasia":      {1.35, 103.82},
+	"azure:southindia":         {13.08, 80.27},
+	"azure:swedencentral":      {60.67, 17.14},
+	"azure:switzerlandnorth":   {47.37, 8.54},
+	"azure:uaenorth":           {25.20, 55.27},
+	"azure:uksouth":            {51.51, -0.13},
+	"azure:ukwest":             {51.48, -3.18},
+	"azure:westeurope":         {52.37, 4.90},
+	"azure:westus":             {37.77, -122.42},
+	"azure:westus2":            {47.23, -119.85},
+	"azure:westus3":            {33.45, -112.07},
+}
+
+// cloudProviderAliases maps alternative cloud provider prefixes to the ones
+// used by cloudRegionLocations.
+var cloudProviderAliases = map[string]string{
+	"amazon": "aws",
+	"google": "gcp",
+	"gcloud": "gcp",
+	"az":     "azure",
+}
+
+// lookupCloudRegion resolves a `<provider>:<region>` string such as
+// `aws:us-east-1` to a location.
+func lookupCloudRegion(region string) (location, error) {
+	provider, name, ok := strings.Cut(strings.ToLower(strings.TrimSpace(region)), ":")
+	if !ok || provider == "" || name == "" {
+		return location{}, fmt.Errorf("expected cloud_region to be in the format <provider>:<region>, e.g. aws:us-east-1, got %q", region)
+	}
+	if alias, ok := cloudProviderAliases[provider]; ok {
+		provider = alias
+	}
+
+	key := provider + ":" + name
+	if l, ok := cloudRegionLocations[key]; ok {
+		return l, nil
+	}
+
+	var known []string
+	for k := range cloudRegionLocations {
+		if strings.HasPrefix(k, provider+":") {
+			known = append(known, k)
+		}
+	}
+	if len(known) == 0 {
+		return location{}, fmt.Errorf("unsupported cloud provider %q in cloud_region, expected one of aws, gcp or azure", provider)
+	}
+
+	sort.Strings(known)
+	if suggestions := closestMatches(key, known); len(suggestions) > 0 {
+		return location{}, fmt.Errorf("unknown cloud_region %q, did you mean %s?", region, strings.Join(suggestions, ", "))
+	}
+	return location{}, fmt.Errorf("unknown cloud_region %q", region)
+}
+
+// lookupCountry resolves an ISO 3166-1 alpha-2 country code to a location.
+func lookupCountry(code string) (location, error) {
+	if l, ok := countryLocations[strings.ToUpper(strings.TrimSpace(code))]; ok {
+		return l, nil
+	}
+	return location{}, fmt.Errorf("unsupported country %q, expected an ISO 3166-1 alpha-2 code such as US or GB", code)
+}
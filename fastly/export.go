@@ -0,0 +1,476 @@
+package fastly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/zclconf/go-cty/cty"
+
+	gofastly "github.com/fastly/go-fastly/v17/fastly"
+)
+
+// ExportOptions configures Export.
+type ExportOptions struct {
+	// ServiceIDs lists the services to export.
+	ServiceIDs []string
+	// All exports every service the API token can read, as listed by the
+	// fastly_services data source. ServiceIDs is ignored when set.
+	All bool
+	// Version exports a specific service version rather than the active one.
+	// It may only be used with a single service.
+	Version int
+}
+
+// exportInternalAttributes are top-level service attributes that are managed
+// by the provider itself and must never appear in exported configuration.
+var exportInternalAttributes = map[string]bool{
+	"activate":      true,
+	"force_refresh": true,
+	"imported":      true,
+}
+
+// Export writes HCL `resource` and `import` blocks reproducing the given
+// Fastly services, along with companion fastly_service_dictionary_items,
+// fastly_service_acl_entries and fastly_service_dynamic_snippet_content
+// resources.
+//
+// The service state is populated by the same import and Read code paths
+// used by `terraform import`, so the emitted configuration should plan
+// cleanly against the imported state.
+func Export(ctx context.Context, meta any, w io.Writer, opts ExportOptions) error {
+	conn := meta.(*APIClient).conn
+
+	ids := opts.ServiceIDs
+	if opts.All {
+		services, err := conn.ListServices(ctx, &gofastly.ListServicesInput{})
+		if err != nil {
+			return fmt.Errorf("error fetching services: %w", err)
+		}
+		ids = flattenServiceIDs(services)
+		sort.Strings(ids)
+	}
+	if len(ids) == 0 {
+		return errors.New("no services to export")
+	}
+	if opts.Version != 0 && len(ids) != 1 {
+		return errors.New("a service version can only be exported for a single service")
+	}
+
+	labels := map[string]bool{}
+	var errs []error
+
+	for _, id := range ids {
+		f, err := exportService(ctx, meta, id, opts.Version, labels)
+		if err != nil {
+			log.Printf("[WARN] Error exporting service (%s): %s", id, err)
+			errs = append(errs, fmt.Errorf("error exporting service (%s): %w", id, err))
+			continue
+		}
+		if _, err := w.Write(hclwrite.Format(f.Bytes())); err != nil {
+			return err
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// exportService renders a single service and its companion resources.
+func exportService(ctx context.Context, meta any, id string, version int, labels map[string]bool) (*hclwrite.File, error) {
+	conn := meta.(*APIClient).conn
+
+	s, err := conn.GetServiceDetails(gofastly.NewContextForResourceID(ctx, id), &gofastly.GetServiceDetailsInput{
+		ServiceID: id,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if s.Type == nil {
+		return nil, errors.New("error: service type is nil")
+	}
+
+	var (
+		resourceType string
+		res          *schema.Resource
+	)
+	switch *s.Type {
+	case ServiceTypeVCL:
+		resourceType, res = "fastly_service_vcl", resourceServiceVCL()
+	case ServiceTypeCompute:
+		resourceType, res = "fastly_service_compute", resourceServiceCompute()
+	default:
+		return nil, fmt.Errorf("unsupported service type: %s", *s.Type)
+	}
+
+	importID := id
+	if version != 0 {
+		importID = fmt.Sprintf("%s@%d", id, version)
+	}
+
+	d, err := exportReadResource(ctx, res, importID, meta, func(d *schema.ResourceData) error {
+		// Read the active version unless a specific version was requested,
+		// in which case the importer has already set cloned_version.
+		return d.Set("activate", version == 0)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	label := exportLabel(gofastly.ToValue(s.Name), labels)
+	address := resourceType + "." + label
+
+	f := hclwrite.NewEmptyFile()
+	root := f.Body()
+
+	exportImportBlock(root, resourceType, label, importID)
+	block := root.AppendNewBlock("resource", []string{resourceType, label})
+	exportBody(block.Body(), res.SchemaMap(), exportResourceValues(res.SchemaMap(), d), exportInternalAttributes)
+	if version != 0 {
+		// activate defaults to true, so without this applying the export
+		// would activate the exported version.
+		block.Body().SetAttributeValue("activate", cty.False)
+	}
+
+	companions := []struct {
+		block        string
+		idKey        string
+		resourceType string
+		resource     func() *schema.Resource
+		skip         func(map[string]any) bool
+	}{
+		{
+			block:        "dictionary",
+			idKey:        "dictionary_id",
+			resourceType: "fastly_service_dictionary_items",
+			resource:     resourceServiceDictionaryItems,
+			// The items of a write-only dictionary cannot be read back.
+			skip: func(m map[string]any) bool { b, _ := m["write_only"].(bool); return b },
+		},
+		{
+			block:        "acl",
+			idKey:        "acl_id",
+			resourceType: "fastly_service_acl_entries",
+			resource:     resourceServiceACLEntries,
+		},
+		{
+			block:        "dynamicsnippet",
+			idKey:        "snippet_id",
+			resourceType: "fastly_service_dynamic_snippet_content",
+			resource:     resourceServiceDynamicSnippetContent,
+		},
+	}
+
+	for _, c := range companions {
+		if _, ok := res.SchemaMap()[c.block]; !ok {
+			continue
+		}
+
+		for _, item := range exportSortedBlocks(d.Get(c.block)) {
+			if c.skip != nil && c.skip(item) {
+				continue
+			}
+			name, _ := item["name"].(string)
+			childID, _ := item[c.idKey].(string)
+			if childID == "" {
+				continue
+			}
+
+			companion := c.resource()
+			cd, err := exportReadResource(ctx, companion, id+"/"+childID, meta, nil)
+			if err != nil {
+				return nil, fmt.Errorf("error exporting %s (%s): %w", c.resourceType, name, err)
+			}
+
+			childLabel := exportLabel(label+"_"+name, labels)
+			root.AppendNewline()
+			exportImportBlock(root, c.resourceType, childLabel, id+"/"+childID)
+			child := root.AppendNewBlock("resource", []string{c.resourceType, childLabel})
+			body := child.Body()
+
+			// Reference the service resource rather than hard-coding IDs so the
+			// exported configuration stays correct if the service is recreated.
+			body.SetAttributeTraversal("service_id", hcl.Traversal{
+				hcl.TraverseRoot{Name: resourceType},
+				hcl.TraverseAttr{Name: label},
+				hcl.TraverseAttr{Name: "id"},
+			})
+			body.SetAttributeRaw(c.idKey, exportRawExpression(fmt.Sprintf(
+				"{ for b in %s.%s : b.name => b.%s }[%q]", address, c.block, c.idKey, name,
+			)))
+
+			skip := map[string]bool{"service_id": true, c.idKey: true}
+			exportBody(body, companion.SchemaMap(), exportResourceValues(companion.SchemaMap(), cd), skip)
+		}
+	}
+
+	root.AppendNewline()
+	return f, nil
+}
+
+// exportReadResource builds ResourceData for res by running its importer and
+// Read function against id, exactly as `terraform import` would.
+func exportReadResource(ctx context.Context, res *schema.Resource, id string, meta any, prepare func(*schema.ResourceData) error) (*schema.ResourceData, error) {
+	d := res.Data(nil)
+	d.SetId(id)
+
+	if res.Importer != nil && res.Importer.StateContext != nil {
+		imported, err := res.Importer.StateContext(ctx, d, meta)
+		if err != nil {
+			return nil, err
+		}
+		if len(imported) != 1 {
+			return nil, fmt.Errorf("expected a single imported resource, got %d", len(imported))
+		}
+		d = imported[0]
+	}
+
+	if prepare != nil {
+		if err := prepare(d); err != nil {
+			return nil, err
+		}
+	}
+
+	if diags := res.ReadContext(ctx, d, meta); diags.HasError() {
+		return nil, diagToErr(diags)
+	}
+	if d.Id() == "" {
+		return nil, errors.New("resource not found")
+	}
+
+	return d, nil
+}
+
+// exportResourceValues collects the top-level values of d into a map.
+func exportResourceValues(sm map[string]*schema.Schema, d *schema.ResourceData) map[string]any {
+	values := make(map[string]any, len(sm))
+	for k := range sm {
+		values[k] = d.Get(k)
+	}
+	return values
+}
+
+// exportBody writes the configurable attributes in values to body, followed
+// by any nested blocks. Computed-only attributes, attributes equal to their
+// schema default and empty optional attributes are omitted.
+func exportBody(body *hclwrite.Body, sm map[string]*schema.Schema, values map[string]any, skip map[string]bool) {
+	keys := make([]string, 0, len(sm))
+	for k := range sm {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var blocks []string
+	for _, k := range keys {
+		s := sm[k]
+		if skip[k] || (!s.Optional && !s.Required) {
+			continue
+		}
+		if _, ok := s.Elem.(*schema.Resource); ok {
+			blocks = append(blocks, k)
+			continue
+		}
+
+		v := values[k]
+		if !s.Required && exportIsDefault(s, v) {
+			continue
+		}
+		if s.Sensitive {
+			body.AppendUnstructuredTokens(hclwrite.Tokens{{
+				Type:  hclsyntax.TokenComment,
+				Bytes: fmt.Appendf(nil, "# %s is sensitive and must be set manually\n", k),
+			}})
+			continue
+		}
+
+		if str, ok := v.(string); ok && strings.Contains(strings.TrimSuffix(str, "\n"), "\n") {
+			body.SetAttributeRaw(k, exportHeredoc(str))
+			continue
+		}
+		body.SetAttributeValue(k, exportCtyValue(s, v))
+	}
+
+	for _, k := range blocks {
+		elem := sm[k].Elem.(*schema.Resource)
+		for _, item := range exportSortedBlocks(values[k]) {
+			body.AppendNewline()
+			nested := body.AppendNewBlock(k, nil)
+			exportBody(nested.Body(), elem.SchemaMap(), item, nil)
+		}
+	}
+}
+
+// exportIsDefault reports whether v can be omitted from the configuration.
+func exportIsDefault(s *schema.Schema, v any) bool {
+	if s.Default != nil {
+		return v == s.Default
+	}
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return t == ""
+	case int:
+		return t == 0
+	case float64:
+		return t == 0
+	case bool:
+		return !t
+	case *schema.Set:
+		return t.Len() == 0
+	case []any:
+		return len(t) == 0
+	case map[string]any:
+		return len(t) == 0
+	}
+	return false
+}
+
+// exportSortedBlocks returns the elements of a block list or set. Lists keep
+// their order, while sets are ordered by name (falling back to their
+// rendered form) so output is deterministic.
+func exportSortedBlocks(v any) []map[string]any {
+	var raw []any
+	switch t := v.(type) {
+	case *schema.Set:
+		raw = t.List()
+	case []any:
+		raw = t
+	}
+
+	items := make([]map[string]any, 0, len(raw))
+	for _, r := range raw {
+		if m, ok := r.(map[string]any); ok {
+			items = append(items, m)
+		}
+	}
+	if _, ok := v.(*schema.Set); !ok {
+		return items
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		ni, _ := items[i]["name"].(string)
+		nj, _ := items[j]["name"].(string)
+		if ni != nj {
+			return ni < nj
+		}
+		return fmt.Sprint(items[i]) < fmt.Sprint(items[j])
+	})
+	return items
+}
+
+// exportCtyValue converts a value read from ResourceData into a cty.Value.
+func exportCtyValue(s *schema.Schema, v any) cty.Value {
+	switch s.Type {
+	case schema.TypeBool:
+		b, _ := v.(bool)
+		return cty.BoolVal(b)
+	case schema.TypeInt:
+		i, _ := v.(int)
+		return cty.NumberIntVal(int64(i))
+	case schema.TypeFloat:
+		f, _ := v.(float64)
+		return cty.NumberFloatVal(f)
+	case schema.TypeString:
+		str, _ := v.(string)
+		return cty.StringVal(str)
+	case schema.TypeList, schema.TypeSet:
+		var raw []any
+		if set, ok := v.(*schema.Set); ok {
+			raw = set.List()
+		} else {
+			raw, _ = v.([]any)
+		}
+		elem, ok := s.Elem.(*schema.Schema)
+		if !ok || len(raw) == 0 {
+			return cty.ListValEmpty(cty.String)
+		}
+		vals := make([]cty.Value, 0, len(raw))
+		for _, r := range raw {
+			vals = append(vals, exportCtyValue(elem, r))
+		}
+		// Sets have no order of their own, so they are sorted to make the
+		// output deterministic. Lists keep their order, which may matter.
+		if s.Type == schema.TypeSet {
+			sort.SliceStable(vals, func(i, j int) bool { return vals[i].GoString() < vals[j].GoString() })
+		}
+		return cty.ListVal(vals)
+	case schema.TypeMap:
+		m, _ := v.(map[string]any)
+		if len(m) == 0 {
+			return cty.MapValEmpty(cty.String)
+		}
+		elem, ok := s.Elem.(*schema.Schema)
+		if !ok {
+			elem = &schema.Schema{Type: schema.TypeString}
+		}
+		vals := make(map[string]cty.Value, len(m))
+		for k, r := range m {
+			vals[k] = exportCtyValue(elem, r)
+		}
+		return cty.MapVal(vals)
+	}
+	return cty.NullVal(cty.DynamicPseudoType)
+}
+
+// exportHeredoc renders a multi-line string as a heredoc, escaping template
+// sequences so the content is preserved verbatim.
+func exportHeredoc(s string) hclwrite.Tokens {
+	s = strings.ReplaceAll(s, "${", "$${")
+	s = strings.ReplaceAll(s, "%{", "%%{")
+	if !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+	return hclwrite.Tokens{
+		{Type: hclsyntax.TokenOHeredoc, Bytes: []byte("<<EOT\n")},
+		{Type: hclsyntax.TokenStringLit, Bytes: []byte(s)},
+		{Type: hclsyntax.TokenCHeredoc, Bytes: []byte("EOT")},
+	}
+}
+
+// exportRawExpression returns the tokens of an HCL expression.
+func exportRawExpression(src string) hclwrite.Tokens {
+	f, diags := hclwrite.ParseConfig([]byte("x = "+src+"\n"), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return hclwrite.TokensForValue(cty.StringVal(src))
+	}
+	return f.Body().GetAttribute("x").Expr().BuildTokens(nil)
+}
+
+// exportImportBlock appends an `import` block for the given resource.
+func exportImportBlock(body *hclwrite.Body, resourceType, label, id string) {
+	block := body.AppendNewBlock("import", nil)
+	block.Body().SetAttributeTraversal("to", hcl.Traversal{
+		hcl.TraverseRoot{Name: resourceType},
+		hcl.TraverseAttr{Name: label},
+	})
+	block.Body().SetAttributeValue("id", cty.StringVal(id))
+	body.AppendNewline()
+}
+
+var exportLabelInvalid = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// exportLabel derives a unique, valid Terraform resource name from name.
+func exportLabel(name string, used map[string]bool) string {
+	label := strings.Trim(exportLabelInvalid.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if label == "" {
+		label = "service"
+	}
+	if label[0] >= '0' && label[0] <= '9' {
+		label = "service_" + label
+	}
+
+	unique := label
+	for i := 2; used[unique]; i++ {
+		unique = fmt.Sprintf("%s_%d", label, i)
+	}
+	used[unique] = true
+	return unique
+}
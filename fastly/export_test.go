@@ -0,0 +1,99 @@
+package fastly
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestExportLabel(t *testing.T) {
+	used := map[string]bool{}
+
+	for _, testcase := range []struct {
+		name string
+		want string
+	}{
+		{name: "My Service", want: "my_service"},
+		{name: "my-service", want: "my_service_2"},
+		{name: "1st service", want: "service_1st_service"},
+		{name: "!!!", want: "service"},
+	} {
+		if got := exportLabel(testcase.name, used); got != testcase.want {
+			t.Errorf("exportLabel(%q): got %q, want %q", testcase.name, got, testcase.want)
+		}
+	}
+}
+
+func TestExportBody(t *testing.T) {
+	res := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"activate":       {Type: schema.TypeBool, Optional: true, Default: true},
+			"cloned_version": {Type: schema.TypeInt, Computed: true},
+			"comment":        {Type: schema.TypeString, Optional: true, Default: "Managed by Terraform"},
+			"name":           {Type: schema.TypeString, Required: true},
+			"backend": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name":  {Type: schema.TypeString, Required: true},
+						"port":  {Type: schema.TypeInt, Optional: true, Default: 80},
+						"token": {Type: schema.TypeString, Optional: true, Sensitive: true},
+					},
+				},
+			},
+			"content":   {Type: schema.TypeString, Optional: true},
+			"hostnames": {Type: schema.TypeList, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}},
+			"tags":      {Type: schema.TypeSet, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}},
+		},
+	}
+
+	d := res.Data(nil)
+	d.SetId("abc")
+	for k, v := range map[string]any{
+		"activate":       true,
+		"cloned_version": 3,
+		"comment":        "Managed by Terraform",
+		"name":           "svc",
+		"content":        "if (req.http.x) {\n  set req.http.y = \"${z}\";\n}\n",
+		"backend": []any{
+			map[string]any{"name": "b", "port": 443, "token": "secret"},
+			map[string]any{"name": "a", "port": 80},
+		},
+		"hostnames": []any{"www.example.com", "example.com"},
+		"tags":      []any{"prod", "edge"},
+	} {
+		if err := d.Set(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f := hclwrite.NewEmptyFile()
+	exportBody(f.Body(), res.SchemaMap(), exportResourceValues(res.SchemaMap(), d), exportInternalAttributes)
+	got := string(hclwrite.Format(f.Bytes()))
+
+	want := `content   = <<EOT
+if (req.http.x) {
+  set req.http.y = "$${z}";
+}
+EOT
+hostnames = ["www.example.com", "example.com"]
+name      = "svc"
+tags      = ["edge", "prod"]
+
+backend {
+  name = "a"
+}
+
+backend {
+  name = "b"
+  port = 443
+  # token is sensitive and must be set manually
+}
+`
+	if strings.TrimSpace(got) != strings.TrimSpace(want) {
+		t.Errorf("unexpected HCL:\n%s\nwant:\n%s", got, want)
+	}
+}
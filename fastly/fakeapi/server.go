@@ -0,0 +1,747 @@
+// Package fakeapi provides an in-memory fake of the Fastly API suitable for
+// running the provider's acceptance tests without a real Fastly account.
+//
+// The fake covers the service lifecycle (create, details, update, delete),
+// service versions (get, update, clone, activate, deactivate, validate), the
+// versioned configuration blocks used by fastly_service_vcl and
+// fastly_service_compute, the versionless dictionary item, ACL entry and
+// dynamic snippet endpoints, and a generic JSON store for the NGWAF API.
+//
+// Request bodies for the legacy service endpoints are form encoded by
+// go-fastly and the values are stored as strings. go-fastly decodes responses
+// with weak typing, so numeric and boolean attributes round-trip correctly.
+package fakeapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// APIKey is the token the fake accepts. Any other value in the Fastly-Key
+// header is rejected with a 401, which mirrors the real API closely enough
+// for the provider's no_auth handling to be exercised.
+const APIKey = "fake-fastly-api-key"
+
+// versionedCollections lists the versioned service endpoints that behave as
+// a simple collection of objects keyed by name.
+var versionedCollections = map[string]bool{
+	"acl":              true,
+	"backend":          true,
+	"cache_settings":   true,
+	"condition":        true,
+	"dictionary":       true,
+	"director":         true,
+	"domain":           true,
+	"gzip":             true,
+	"header":           true,
+	"healthcheck":      true,
+	"rate-limiters":    true,
+	"request_settings": true,
+	"response_object":  true,
+	"snippet":          true,
+	"vcl":              true,
+}
+
+// idCollections lists the versioned collections whose objects are assigned
+// an ID that is stable across cloned versions.
+var idCollections = map[string]bool{
+	"acl":           true,
+	"dictionary":    true,
+	"rate-limiters": true,
+	"snippet":       true,
+}
+
+type object map[string]any
+
+type version struct {
+	number      int
+	active      bool
+	locked      bool
+	staging     bool
+	comment     string
+	createdAt   time.Time
+	updatedAt   time.Time
+	collections map[string][]object
+	settings    object
+}
+
+type service struct {
+	id        string
+	name      string
+	comment   string
+	kind      string
+	createdAt time.Time
+	updatedAt time.Time
+	deletedAt *time.Time
+	versions  []*version
+
+	dictionaryItems map[string][]object
+	aclEntries      map[string][]object
+	snippetContent  map[string]object
+}
+
+// Server is an in-memory fake of the Fastly API.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	nextID   int
+	services map[string]*service
+	order    []string
+	ngwaf    map[string][]object
+}
+
+// NewServer starts and returns a new fake Fastly API server. The caller
+// should call Close when finished.
+func NewServer() *Server {
+	s := &Server{
+		services: map[string]*service{},
+		ngwaf:    map[string][]object{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+func (s *Server) newID() string {
+	s.nextID++
+	return fmt.Sprintf("fake%018d", s.nextID)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Fastly-Key") != APIKey {
+		writeError(w, http.StatusUnauthorized, "Provided credentials are missing or invalid")
+		return
+	}
+
+	if err := parseBody(r); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := strings.Trim(r.URL.Path, "/")
+	parts := strings.Split(path, "/")
+
+	switch {
+	case path == "service":
+		s.handleServices(w, r)
+	case parts[0] == "service" && len(parts) >= 2:
+		s.handleService(w, r, parts[1], parts[2:])
+	case parts[0] == "ngwaf":
+		s.handleNGWAF(w, r, path)
+	default:
+		writeError(w, http.StatusNotFound, "Record not found")
+	}
+}
+
+func (s *Server) handleServices(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		var list []object
+		for _, id := range s.order {
+			svc := s.services[id]
+			if svc.deletedAt != nil {
+				continue
+			}
+			list = append(list, svc.summary())
+		}
+		writePage(w, r, list)
+	case http.MethodPost:
+		now := time.Now().UTC()
+		svc := &service{
+			id:              s.newID(),
+			name:            r.PostForm.Get("name"),
+			comment:         r.PostForm.Get("comment"),
+			kind:            r.PostForm.Get("type"),
+			createdAt:       now,
+			updatedAt:       now,
+			dictionaryItems: map[string][]object{},
+			aclEntries:      map[string][]object{},
+			snippetContent:  map[string]object{},
+		}
+		if svc.kind == "" {
+			svc.kind = "vcl"
+		}
+		svc.versions = append(svc.versions, newVersion(1))
+		s.services[svc.id] = svc
+		s.order = append(s.order, svc.id)
+		writeJSON(w, http.StatusOK, svc.summary())
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (s *Server) handleService(w http.ResponseWriter, r *http.Request, id string, rest []string) {
+	svc, ok := s.services[id]
+	if !ok || svc.deletedAt != nil {
+		writeError(w, http.StatusNotFound, "Record not found")
+		return
+	}
+
+	switch {
+	case len(rest) == 0:
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, svc.summary())
+		case http.MethodPut:
+			if v, ok := r.PostForm["name"]; ok {
+				svc.name = v[0]
+			}
+			if v, ok := r.PostForm["comment"]; ok {
+				svc.comment = v[0]
+			}
+			svc.updatedAt = time.Now().UTC()
+			writeJSON(w, http.StatusOK, svc.summary())
+		case http.MethodDelete:
+			if svc.activeVersion() != nil {
+				writeError(w, http.StatusBadRequest, "Cannot delete an active service")
+				return
+			}
+			now := time.Now().UTC()
+			svc.deletedAt = &now
+			writeStatus(w)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	case rest[0] == "details" && len(rest) == 1:
+		writeJSON(w, http.StatusOK, svc.details())
+	case rest[0] == "version":
+		s.handleVersions(w, r, svc, rest[1:])
+	case rest[0] == "dictionary" && len(rest) >= 3 && rest[2] == "items":
+		s.handleBatch(w, r, svc.dictionaryItems, rest[1], "items", "item_key")
+	case rest[0] == "acl" && len(rest) >= 3 && rest[2] == "entries":
+		s.handleBatch(w, r, svc.aclEntries, rest[1], "entries", "id")
+	case rest[0] == "snippet" && len(rest) == 2:
+		s.handleDynamicSnippet(w, r, svc, rest[1])
+	default:
+		writeError(w, http.StatusNotFound, "Record not found")
+	}
+}
+
+func (s *Server) handleVersions(w http.ResponseWriter, r *http.Request, svc *service, rest []string) {
+	if len(rest) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			list := make([]object, 0, len(svc.versions))
+			for _, v := range svc.versions {
+				list = append(list, v.toObject(svc.id))
+			}
+			writeJSON(w, http.StatusOK, list)
+		case http.MethodPost:
+			v := newVersion(len(svc.versions) + 1)
+			svc.versions = append(svc.versions, v)
+			writeJSON(w, http.StatusOK, v.toObject(svc.id))
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+		return
+	}
+
+	n, err := strconv.Atoi(rest[0])
+	if err != nil || n < 1 || n > len(svc.versions) {
+		writeError(w, http.StatusNotFound, "Record not found")
+		return
+	}
+	v := svc.versions[n-1]
+	rest = rest[1:]
+
+	if len(rest) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, v.toObject(svc.id))
+		case http.MethodPut:
+			if c, ok := r.PostForm["comment"]; ok {
+				v.comment = c[0]
+			}
+			v.updatedAt = time.Now().UTC()
+			writeJSON(w, http.StatusOK, v.toObject(svc.id))
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+		return
+	}
+
+	action := strings.Join(rest, "/")
+	switch action {
+	case "clone":
+		clone := v.clone(len(svc.versions) + 1)
+		svc.versions = append(svc.versions, clone)
+		writeJSON(w, http.StatusOK, clone.toObject(svc.id))
+		return
+	case "activate":
+		for _, other := range svc.versions {
+			other.active = false
+		}
+		v.active = true
+		v.locked = true
+		writeJSON(w, http.StatusOK, v.toObject(svc.id))
+		return
+	case "activate/staging":
+		for _, other := range svc.versions {
+			other.staging = false
+		}
+		v.staging = true
+		v.locked = true
+		writeJSON(w, http.StatusOK, v.toObject(svc.id))
+		return
+	case "deactivate":
+		v.active = false
+		writeJSON(w, http.StatusOK, v.toObject(svc.id))
+		return
+	case "deactivate/staging":
+		v.staging = false
+		writeJSON(w, http.StatusOK, v.toObject(svc.id))
+		return
+	case "lock":
+		v.locked = true
+		writeJSON(w, http.StatusOK, v.toObject(svc.id))
+		return
+	case "validate":
+		writeJSON(w, http.StatusOK, object{"status": "ok", "msg": nil, "errors": []string{}, "warnings": []string{}})
+		return
+	case "settings":
+		if r.Method == http.MethodPut {
+			if v.locked {
+				writeError(w, http.StatusConflict, "Version is locked")
+				return
+			}
+			mergeForm(v.settings, r.PostForm)
+		}
+		writeJSON(w, http.StatusOK, v.settings)
+		return
+	}
+
+	s.handleVersionedBlock(w, r, svc, v, rest)
+}
+
+func (s *Server) handleVersionedBlock(w http.ResponseWriter, r *http.Request, svc *service, v *version, rest []string) {
+	collection, name := splitCollection(rest)
+	if collection == "" {
+		writeError(w, http.StatusNotFound, "Record not found")
+		return
+	}
+
+	if r.Method != http.MethodGet && v.locked {
+		writeError(w, http.StatusConflict, "Version is locked")
+		return
+	}
+
+	items := v.collections[collection]
+
+	if name == "" {
+		switch r.Method {
+		case http.MethodGet:
+			if items == nil {
+				items = []object{}
+			}
+			writeJSON(w, http.StatusOK, items)
+		case http.MethodPost:
+			obj := object{}
+			mergeForm(obj, r.PostForm)
+			if idCollections[collection] {
+				obj["id"] = s.newID()
+			}
+			obj["service_id"] = svc.id
+			obj["version"] = v.number
+			stamp(obj, true)
+			v.collections[collection] = append(items, obj)
+			writeJSON(w, http.StatusOK, obj)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+		return
+	}
+
+	idx := findObject(items, name)
+	if idx < 0 {
+		writeError(w, http.StatusNotFound, "Record not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, items[idx])
+	case http.MethodPut:
+		mergeForm(items[idx], r.PostForm)
+		stamp(items[idx], false)
+		writeJSON(w, http.StatusOK, items[idx])
+	case http.MethodDelete:
+		v.collections[collection] = append(items[:idx], items[idx+1:]...)
+		writeStatus(w)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleBatch serves the versionless dictionary item and ACL entry endpoints,
+// including the JSON batch PATCH used by go-fastly.
+func (s *Server) handleBatch(w http.ResponseWriter, r *http.Request, store map[string][]object, parentID, listKey, key string) {
+	items := store[parentID]
+
+	switch r.Method {
+	case http.MethodGet:
+		if items == nil {
+			items = []object{}
+		}
+		writePage(w, r, items)
+	case http.MethodPatch:
+		var batch map[string][]object
+		if err := json.Unmarshal([]byte(r.Form.Get(jsonBodyKey)), &batch); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		for _, op := range batch[listKey] {
+			operation, _ := op["op"].(string)
+			delete(op, "op")
+
+			id, _ := op[key].(string)
+			idx := -1
+			if id != "" {
+				idx = findObject(items, id)
+			}
+
+			switch operation {
+			case "create", "upsert", "update":
+				if idx >= 0 {
+					for k, val := range op {
+						items[idx][k] = val
+					}
+					stamp(items[idx], false)
+					continue
+				}
+				if operation == "update" {
+					writeError(w, http.StatusNotFound, fmt.Sprintf("Record not found: %s", id))
+					return
+				}
+				if key == "id" {
+					op["id"] = s.newID()
+				}
+				stamp(op, true)
+				items = append(items, op)
+			case "delete":
+				if idx >= 0 {
+					items = append(items[:idx], items[idx+1:]...)
+				}
+			default:
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown batch operation %q", operation))
+				return
+			}
+		}
+		store[parentID] = items
+		writeStatus(w)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (s *Server) handleDynamicSnippet(w http.ResponseWriter, r *http.Request, svc *service, snippetID string) {
+	obj, ok := svc.snippetContent[snippetID]
+	if !ok {
+		obj = object{"snippet_id": snippetID, "service_id": svc.id, "content": ""}
+		stamp(obj, true)
+		svc.snippetContent[snippetID] = obj
+	}
+	if r.Method == http.MethodPut {
+		mergeForm(obj, r.PostForm)
+		stamp(obj, false)
+	}
+	writeJSON(w, http.StatusOK, obj)
+}
+
+// handleNGWAF serves the NGWAF API as a generic JSON document store. Objects
+// are created with POST on a collection, listed with GET on the collection,
+// and read, updated and deleted via GET, PATCH, PUT and DELETE on
+// "<collection>/<id>". Listing a collection that has never been written to
+// returns no objects, while reading a missing object is a 404.
+func (s *Server) handleNGWAF(w http.ResponseWriter, r *http.Request, path string) {
+	if ngwafCollection(path) {
+		switch r.Method {
+		case http.MethodGet:
+			items := s.ngwaf[path]
+			if items == nil {
+				items = []object{}
+			}
+			writeJSON(w, http.StatusOK, object{"data": items, "meta": object{"limit": len(items), "total": len(items)}})
+		case http.MethodPost:
+			obj := object{}
+			if body := r.Form.Get(jsonBodyKey); body != "" {
+				if err := json.Unmarshal([]byte(body), &obj); err != nil {
+					writeError(w, http.StatusBadRequest, err.Error())
+					return
+				}
+			}
+			obj["id"] = s.newID()
+			stamp(obj, true)
+			s.ngwaf[path] = append(s.ngwaf[path], obj)
+			writeJSON(w, http.StatusOK, obj)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+		return
+	}
+
+	i := strings.LastIndex(path, "/")
+	collection, id := path[:i], path[i+1:]
+	items := s.ngwaf[collection]
+	idx := findObject(items, id)
+	if idx < 0 {
+		writeError(w, http.StatusNotFound, "Record not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, items[idx])
+	case http.MethodPatch, http.MethodPut:
+		update := object{}
+		if err := json.Unmarshal([]byte(r.Form.Get(jsonBodyKey)), &update); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if r.Method == http.MethodPut {
+			update["id"] = id
+			update["created_at"] = items[idx]["created_at"]
+			items[idx] = update
+		} else {
+			for k, v := range update {
+				items[idx][k] = v
+			}
+		}
+		stamp(items[idx], false)
+		writeJSON(w, http.StatusOK, items[idx])
+	case http.MethodDelete:
+		s.ngwaf[collection] = append(items[:idx], items[idx+1:]...)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// ngwafCollection reports whether an NGWAF path is a collection rather than
+// an object. Below ngwaf/v1 the segments alternate between collection names
+// and object IDs, as in ngwaf/v1/workspaces/<id>/lists/<id>.
+func ngwafCollection(path string) bool {
+	rest, ok := strings.CutPrefix(path, "ngwaf/v1/")
+	if !ok {
+		return false
+	}
+	return strings.Count(rest, "/")%2 == 0
+}
+
+func newVersion(number int) *version {
+	now := time.Now().UTC()
+	return &version{
+		number:      number,
+		createdAt:   now,
+		updatedAt:   now,
+		collections: map[string][]object{},
+		settings: object{
+			"general.default_host":       "",
+			"general.default_ttl":        3600,
+			"general.stale_if_error":     false,
+			"general.stale_if_error_ttl": 43200,
+		},
+	}
+}
+
+// clone copies the version's configuration into a new unlocked version.
+func (v *version) clone(number int) *version {
+	c := newVersion(number)
+	c.comment = v.comment
+	for name, items := range v.collections {
+		copied := make([]object, 0, len(items))
+		for _, item := range items {
+			o := object{}
+			for k, val := range item {
+				o[k] = val
+			}
+			o["version"] = number
+			copied = append(copied, o)
+		}
+		c.collections[name] = copied
+	}
+	for k, val := range v.settings {
+		c.settings[k] = val
+	}
+	return c
+}
+
+func (v *version) toObject(serviceID string) object {
+	return object{
+		"number":     v.number,
+		"service_id": serviceID,
+		"active":     v.active,
+		"locked":     v.locked,
+		"staging":    v.staging,
+		"testing":    false,
+		"deployed":   v.active,
+		"comment":    v.comment,
+		"created_at": v.createdAt.Format(time.RFC3339),
+		"updated_at": v.updatedAt.Format(time.RFC3339),
+	}
+}
+
+func (svc *service) activeVersion() *version {
+	for _, v := range svc.versions {
+		if v.active {
+			return v
+		}
+	}
+	return nil
+}
+
+func (svc *service) summary() object {
+	versions := make([]object, 0, len(svc.versions))
+	for _, v := range svc.versions {
+		versions = append(versions, v.toObject(svc.id))
+	}
+	active := 0
+	if v := svc.activeVersion(); v != nil {
+		active = v.number
+	}
+	return object{
+		"id":          svc.id,
+		"name":        svc.name,
+		"comment":     svc.comment,
+		"type":        svc.kind,
+		"customer_id": "fakecustomer",
+		"version":     active,
+		"versions":    versions,
+		"created_at":  svc.createdAt.Format(time.RFC3339),
+		"updated_at":  svc.updatedAt.Format(time.RFC3339),
+	}
+}
+
+func (svc *service) details() object {
+	o := svc.summary()
+	latest := svc.versions[len(svc.versions)-1]
+	o["version"] = latest.toObject(svc.id)
+	if v := svc.activeVersion(); v != nil {
+		o["active_version"] = v.toObject(svc.id)
+	}
+	var environments []object
+	for _, v := range svc.versions {
+		if v.staging {
+			environments = append(environments, object{"name": "staging", "service_version": v.number, "active": true})
+		}
+	}
+	o["environments"] = environments
+	return o
+}
+
+// splitCollection splits the remainder of a versioned path into the
+// collection name and, when present, the name of a single object in it.
+func splitCollection(rest []string) (collection, name string) {
+	switch {
+	case len(rest) == 1 && versionedCollections[rest[0]]:
+		return rest[0], ""
+	case len(rest) == 2 && versionedCollections[rest[0]]:
+		return rest[0], rest[1]
+	case len(rest) == 2 && rest[0] == "logging":
+		return strings.Join(rest, "/"), ""
+	case len(rest) == 3 && rest[0] == "logging":
+		return strings.Join(rest[:2], "/"), rest[2]
+	}
+	return "", ""
+}
+
+// findObject returns the index of the object whose id, name or item_key
+// matches key, or -1.
+func findObject(items []object, key string) int {
+	for i, item := range items {
+		for _, field := range []string{"id", "name", "item_key"} {
+			if v, ok := item[field].(string); ok && v == key {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func mergeForm(obj object, form url.Values) {
+	for k, v := range form {
+		if k == jsonBodyKey {
+			continue
+		}
+		obj[k] = v[0]
+	}
+}
+
+func stamp(obj object, created bool) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	if created {
+		obj["created_at"] = now
+	}
+	obj["updated_at"] = now
+}
+
+// jsonBodyKey is the form key under which parseBody stores a raw JSON body.
+const jsonBodyKey = "__json_body"
+
+// parseBody parses form encoded bodies into r.PostForm and stores JSON bodies
+// verbatim in r.Form under jsonBodyKey.
+func parseBody(r *http.Request) error {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") ||
+		strings.HasPrefix(r.Header.Get("Content-Type"), "application/vnd.api+json") {
+		var body []byte
+		if r.Body != nil {
+			var err error
+			if body, err = io.ReadAll(r.Body); err != nil {
+				return err
+			}
+		}
+		r.Form = url.Values{jsonBodyKey: {string(body)}}
+		r.PostForm = url.Values{}
+		return nil
+	}
+	return r.ParseForm()
+}
+
+// writePage honours the page and per_page query parameters used by go-fastly
+// paginators and advertises the next page with a Link header.
+func writePage(w http.ResponseWriter, r *http.Request, items []object) {
+	if items == nil {
+		items = []object{}
+	}
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if perPage > 0 {
+		if page < 1 {
+			page = 1
+		}
+		start := min((page-1)*perPage, len(items))
+		end := min(start+perPage, len(items))
+		if end < len(items) {
+			q := r.URL.Query()
+			q.Set("page", strconv.Itoa(page+1))
+			next := url.URL{Path: r.URL.Path, RawQuery: q.Encode()}
+			w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", next.String()))
+		}
+		items = items[start:end]
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeStatus(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, object{"status": "ok"})
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, object{"msg": msg, "detail": msg})
+}
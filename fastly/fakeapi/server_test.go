@@ -0,0 +1,166 @@
+package fakeapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func do(t *testing.T, srv *Server, method, path string, form url.Values, out any) int {
+	t.Helper()
+
+	var body *strings.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	} else {
+		body = strings.NewReader("")
+	}
+
+	req, err := http.NewRequest(method, srv.URL+path, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Fastly-Key", APIKey)
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("decoding %s %s: %s", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestServerServiceVersionLifecycle(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	var svc map[string]any
+	if code := do(t, srv, http.MethodPost, "/service", url.Values{"name": {"test"}, "type": {"vcl"}}, &svc); code != http.StatusOK {
+		t.Fatalf("create service: got status %d", code)
+	}
+	id := svc["id"].(string)
+
+	if code := do(t, srv, http.MethodPost, "/service/"+id+"/version/1/backend", url.Values{"name": {"origin"}, "port": {"443"}}, nil); code != http.StatusOK {
+		t.Fatalf("create backend: got status %d", code)
+	}
+	if code := do(t, srv, http.MethodPut, "/service/"+id+"/version/1/activate", nil, nil); code != http.StatusOK {
+		t.Fatalf("activate: got status %d", code)
+	}
+
+	// Locked versions reject changes.
+	if code := do(t, srv, http.MethodPost, "/service/"+id+"/version/1/backend", url.Values{"name": {"other"}}, nil); code != http.StatusConflict {
+		t.Errorf("create backend on locked version: got status %d, want %d", code, http.StatusConflict)
+	}
+
+	var clone map[string]any
+	do(t, srv, http.MethodPut, "/service/"+id+"/version/1/clone", nil, &clone)
+	if clone["number"].(float64) != 2 || clone["locked"].(bool) {
+		t.Fatalf("unexpected clone: %#v", clone)
+	}
+
+	var backends []map[string]any
+	do(t, srv, http.MethodGet, "/service/"+id+"/version/2/backend", nil, &backends)
+	if len(backends) != 1 || backends[0]["name"] != "origin" || backends[0]["port"] != "443" {
+		t.Fatalf("clone did not copy backends: %#v", backends)
+	}
+
+	var details map[string]any
+	do(t, srv, http.MethodGet, "/service/"+id+"/details", nil, &details)
+	active := details["active_version"].(map[string]any)
+	latest := details["version"].(map[string]any)
+	if active["number"].(float64) != 1 || latest["number"].(float64) != 2 {
+		t.Errorf("unexpected details versions: active %v, latest %v", active["number"], latest["number"])
+	}
+
+	var valid map[string]any
+	do(t, srv, http.MethodGet, "/service/"+id+"/version/2/validate", nil, &valid)
+	if valid["status"] != "ok" {
+		t.Errorf("validate: got %#v", valid)
+	}
+
+	if code := do(t, srv, http.MethodDelete, "/service/"+id, nil, nil); code != http.StatusBadRequest {
+		t.Errorf("delete active service: got status %d, want %d", code, http.StatusBadRequest)
+	}
+}
+
+func TestServerRejectsUnknownKey(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	resp, err := srv.Client().Get(srv.URL + "/service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func TestServerDictionaryItemBatch(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	var svc map[string]any
+	do(t, srv, http.MethodPost, "/service", url.Values{"name": {"test"}}, &svc)
+	id := svc["id"].(string)
+
+	batch := `{"items":[{"op":"create","item_key":"a","item_value":"1"},{"op":"upsert","item_key":"b","item_value":"2"}]}`
+	req, _ := http.NewRequest(http.MethodPatch, srv.URL+"/service/"+id+"/dictionary/d1/items", strings.NewReader(batch))
+	req.Header.Set("Fastly-Key", APIKey)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	var items []map[string]any
+	do(t, srv, http.MethodGet, "/service/"+id+"/dictionary/d1/items", nil, &items)
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2", len(items))
+	}
+}
+
+func TestServerNGWAFCollectionsAndObjects(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	// Collections that have never been written to are empty, at any depth.
+	for _, path := range []string{"/ngwaf/v1/workspaces", "/ngwaf/v1/workspaces/abc/lists"} {
+		var page struct {
+			Data []map[string]any `json:"data"`
+		}
+		if code := do(t, srv, http.MethodGet, path, nil, &page); code != http.StatusOK || len(page.Data) != 0 {
+			t.Errorf("list %s: got status %d and %d objects", path, code, len(page.Data))
+		}
+	}
+
+	var workspace map[string]any
+	if code := do(t, srv, http.MethodPost, "/ngwaf/v1/workspaces", nil, &workspace); code != http.StatusOK {
+		t.Fatalf("create workspace: got status %d", code)
+	}
+	id := workspace["id"].(string)
+
+	if code := do(t, srv, http.MethodGet, "/ngwaf/v1/workspaces/"+id, nil, nil); code != http.StatusOK {
+		t.Errorf("get workspace: got status %d", code)
+	}
+
+	// Missing objects are not found, at any depth.
+	for _, path := range []string{"/ngwaf/v1/workspaces/missing", "/ngwaf/v1/workspaces/" + id + "/lists/missing"} {
+		if code := do(t, srv, http.MethodGet, path, nil, nil); code != http.StatusNotFound {
+			t.Errorf("get %s: got status %d", path, code)
+		}
+	}
+}
@@ -14,6 +14,7 @@ import (
 
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 
 	gofastly "github.com/fastly/go-fastly/v17/fastly"
@@ -140,12 +141,19 @@ func renderTestConfigTemplate(t *testing.T, tmpl *template.Template, data any) s
 // tests to compare a list of expected backends against the list of
 // configured backends for a service-version.
 func testAccCheckFastlyServiceAttributesBackends(service *gofastly.ServiceDetail, name string, backends []string, version int) resource.TestCheckFunc {
+	return testAccCheckFastlyServiceAttributesBackendsWithProvider(testAccProvider, service, name, backends, version)
+}
+
+// testAccCheckFastlyServiceAttributesBackendsWithProvider is
+// testAccCheckFastlyServiceAttributesBackends using the API client of the
+// given provider, such as the provider of a testAccCassette.
+func testAccCheckFastlyServiceAttributesBackendsWithProvider(p *schema.Provider, service *gofastly.ServiceDetail, name string, backends []string, version int) resource.TestCheckFunc {
 	return func(_ *terraform.State) error {
 		if gofastly.ToValue(service.Name) != name {
 			return fmt.Errorf("bad name, expected (%s), got (%s)", name, gofastly.ToValue(service.Name))
 		}
 
-		conn := testAccProvider.Meta().(*APIClient).conn
+		conn := p.Meta().(*APIClient).conn
 		backendList, err := conn.ListBackends(context.TODO(), &gofastly.ListBackendsInput{
 			ServiceID:      gofastly.ToValue(service.ServiceID),
 			ServiceVersion: version,
@@ -0,0 +1,213 @@
+package fastly
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	fwdiag "github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	providerschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+var (
+	_ provider.Provider                       = &frameworkProvider{}
+	_ provider.ProviderWithEphemeralResources = &frameworkProvider{}
+	_ provider.ProviderWithFunctions          = &frameworkProvider{}
+)
+
+// frameworkProvider is the terraform-plugin-framework half of the provider.
+// It is served alongside the SDK provider by ProviderServerFactory, and new
+// resources, data sources, ephemeral resources and functions should be
+// registered here. See DEVELOPMENT.md for how to move an existing SDK
+// resource over.
+type frameworkProvider struct {
+	// sdk is the SDK provider served alongside this one. Both providers
+	// must share the same provider schema, and the API client configured by
+	// the SDK provider is reused by framework resources.
+	sdk     *schema.Provider
+	version string
+}
+
+func newFrameworkProvider(sdk *schema.Provider, version string) provider.Provider {
+	return &frameworkProvider{sdk: sdk, version: version}
+}
+
+// frameworkResources returns the resources implemented with the framework.
+func frameworkResources() []func() resource.Resource {
+	return []func() resource.Resource{}
+}
+
+// frameworkDataSources returns the data sources implemented with the
+// framework.
+func frameworkDataSources() []func() datasource.DataSource {
+	return []func() datasource.DataSource{}
+}
+
+func (p *frameworkProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "fastly"
+	resp.Version = p.version
+}
+
+func (p *frameworkProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
+	attributes, blocks := frameworkProviderAttributes(p.sdk.Schema)
+	resp.Schema = providerschema.Schema{
+		Attributes: attributes,
+		Blocks:     blocks,
+	}
+}
+
+// Configure shares the API client of the SDK provider with framework
+// resources and data sources. The mux server configures its servers in
+// order and the SDK provider is served first, so by the time this runs the
+// SDK provider has already validated the configuration and reported any
+// errors.
+func (p *frameworkProvider) Configure(_ context.Context, _ provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	client, ok := p.sdk.Meta().(*APIClient)
+	if !ok {
+		return
+	}
+
+	resp.DataSourceData = client
+	resp.EphemeralResourceData = client
+	resp.ResourceData = client
+}
+
+func (p *frameworkProvider) Resources(_ context.Context) []func() resource.Resource {
+	return frameworkResources()
+}
+
+func (p *frameworkProvider) DataSources(_ context.Context) []func() datasource.DataSource {
+	return frameworkDataSources()
+}
+
+func (p *frameworkProvider) EphemeralResources(_ context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{}
+}
+
+func (p *frameworkProvider) Functions(_ context.Context) []func() function.Function {
+	return []func() function.Function{}
+}
+
+// frameworkAPIClient returns the API client from the provider data passed to
+// a framework resource or data source's Configure method. It returns nil
+// before the provider has been configured, e.g. during validation.
+func frameworkAPIClient(providerData any, diags *fwdiag.Diagnostics) *APIClient {
+	if providerData == nil {
+		return nil
+	}
+
+	client, ok := providerData.(*APIClient)
+	if !ok {
+		diags.AddError(
+			"Unexpected provider data",
+			fmt.Sprintf("Expected *APIClient, got %T. This is always a bug in the provider.", providerData),
+		)
+		return nil
+	}
+	return client
+}
+
+// frameworkProviderAttributes converts the SDK provider schema to the
+// framework provider schema. The mux server requires both providers to
+// declare identical provider schemas, so the SDK schema is the single
+// source of truth for provider configuration.
+func frameworkProviderAttributes(sm map[string]*schema.Schema) (map[string]providerschema.Attribute, map[string]providerschema.Block) {
+	attributes := map[string]providerschema.Attribute{}
+	blocks := map[string]providerschema.Block{}
+
+	for name, s := range sm {
+		if r, ok := s.Elem.(*schema.Resource); ok {
+			nestedAttributes, nestedBlocks := frameworkProviderAttributes(r.Schema)
+			object := providerschema.NestedBlockObject{
+				Attributes: nestedAttributes,
+				Blocks:     nestedBlocks,
+			}
+			// The framework has no MinItems or MaxItems for blocks, so they
+			// are enforced by validators. tf5muxserver ignores them when
+			// comparing the provider schemas.
+			if s.Type == schema.TypeSet {
+				var validators []validator.Set
+				if s.MinItems > 0 {
+					validators = append(validators, setvalidator.SizeAtLeast(s.MinItems))
+				}
+				if s.MaxItems > 0 {
+					validators = append(validators, setvalidator.SizeAtMost(s.MaxItems))
+				}
+				blocks[name] = providerschema.SetNestedBlock{NestedObject: object, Description: s.Description, Validators: validators}
+			} else {
+				var validators []validator.List
+				if s.MinItems > 0 {
+					validators = append(validators, listvalidator.SizeAtLeast(s.MinItems))
+				}
+				if s.MaxItems > 0 {
+					validators = append(validators, listvalidator.SizeAtMost(s.MaxItems))
+				}
+				blocks[name] = providerschema.ListNestedBlock{NestedObject: object, Description: s.Description, Validators: validators}
+			}
+			continue
+		}
+
+		switch s.Type {
+		case schema.TypeBool:
+			attributes[name] = providerschema.BoolAttribute{
+				Required: s.Required, Optional: s.Optional, Sensitive: s.Sensitive, Description: s.Description,
+			}
+		case schema.TypeInt:
+			attributes[name] = providerschema.Int64Attribute{
+				Required: s.Required, Optional: s.Optional, Sensitive: s.Sensitive, Description: s.Description,
+			}
+		case schema.TypeFloat:
+			attributes[name] = providerschema.Float64Attribute{
+				Required: s.Required, Optional: s.Optional, Sensitive: s.Sensitive, Description: s.Description,
+			}
+		case schema.TypeList:
+			attributes[name] = providerschema.ListAttribute{
+				ElementType: frameworkElementType(s), Required: s.Required, Optional: s.Optional, Sensitive: s.Sensitive, Description: s.Description,
+			}
+		case schema.TypeSet:
+			attributes[name] = providerschema.SetAttribute{
+				ElementType: frameworkElementType(s), Required: s.Required, Optional: s.Optional, Sensitive: s.Sensitive, Description: s.Description,
+			}
+		case schema.TypeMap:
+			attributes[name] = providerschema.MapAttribute{
+				ElementType: frameworkElementType(s), Required: s.Required, Optional: s.Optional, Sensitive: s.Sensitive, Description: s.Description,
+			}
+		default:
+			attributes[name] = providerschema.StringAttribute{
+				Required: s.Required, Optional: s.Optional, Sensitive: s.Sensitive, Description: s.Description,
+			}
+		}
+	}
+
+	return attributes, blocks
+}
+
+// frameworkElementType returns the framework type of the elements of a
+// list, set or map of primitives.
+func frameworkElementType(s *schema.Schema) attr.Type {
+	e, ok := s.Elem.(*schema.Schema)
+	if !ok {
+		return types.StringType
+	}
+
+	switch e.Type {
+	case schema.TypeBool:
+		return types.BoolType
+	case schema.TypeInt:
+		return types.Int64Type
+	case schema.TypeFloat:
+		return types.Float64Type
+	default:
+		return types.StringType
+	}
+}
@@ -0,0 +1,359 @@
+package fastly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// The names of the guardrails, which are also the names of their attributes
+// in the provider's guardrails block and the values accepted by
+// override_guardrails.
+const (
+	guardrailForbidDomainRemoval          = "forbid_domain_removal"
+	guardrailForbidForceDestroy           = "forbid_force_destroy"
+	guardrailForbidReuse                  = "forbid_reuse"
+	guardrailMaxBackendDeletions          = "max_backend_deletions"
+	guardrailProtectDynamicSnippets       = "protect_dynamic_snippets"
+	guardrailProtectWriteOnlyDictionaries = "protect_write_only_dictionaries"
+	guardrailRequireVersionComment        = "require_version_comment"
+)
+
+var guardrailNames = []string{
+	guardrailForbidDomainRemoval,
+	guardrailForbidForceDestroy,
+	guardrailForbidReuse,
+	guardrailMaxBackendDeletions,
+	guardrailProtectDynamicSnippets,
+	guardrailProtectWriteOnlyDictionaries,
+	guardrailRequireVersionComment,
+}
+
+// Guardrails are provider-level rules that block destructive changes at plan
+// time. See expandGuardrails for the defaults.
+type Guardrails struct {
+	ForbidDomainRemoval bool
+	ForbidForceDestroy  bool
+	ForbidReuse         bool
+	// MaxBackendDeletions is the maximum number of backends a single plan may
+	// delete from a service. A negative value means there is no limit.
+	MaxBackendDeletions          int
+	ProtectDynamicSnippets       bool
+	ProtectWriteOnlyDictionaries bool
+	RequireVersionComment        bool
+}
+
+// guardrailsSchema returns the schema of the provider's guardrails block.
+func guardrailsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "Rules that block destructive changes to services at plan time. Each rule can be overridden for a single resource by adding its name to the resource's `override_guardrails` attribute",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				guardrailForbidDomainRemoval: {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+					Description: "Forbid removing a `domain` from a service. Default `false`",
+				},
+				guardrailForbidForceDestroy: {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+					Description: "Forbid setting `force_destroy = true` on a service. Default `false`",
+				},
+				guardrailForbidReuse: {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+					Description: "Forbid setting `reuse = true` on a service. Default `false`",
+				},
+				guardrailMaxBackendDeletions: {
+					Type:             schema.TypeInt,
+					Optional:         true,
+					Default:          -1,
+					Description:      "The maximum number of backends a single plan may delete from a service. `-1` means there is no limit. Default `-1`",
+					ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(-1)),
+				},
+				guardrailProtectDynamicSnippets: {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+					Description: "Forbid removing a `dynamicsnippet` block from a service, and deleting or moving a `fastly_service_dynamic_snippet_content` resource. Default `false`",
+				},
+				guardrailProtectWriteOnlyDictionaries: {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+					Description: "Forbid removing or recreating a `dictionary` with `write_only = true` from a service, and deleting items of such a dictionary with `fastly_service_dictionary_items`, as its items cannot be recovered. Default `false`",
+				},
+				guardrailRequireVersionComment: {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+					Description: "Require `version_comment` to be set whenever a new service version will be created. Default `false`",
+				},
+			},
+		},
+	}
+}
+
+// expandGuardrails expands the provider's guardrails block.
+func expandGuardrails(raw []any) (Guardrails, error) {
+	g := Guardrails{MaxBackendDeletions: -1}
+	if len(raw) == 0 || raw[0] == nil {
+		return g, nil
+	}
+
+	resource := raw[0].(map[string]any)
+	g.ForbidDomainRemoval = resource[guardrailForbidDomainRemoval].(bool)
+	g.ForbidForceDestroy = resource[guardrailForbidForceDestroy].(bool)
+	g.ForbidReuse = resource[guardrailForbidReuse].(bool)
+	g.MaxBackendDeletions = resource[guardrailMaxBackendDeletions].(int)
+	g.ProtectDynamicSnippets = resource[guardrailProtectDynamicSnippets].(bool)
+	g.ProtectWriteOnlyDictionaries = resource[guardrailProtectWriteOnlyDictionaries].(bool)
+	g.RequireVersionComment = resource[guardrailRequireVersionComment].(bool)
+	return g, nil
+}
+
+// overrideGuardrailsSchema returns the schema of the override_guardrails
+// attribute of the resources that guardrails apply to.
+func overrideGuardrailsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Description: "The names of provider `guardrails` that do not apply to this resource, e.g. `[\"forbid_domain_removal\"]`. Intended to be set temporarily for a deliberate destructive change",
+		Elem: &schema.Schema{
+			Type:             schema.TypeString,
+			ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(guardrailNames, false)),
+		},
+	}
+}
+
+// guardrailsFromMeta returns the guardrails configured for the provider.
+func guardrailsFromMeta(meta any) Guardrails {
+	client, ok := meta.(*APIClient)
+	if !ok {
+		return Guardrails{MaxBackendDeletions: -1}
+	}
+	return client.guardrails
+}
+
+// guardrailOverrides returns the guardrails overridden by a resource.
+func guardrailOverrides(v any) map[string]bool {
+	overrides := map[string]bool{}
+	if set, ok := v.(*schema.Set); ok {
+		for _, name := range set.List() {
+			overrides[name.(string)] = true
+		}
+	}
+	return overrides
+}
+
+// guardrailViolation returns the error for a change blocked by a guardrail.
+func guardrailViolation(name, format string, a ...any) error {
+	return fmt.Errorf("guardrail %s: %s. Add %q to override_guardrails to allow this change", name, fmt.Sprintf(format, a...), name)
+}
+
+// serviceGuardrailChange describes the parts of a planned service change that
+// guardrails apply to.
+type serviceGuardrailChange struct {
+	forceDestroy                 bool
+	newVersion                   bool
+	removedBackends              []string
+	removedDomains               []string
+	removedDynamicSnippets       []string
+	removedWriteOnlyDictionaries []string
+	reuse                        bool
+	versionComment               string
+}
+
+// checkService returns the guardrails violated by a planned service change,
+// ignoring those in overrides.
+func (g Guardrails) checkService(c serviceGuardrailChange, overrides map[string]bool) error {
+	var errs []error
+	check := func(name string, violated bool, format string, a ...any) {
+		if violated && !overrides[name] {
+			errs = append(errs, guardrailViolation(name, format, a...))
+		}
+	}
+
+	check(guardrailForbidDomainRemoval, g.ForbidDomainRemoval && len(c.removedDomains) > 0,
+		"removing domains %s is forbidden", quoteNames(c.removedDomains))
+	check(guardrailMaxBackendDeletions, g.MaxBackendDeletions >= 0 && len(c.removedBackends) > g.MaxBackendDeletions,
+		"deleting %d backends (%s) exceeds the maximum of %d", len(c.removedBackends), quoteNames(c.removedBackends), g.MaxBackendDeletions)
+	check(guardrailRequireVersionComment, g.RequireVersionComment && c.newVersion && strings.TrimSpace(c.versionComment) == "",
+		"version_comment must be set when a new service version is created")
+	check(guardrailForbidForceDestroy, g.ForbidForceDestroy && c.forceDestroy,
+		"setting force_destroy to true is forbidden")
+	check(guardrailForbidReuse, g.ForbidReuse && c.reuse,
+		"setting reuse to true is forbidden")
+	check(guardrailProtectWriteOnlyDictionaries, g.ProtectWriteOnlyDictionaries && len(c.removedWriteOnlyDictionaries) > 0,
+		"removing or recreating write_only dictionaries %s is forbidden, as their items cannot be recovered", quoteNames(c.removedWriteOnlyDictionaries))
+	check(guardrailProtectDynamicSnippets, g.ProtectDynamicSnippets && len(c.removedDynamicSnippets) > 0,
+		"removing dynamic snippets %s is forbidden", quoteNames(c.removedDynamicSnippets))
+
+	return errors.Join(errs...)
+}
+
+// validateServiceGuardrails enforces the provider's guardrails on service
+// resources.
+func validateServiceGuardrails(_ context.Context, d *schema.ResourceDiff, meta any) error {
+	g := guardrailsFromMeta(meta)
+
+	// force_destroy and reuse are only checked when they are set, so that
+	// services that already had them before the guardrails were enabled
+	// can still be planned.
+	setToTrue := func(key string) bool {
+		return d.Get(key).(bool) && (d.Id() == "" || d.HasChange(key))
+	}
+
+	c := serviceGuardrailChange{
+		forceDestroy:   setToTrue("force_destroy"),
+		newVersion:     d.Id() == "" || d.HasChange("cloned_version"),
+		reuse:          setToTrue("reuse"),
+		versionComment: d.Get("version_comment").(string),
+	}
+
+	// Not every block exists on every service type, e.g. Compute services
+	// have no dynamicsnippet block.
+	config := d.GetRawConfig().Type()
+	removed := func(key string, keep func(map[string]any) bool) []string {
+		if !config.IsObjectType() || !config.HasAttribute(key) {
+			return nil
+		}
+		o, n := d.GetChange(key)
+		return removedBlockNames(o, n, keep)
+	}
+	c.removedDomains = removed("domain", nil)
+	c.removedBackends = removed("backend", nil)
+	c.removedDynamicSnippets = removed("dynamicsnippet", nil)
+	c.removedWriteOnlyDictionaries = removed("dictionary", func(resource map[string]any) bool {
+		writeOnly, _ := resource["write_only"].(bool)
+		return writeOnly
+	})
+
+	return g.checkService(c, guardrailOverrides(d.Get("override_guardrails")))
+}
+
+// validateDynamicSnippetContentGuardrails enforces the provider's guardrails
+// on fastly_service_dynamic_snippet_content. Changing service_id or
+// snippet_id leaves the previous snippet's content behind, so it is treated
+// like a deletion.
+//
+// NOTE: Terraform does not pass destroy plans to CustomizeDiff, so destroying
+// the resource is not checked. See the Guardrails section of the provider
+// documentation.
+func validateDynamicSnippetContentGuardrails(_ context.Context, d *schema.ResourceDiff, meta any) error {
+	if d.Id() == "" || !d.HasChanges("service_id", "snippet_id") {
+		return nil
+	}
+	if !guardrailsFromMeta(meta).ProtectDynamicSnippets || guardrailOverrides(d.Get("override_guardrails"))[guardrailProtectDynamicSnippets] {
+		return nil
+	}
+	return guardrailViolation(guardrailProtectDynamicSnippets, "moving dynamic snippet content to another snippet is forbidden")
+}
+
+// validateDictionaryItemsGuardrails enforces the provider's guardrails on
+// fastly_service_dictionary_items. Replacing the resource deletes all of its
+// items from the previous dictionary.
+func validateDictionaryItemsGuardrails(ctx context.Context, d *schema.ResourceDiff, meta any) error {
+	if d.Id() == "" {
+		return nil
+	}
+	if !guardrailsFromMeta(meta).ProtectWriteOnlyDictionaries || guardrailOverrides(d.Get("override_guardrails"))[guardrailProtectWriteOnlyDictionaries] {
+		return nil
+	}
+
+	replace := d.HasChanges("service_id", "dictionary_id")
+	if !replace && !d.Get("manage_items").(bool) {
+		return nil
+	}
+	o, n := d.GetChange("items")
+	removed := removedMapKeys(o, n, replace)
+	if len(removed) == 0 {
+		return nil
+	}
+
+	client, ok := meta.(*APIClient)
+	if !ok {
+		return nil
+	}
+	serviceID, _ := d.GetChange("service_id")
+	dictionaryID, _ := d.GetChange("dictionary_id")
+	writeOnly, err := dictionaryIsWriteOnly(ctx, client.conn, serviceID.(string), dictionaryID.(string))
+	if err != nil || !writeOnly {
+		return err
+	}
+	return guardrailViolation(guardrailProtectWriteOnlyDictionaries,
+		"deleting %d items of a write_only dictionary is forbidden, as they cannot be recovered", len(removed))
+}
+
+// removedMapKeys returns the keys of the map o that are not in the map n, or
+// all of the keys of o when all is true, as a sorted list.
+func removedMapKeys(o, n any, all bool) []string {
+	om, _ := o.(map[string]any)
+	nm, _ := n.(map[string]any)
+	var removed []string
+	for key := range om {
+		if _, ok := nm[key]; all || !ok {
+			removed = append(removed, key)
+		}
+	}
+	sort.Strings(removed)
+	return removed
+}
+
+// removedBlockNames returns the names of the blocks in o that are not in n,
+// as a sorted list. When keep is not nil, only the blocks in o for which it
+// returns true are considered, and a block in n only matches when keep also
+// returns true for it, so e.g. a dictionary that is no longer write_only
+// counts as removed.
+func removedBlockNames(o, n any, keep func(map[string]any) bool) []string {
+	names := func(v any) map[string]bool {
+		m := map[string]bool{}
+		var blocks []any
+		switch v := v.(type) {
+		case *schema.Set:
+			blocks = v.List()
+		case []any:
+			blocks = v
+		}
+		for _, b := range blocks {
+			resource, ok := b.(map[string]any)
+			if !ok || (keep != nil && !keep(resource)) {
+				continue
+			}
+			if name, ok := resource["name"].(string); ok {
+				m[name] = true
+			}
+		}
+		return m
+	}
+
+	newNames := names(n)
+	var removed []string
+	for name := range names(o) {
+		if !newNames[name] {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+	return removed
+}
+
+// quoteNames renders names for use in guardrail errors.
+func quoteNames(names []string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, fmt.Sprintf("%q", name))
+	}
+	return strings.Join(quoted, ", ")
+}
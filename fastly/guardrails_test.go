@@ -0,0 +1,179 @@
+package fastly
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestExpandGuardrails(t *testing.T) {
+	g, err := expandGuardrails(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(Guardrails{MaxBackendDeletions: -1}, g); diff != "" {
+		t.Errorf("unexpected default guardrails (-want +got):\n%s", diff)
+	}
+
+	block := map[string]any{
+		guardrailForbidDomainRemoval:          true,
+		guardrailForbidForceDestroy:           false,
+		guardrailForbidReuse:                  false,
+		guardrailMaxBackendDeletions:          1,
+		guardrailProtectDynamicSnippets:       true,
+		guardrailProtectWriteOnlyDictionaries: false,
+		guardrailRequireVersionComment:        true,
+	}
+	g, err = expandGuardrails([]any{block})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Guardrails{
+		ForbidDomainRemoval:    true,
+		MaxBackendDeletions:    1,
+		ProtectDynamicSnippets: true,
+		RequireVersionComment:  true,
+	}
+	if diff := cmp.Diff(want, g); diff != "" {
+		t.Errorf("unexpected guardrails (-want +got):\n%s", diff)
+	}
+}
+
+func TestGuardrailsCheckService(t *testing.T) {
+	g := Guardrails{
+		ForbidDomainRemoval:          true,
+		ForbidForceDestroy:           true,
+		ForbidReuse:                  true,
+		MaxBackendDeletions:          1,
+		ProtectDynamicSnippets:       true,
+		ProtectWriteOnlyDictionaries: true,
+		RequireVersionComment:        true,
+	}
+
+	for _, tc := range []struct {
+		name       string
+		guardrails Guardrails
+		change     serviceGuardrailChange
+		overrides  map[string]bool
+		want       []string
+	}{
+		{
+			name:       "no guardrails",
+			guardrails: Guardrails{MaxBackendDeletions: -1},
+			change: serviceGuardrailChange{
+				forceDestroy:    true,
+				newVersion:      true,
+				removedBackends: []string{"a", "b"},
+				removedDomains:  []string{"example.com"},
+			},
+		},
+		{
+			name:       "allowed change",
+			guardrails: g,
+			change: serviceGuardrailChange{
+				newVersion:      true,
+				removedBackends: []string{"a"},
+				versionComment:  "Remove backend a",
+			},
+		},
+		{
+			name:       "violations",
+			guardrails: g,
+			change: serviceGuardrailChange{
+				forceDestroy:                 true,
+				newVersion:                   true,
+				removedBackends:              []string{"a", "b"},
+				removedDomains:               []string{"example.com"},
+				removedDynamicSnippets:       []string{"blocklist"},
+				removedWriteOnlyDictionaries: []string{"secrets"},
+				reuse:                        true,
+			},
+			want: []string{
+				`guardrail forbid_domain_removal: removing domains "example.com" is forbidden`,
+				`guardrail max_backend_deletions: deleting 2 backends ("a", "b") exceeds the maximum of 1`,
+				`guardrail require_version_comment: version_comment must be set`,
+				`guardrail forbid_force_destroy: setting force_destroy to true is forbidden`,
+				`guardrail forbid_reuse: setting reuse to true is forbidden`,
+				`guardrail protect_write_only_dictionaries: removing or recreating write_only dictionaries "secrets" is forbidden`,
+				`guardrail protect_dynamic_snippets: removing dynamic snippets "blocklist" is forbidden`,
+			},
+		},
+		{
+			name:       "overridden",
+			guardrails: g,
+			change: serviceGuardrailChange{
+				removedBackends: []string{"a", "b"},
+				removedDomains:  []string{"example.com"},
+			},
+			overrides: map[string]bool{guardrailForbidDomainRemoval: true},
+			want: []string{
+				`guardrail max_backend_deletions: deleting 2 backends`,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.guardrails.checkService(tc.change, tc.overrides)
+			if len(tc.want) == 0 {
+				if err != nil {
+					t.Fatalf("expected no violations, got %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected violations, got none")
+			}
+
+			got := strings.Split(err.Error(), "\n")
+			if len(got) != len(tc.want) {
+				t.Fatalf("expected %d violations, got %d:\n%s", len(tc.want), len(got), err)
+			}
+			for i, want := range tc.want {
+				if !strings.HasPrefix(got[i], want) {
+					t.Errorf("expected violation %d to start with %q, got %q", i, want, got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRemovedBlockNames(t *testing.T) {
+	dictionary := func(name string, writeOnly bool) map[string]any {
+		return map[string]any{"name": name, "write_only": writeOnly}
+	}
+	writeOnly := func(resource map[string]any) bool {
+		return resource["write_only"].(bool)
+	}
+	set := func(blocks ...any) *schema.Set {
+		return schema.NewSet(func(v any) int {
+			return schema.HashString(v.(map[string]any)["name"])
+		}, blocks)
+	}
+
+	o := set(dictionary("a", true), dictionary("b", true), dictionary("c", false), dictionary("d", true))
+	n := set(dictionary("a", true), dictionary("b", false))
+
+	if diff := cmp.Diff([]string{"c", "d"}, removedBlockNames(o, n, nil)); diff != "" {
+		t.Errorf("unexpected removed blocks (-want +got):\n%s", diff)
+	}
+	// b is no longer write_only, so it is recreated.
+	if diff := cmp.Diff([]string{"b", "d"}, removedBlockNames(o, n, writeOnly)); diff != "" {
+		t.Errorf("unexpected removed write_only blocks (-want +got):\n%s", diff)
+	}
+	if got := removedBlockNames(nil, n, nil); got != nil {
+		t.Errorf("expected no removed blocks, got %v", got)
+	}
+}
+
+func TestRemovedMapKeys(t *testing.T) {
+	o := map[string]any{"a": "1", "b": "2", "c": "3"}
+	n := map[string]any{"a": "1", "c": "4"}
+
+	if diff := cmp.Diff([]string{"b"}, removedMapKeys(o, n, false)); diff != "" {
+		t.Errorf("unexpected removed keys (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"a", "b", "c"}, removedMapKeys(o, n, true)); diff != "" {
+		t.Errorf("unexpected removed keys (-want +got):\n%s", diff)
+	}
+}
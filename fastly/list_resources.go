@@ -0,0 +1,223 @@
+package fastly
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	gofastly "github.com/fastly/go-fastly/v17/fastly"
+	ws "github.com/fastly/go-fastly/v17/fastly/ngwaf/v1/workspaces"
+)
+
+// listResource describes a list resource, which `terraform query` uses to
+// discover existing remote objects of a managed resource type so that they
+// can be imported in bulk.
+type listResource struct {
+	// Schema holds the arguments accepted in the `list` block's config.
+	Schema map[string]*schema.Schema
+
+	// List returns the remote objects matching the arguments in d.
+	List func(ctx context.Context, d *schema.ResourceData, meta any) ([]listResourceItem, error)
+
+	// Prepare is called on the ResourceData of every result before its Read
+	// function runs when the full resource object is requested.
+	Prepare func(d *schema.ResourceData) error
+}
+
+// listResourceItem is a single remote object returned by a list resource.
+type listResourceItem struct {
+	ID   string
+	Name string
+}
+
+// listResources returns the list resources supported by the provider, keyed
+// by the managed resource type they list.
+func listResources() map[string]*listResource {
+	return map[string]*listResource{
+		"fastly_configstore":      listResourceConfigStores(),
+		"fastly_kvstore":          listResourceKVStores(),
+		"fastly_ngwaf_workspace":  listResourceNGWAFWorkspaces(),
+		"fastly_secretstore":      listResourceSecretStores(),
+		"fastly_service_compute":  listResourceServices(ServiceTypeCompute),
+		"fastly_service_vcl":      listResourceServices(ServiceTypeVCL),
+		"fastly_tls_subscription": listResourceTLSSubscriptions(),
+	}
+}
+
+// listResourceNameSchema returns the `name_prefix` argument shared by list
+// resources whose remote objects have a name.
+func listResourceNameSchema(kind string) map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"name_prefix": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: fmt.Sprintf("Only return %s whose name starts with this prefix.", kind),
+		},
+	}
+}
+
+// filterListResourceItems drops the items whose name does not start with the
+// `name_prefix` argument, if it is set.
+func filterListResourceItems(d *schema.ResourceData, items []listResourceItem) []listResourceItem {
+	prefix := d.Get("name_prefix").(string)
+	if prefix == "" {
+		return items
+	}
+
+	result := make([]listResourceItem, 0, len(items))
+	for _, item := range items {
+		if strings.HasPrefix(item.Name, prefix) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func listResourceServices(serviceType string) *listResource {
+	return &listResource{
+		Schema: listResourceNameSchema("services"),
+		List: func(ctx context.Context, d *schema.ResourceData, meta any) ([]listResourceItem, error) {
+			conn := meta.(*APIClient).conn
+
+			services, err := conn.ListServices(ctx, &gofastly.ListServicesInput{})
+			if err != nil {
+				return nil, fmt.Errorf("error fetching services: %w", err)
+			}
+
+			var items []listResourceItem
+			for _, s := range services {
+				if gofastly.ToValue(s.Type) != serviceType {
+					continue
+				}
+				items = append(items, listResourceItem{
+					ID:   gofastly.ToValue(s.ServiceID),
+					Name: gofastly.ToValue(s.Name),
+				})
+			}
+			return filterListResourceItems(d, items), nil
+		},
+		Prepare: func(d *schema.ResourceData) error {
+			// Read the active version, as `terraform import` of a service ID does.
+			return d.Set("activate", true)
+		},
+	}
+}
+
+func listResourceConfigStores() *listResource {
+	return &listResource{
+		Schema: listResourceNameSchema("Config Stores"),
+		List: func(ctx context.Context, d *schema.ResourceData, meta any) ([]listResourceItem, error) {
+			conn := meta.(*APIClient).conn
+
+			stores, err := conn.ListConfigStores(ctx, &gofastly.ListConfigStoresInput{})
+			if err != nil {
+				return nil, fmt.Errorf("error fetching Config Stores: %w", err)
+			}
+
+			items := make([]listResourceItem, 0, len(stores))
+			for _, s := range stores {
+				items = append(items, listResourceItem{ID: s.StoreID, Name: s.Name})
+			}
+			return filterListResourceItems(d, items), nil
+		},
+	}
+}
+
+func listResourceKVStores() *listResource {
+	return &listResource{
+		Schema: listResourceNameSchema("KV Stores"),
+		List: func(ctx context.Context, d *schema.ResourceData, meta any) ([]listResourceItem, error) {
+			stores, err := listKVStores(ctx, meta.(*APIClient).conn)
+			if err != nil {
+				return nil, fmt.Errorf("error fetching KV Stores: %w", err)
+			}
+
+			items := make([]listResourceItem, 0, len(stores))
+			for _, s := range stores {
+				items = append(items, listResourceItem{ID: s.StoreID, Name: s.Name})
+			}
+			return filterListResourceItems(d, items), nil
+		},
+	}
+}
+
+func listResourceSecretStores() *listResource {
+	return &listResource{
+		Schema: listResourceNameSchema("Secret Stores"),
+		List: func(ctx context.Context, d *schema.ResourceData, meta any) ([]listResourceItem, error) {
+			stores, err := listSecretStores(ctx, meta.(*APIClient).conn)
+			if err != nil {
+				return nil, fmt.Errorf("error fetching Secret Stores: %w", err)
+			}
+
+			items := make([]listResourceItem, 0, len(stores))
+			for _, s := range stores {
+				items = append(items, listResourceItem{ID: s.StoreID, Name: s.Name})
+			}
+			return filterListResourceItems(d, items), nil
+		},
+	}
+}
+
+func listResourceNGWAFWorkspaces() *listResource {
+	return &listResource{
+		Schema: listResourceNameSchema("workspaces"),
+		List: func(ctx context.Context, d *schema.ResourceData, meta any) ([]listResourceItem, error) {
+			conn := meta.(*APIClient).conn
+
+			workspaces, err := ws.List(ctx, conn, &ws.ListInput{})
+			if err != nil {
+				return nil, fmt.Errorf("error fetching workspaces: %w", err)
+			}
+
+			items := make([]listResourceItem, 0, len(workspaces.Data))
+			for _, w := range workspaces.Data {
+				items = append(items, listResourceItem{ID: w.WorkspaceID, Name: w.Name})
+			}
+			return filterListResourceItems(d, items), nil
+		},
+	}
+}
+
+func listResourceTLSSubscriptions() *listResource {
+	return &listResource{
+		// The same filters as the fastly_tls_subscription data source, so
+		// that getTLSSubscriptionFilters can be shared.
+		Schema: map[string]*schema.Schema{
+			"certificate_authority": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return subscriptions using this certificate authority.",
+			},
+			"configuration_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return subscriptions using this TLS configuration.",
+			},
+			"domains": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Only return subscriptions covering all of these domains.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+		List: func(ctx context.Context, d *schema.ResourceData, meta any) ([]listResourceItem, error) {
+			subscriptions, err := listTLSSubscriptions(ctx, meta.(*APIClient).conn, getTLSSubscriptionFilters(d)...)
+			if err != nil {
+				return nil, fmt.Errorf("error fetching TLS subscriptions: %w", err)
+			}
+
+			items := make([]listResourceItem, 0, len(subscriptions))
+			for _, s := range subscriptions {
+				name := s.ID
+				if len(s.Domains) > 0 {
+					name = s.Domains[0].ID
+				}
+				items = append(items, listResourceItem{ID: s.ID, Name: name})
+			}
+			return items, nil
+		},
+	}
+}
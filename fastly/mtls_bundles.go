@@ -0,0 +1,133 @@
+package fastly
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// mtlsBundleCertificatesSchema returns the schema of the certificates parsed
+// from a mutual TLS certificate bundle.
+func mtlsBundleCertificatesSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Computed:    true,
+		Description: "The certificates in the bundle, in the order they appear.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"fingerprint": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The hex-encoded SHA-256 fingerprint of the certificate.",
+				},
+				"is_ca": {
+					Type:        schema.TypeBool,
+					Computed:    true,
+					Description: "Whether the certificate is a CA certificate.",
+				},
+				"issuer": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The distinguished name of the certificate's issuer.",
+				},
+				"not_after": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "Timestamp (RFC 3339) after which the certificate is no longer valid.",
+				},
+				"subject": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The distinguished name of the certificate's subject.",
+				},
+			},
+		},
+	}
+}
+
+// certificateFingerprint returns the hex-encoded SHA-256 of the certificate.
+func certificateFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// flattenMTLSBundleCertificates models the certificates of a bundle into a
+// format suitable for saving to Terraform state.
+func flattenMTLSBundleCertificates(certs []*x509.Certificate) []map[string]any {
+	result := make([]map[string]any, 0, len(certs))
+	for _, cert := range certs {
+		result = append(result, map[string]any{
+			"fingerprint": certificateFingerprint(cert),
+			"is_ca":       cert.IsCA,
+			"issuer":      cert.Issuer.String(),
+			"not_after":   cert.NotAfter.UTC().Format(time.RFC3339),
+			"subject":     cert.Subject.String(),
+		})
+	}
+	return result
+}
+
+// checkMTLSBundle returns an error for each certificate in a bundle that is
+// not a CA certificate or has expired, as clients presenting certificates
+// issued by them would be rejected.
+func checkMTLSBundle(certs []*x509.Certificate, now time.Time) error {
+	var errs []error
+	for i, cert := range certs {
+		if !cert.IsCA {
+			errs = append(errs, fmt.Errorf("certificate %d (%s) in cert_bundle is not a CA certificate", i+1, describeCertificate(cert)))
+		}
+		if now.After(cert.NotAfter) {
+			errs = append(errs, fmt.Errorf("certificate %d (%s) in cert_bundle expired at %s", i+1, describeCertificate(cert), cert.NotAfter.UTC().Format(time.RFC3339)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// mergeMTLSBundle merges the certificates in several PEM-encoded bundles into
+// one, dropping duplicates. The certificates are ordered by subject, then by
+// expiry, so the result does not depend on the order of the inputs.
+func mergeMTLSBundle(bundles []string) (string, []*x509.Certificate, error) {
+	seen := map[string]bool{}
+	var certs []*x509.Certificate
+	for i, bundle := range bundles {
+		parsed, err := parseCertificateChain(bundle)
+		if err != nil {
+			return "", nil, fmt.Errorf("bundle %d: %w", i+1, err)
+		}
+		for _, cert := range parsed {
+			fingerprint := certificateFingerprint(cert)
+			if seen[fingerprint] {
+				continue
+			}
+			seen[fingerprint] = true
+			certs = append(certs, cert)
+		}
+	}
+
+	sort.Slice(certs, func(i, j int) bool {
+		if si, sj := certs[i].Subject.String(), certs[j].Subject.String(); si != sj {
+			return si < sj
+		}
+		if !certs[i].NotAfter.Equal(certs[j].NotAfter) {
+			return certs[i].NotAfter.Before(certs[j].NotAfter)
+		}
+		return bytes.Compare(certs[i].Raw, certs[j].Raw) < 0
+	})
+
+	var b strings.Builder
+	for _, cert := range certs {
+		if err := pem.Encode(&b, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}); err != nil {
+			return "", nil, err
+		}
+	}
+	return b.String(), certs, nil
+}
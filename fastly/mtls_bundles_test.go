@@ -0,0 +1,75 @@
+package fastly
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMergeMTLSBundle(t *testing.T) {
+	_, ca1, err := generateKeyAndCert("a.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ca2, err := generateKeyAndCert("b.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bundle, certs, err := mergeMTLSBundle([]string{ca2 + "\n" + ca1, ca1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(certs) != 2 {
+		t.Fatalf("expected 2 certificates, got %d", len(certs))
+	}
+	if got := strings.Count(bundle, "BEGIN CERTIFICATE"); got != 2 {
+		t.Errorf("expected 2 certificates in the bundle, got %d", got)
+	}
+
+	reordered, _, err := mergeMTLSBundle([]string{ca1, ca2, ca2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reordered != bundle {
+		t.Errorf("expected the same bundle regardless of input order, got:\n%s\nand:\n%s", bundle, reordered)
+	}
+
+	if _, _, err := mergeMTLSBundle([]string{ca1, "not a certificate"}); err == nil {
+		t.Error("expected an error for a bundle without certificates")
+	}
+}
+
+func TestCheckMTLSBundle(t *testing.T) {
+	_, leaf, ca, err := generateKeyAndCertWithCA("www.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certs, err := parseCertificateChain(ca)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkMTLSBundle(certs, time.Now()); err != nil {
+		t.Errorf("expected a valid bundle, got %s", err)
+	}
+	if err := checkMTLSBundle(certs, time.Now().AddDate(1, 0, 0)); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("expected an expired certificate error, got %v", err)
+	}
+
+	certs, err = parseCertificateChain(ca + "\n" + leaf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkMTLSBundle(certs, time.Now()); err == nil || !strings.Contains(err.Error(), "certificate 2 (www.example.com) in cert_bundle is not a CA certificate") {
+		t.Errorf("expected a non-CA certificate error, got %v", err)
+	}
+
+	flattened := flattenMTLSBundleCertificates(certs)
+	if flattened[0]["is_ca"] != true || flattened[1]["is_ca"] != false {
+		t.Errorf("unexpected is_ca values in %v", flattened)
+	}
+	if fingerprint := flattened[0]["fingerprint"].(string); len(fingerprint) != 64 {
+		t.Errorf("expected a hex-encoded SHA-256 fingerprint, got %q", fingerprint)
+	}
+}
@@ -0,0 +1,136 @@
+package fastly
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// normalizeNGWAFListEntry trims an entry and writes IP addresses and CIDR
+// ranges in canonical form, so that equivalent entries compare equal.
+// IPv4-mapped IPv6 addresses become IPv4 addresses, host bits of ranges are
+// cleared and single-address ranges become addresses.
+func normalizeNGWAFListEntry(entry string) string {
+	entry = strings.TrimSpace(entry)
+	if addr, err := netip.ParseAddr(entry); err == nil {
+		return addr.Unmap().String()
+	}
+	if prefix, err := netip.ParsePrefix(entry); err == nil {
+		if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+		prefix = prefix.Masked()
+		if prefix.IsSingleIP() {
+			return prefix.Addr().String()
+		}
+		return prefix.String()
+	}
+	return entry
+}
+
+// normalizeNGWAFListEntries normalizes entries, dropping empty entries and
+// duplicates, and returns them sorted.
+func normalizeNGWAFListEntries(entries []string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, entry := range entries {
+		entry = normalizeNGWAFListEntry(entry)
+		if entry == "" || seen[entry] {
+			continue
+		}
+		seen[entry] = true
+		result = append(result, entry)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// readNGWAFListEntriesFile reads the entries of a list from a file with one
+// entry per line. Blank lines and lines starting with # are ignored.
+func readNGWAFListEntriesFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading list entries: %w", err)
+	}
+	defer f.Close()
+
+	var entries []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading list entries from %s: %w", path, err)
+	}
+	return normalizeNGWAFListEntries(entries), nil
+}
+
+// hashNGWAFListEntries returns the hex-encoded SHA-256 of normalized entries.
+func hashNGWAFListEntries(entries []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(entries, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// planNGWAFListEntryUpdates returns the successive entries to send to bring
+// a list from its current to its desired entries, changing at most chunkSize
+// entries at a time. Removals are applied before additions so the list does
+// not grow beyond the larger of its current and desired sizes. Both inputs
+// must be normalized.
+func planNGWAFListEntryUpdates(current, desired []string, chunkSize int) [][]string {
+	want := map[string]bool{}
+	for _, entry := range desired {
+		want[entry] = true
+	}
+	have := map[string]bool{}
+	for _, entry := range current {
+		have[entry] = true
+	}
+
+	type change struct {
+		entry  string
+		remove bool
+	}
+	var changes []change
+	for _, entry := range current {
+		if !want[entry] {
+			changes = append(changes, change{entry, true})
+		}
+	}
+	for _, entry := range desired {
+		if !have[entry] {
+			changes = append(changes, change{entry, false})
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	if chunkSize <= 0 {
+		chunkSize = len(changes)
+	}
+
+	var updates [][]string
+	for chunk := range slices.Chunk(changes, chunkSize) {
+		for _, c := range chunk {
+			have[c.entry] = !c.remove
+		}
+		entries := []string{}
+		for entry, ok := range have {
+			if ok {
+				entries = append(entries, entry)
+			}
+		}
+		sort.Strings(entries)
+		updates = append(updates, entries)
+	}
+	return updates
+}
@@ -0,0 +1,103 @@
+package fastly
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeNGWAFListEntries(t *testing.T) {
+	got := normalizeNGWAFListEntries([]string{
+		" 192.0.2.1 ",
+		"192.0.2.1/32",
+		"::ffff:192.0.2.1",
+		"198.51.100.7/24",
+		"2001:DB8::1",
+		"2001:db8:0::/48",
+		"::ffff:203.0.113.0/120",
+		"*bot*",
+		"",
+		"US",
+	})
+	require.Equal(t, []string{
+		"*bot*",
+		"192.0.2.1",
+		"198.51.100.0/24",
+		"2001:db8::/48",
+		"2001:db8::1",
+		"203.0.113.0/24",
+		"US",
+	}, got)
+}
+
+func TestReadNGWAFListEntriesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "entries.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# blocked addresses\n192.0.2.1\n\n  192.0.2.0/24\n192.0.2.1/32\r\n"), 0o600))
+
+	entries, err := readNGWAFListEntriesFile(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{"192.0.2.0/24", "192.0.2.1"}, entries)
+	require.Equal(t, hashNGWAFListEntries([]string{"192.0.2.0/24", "192.0.2.1"}), hashNGWAFListEntries(entries))
+
+	_, err = readNGWAFListEntriesFile(filepath.Join(t.TempDir(), "missing.txt"))
+	require.ErrorContains(t, err, "error reading list entries")
+}
+
+func TestPlanNGWAFListEntryUpdates(t *testing.T) {
+	current := []string{"a", "b", "c"}
+
+	require.Nil(t, planNGWAFListEntryUpdates(current, []string{"a", "b", "c"}, 2))
+
+	// Removals come first, then additions, at most three changes per update.
+	require.Equal(t, [][]string{
+		{"a", "d"},
+		{"a", "d", "e"},
+	}, planNGWAFListEntryUpdates(current, []string{"a", "d", "e"}, 3))
+
+	// A chunk size covering all changes needs a single update.
+	require.Equal(t, [][]string{
+		{"a", "d", "e"},
+	}, planNGWAFListEntryUpdates(current, []string{"a", "d", "e"}, 10000))
+
+	require.Equal(t, [][]string{
+		{},
+	}, planNGWAFListEntryUpdates(current, nil, 0))
+}
+
+func TestNGWAFListEntriesConfigured(t *testing.T) {
+	config := func(entries cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"name":    cty.StringVal("example"),
+			"entries": entries,
+		})
+	}
+
+	require.True(t, ngwafListEntriesConfigured(config(cty.ListVal([]cty.Value{cty.StringVal("192.0.2.1")}))))
+	require.True(t, ngwafListEntriesConfigured(config(cty.ListValEmpty(cty.String))))
+	require.False(t, ngwafListEntriesConfigured(config(cty.NullVal(cty.List(cty.String)))))
+
+	// Without a configuration, entries are assumed to be managed.
+	require.True(t, ngwafListEntriesConfigured(cty.NullVal(cty.EmptyObject)))
+}
+
+func TestUpgradeNGWAFListStateV0toV1(t *testing.T) {
+	for _, r := range []*schema.Resource{resourceFastlyNGWAFWorkspaceList(), resourceFastlyNGWAFAccountList()} {
+		require.Len(t, r.StateUpgraders, 1)
+		require.False(t, r.StateUpgraders[0].Type.HasAttribute("entries_managed"))
+		require.True(t, r.StateUpgraders[0].Type.HasAttribute("entries"))
+	}
+
+	upgraded, err := upgradeNGWAFListStateV0toV1(context.Background(), map[string]any{
+		"entries": []any{"192.0.2.1"},
+	}, nil)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"entries":         []any{"192.0.2.1"},
+		"entries_managed": true,
+	}, upgraded)
+}
@@ -19,7 +19,10 @@ func resourceFastlyNGWAFRuleCreate(ctx context.Context, d *schema.ResourceData,
 		return diag.FromErr(err)
 	}
 
-	i := expandNGWAFRuleCreateInput(d, rsc.scope)
+	i, err := expandNGWAFRuleCreateInput(d, rsc.scope)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
 	log.Printf("[DEBUG] CREATE: NGWAF %s rule input: %#v", rsc.scope.Type, i)
 
@@ -68,7 +71,10 @@ func resourceFastlyNGWAFRuleUpdate(ctx context.Context, d *schema.ResourceData,
 		return diag.FromErr(err)
 	}
 
-	i := expandNGWAFRuleUpdateInput(d, rsc.scope)
+	i, err := expandNGWAFRuleUpdateInput(d, rsc.scope)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
 	log.Printf("[DEBUG] UPDATE: NGWAF %s rule input: %#v", rsc.scope.Type, i)
 
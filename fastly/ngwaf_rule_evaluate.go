@@ -0,0 +1,347 @@
+package fastly
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ngwafSampleRequest is a request that a rule's conditions are evaluated
+// against locally.
+type ngwafSampleRequest struct {
+	method  string
+	path    string
+	headers map[string]string
+	ip      string
+	country string
+}
+
+// ngwafRuleList holds the entries of a list referenced by in_list and
+// not_in_list conditions.
+type ngwafRuleList struct {
+	listType string
+	entries  []string
+}
+
+// ngwafRuleEvaluator evaluates a rule's conditions against sample requests,
+// without calling the API. Lists are looked up by reference ID.
+type ngwafRuleEvaluator struct {
+	lists       map[string]ngwafRuleList
+	regexps     map[string]*regexp.Regexp
+	unsupported map[string]bool
+}
+
+func newNGWAFRuleEvaluator(lists map[string]ngwafRuleList) *ngwafRuleEvaluator {
+	return &ngwafRuleEvaluator{
+		lists:       lists,
+		regexps:     map[string]*regexp.Regexp{},
+		unsupported: map[string]bool{},
+	}
+}
+
+// unsupportedFields returns the fields that conditions referenced but that
+// can't be evaluated from a sample request. Conditions on them never match.
+func (e *ngwafRuleEvaluator) unsupportedFields() []string {
+	fields := make([]string, 0, len(e.unsupported))
+	for field := range e.unsupported {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// matches reports whether a request matches a rule's conditions.
+func (e *ngwafRuleEvaluator) matches(c *ngwafRuleConditions, req *ngwafSampleRequest) (bool, error) {
+	var items []func() (bool, error)
+	for _, raw := range c.conditions {
+		m := raw.(map[string]any)
+		items = append(items, func() (bool, error) { return e.matchesCondition(m, req) })
+	}
+	for _, raw := range c.multivalConditions {
+		m := raw.(map[string]any)
+		items = append(items, func() (bool, error) { return e.matchesMultival(m, req) })
+	}
+	for _, raw := range c.groupConditions {
+		m := raw.(map[string]any)
+		items = append(items, func() (bool, error) { return e.matchesGroup(m, req) })
+	}
+	return combineNGWAFRuleResults(c.groupOperator, items)
+}
+
+func (e *ngwafRuleEvaluator) matchesGroup(m map[string]any, req *ngwafSampleRequest) (bool, error) {
+	var items []func() (bool, error)
+	if conditions, ok := m["condition"].([]any); ok {
+		for _, raw := range conditions {
+			cm := raw.(map[string]any)
+			items = append(items, func() (bool, error) { return e.matchesCondition(cm, req) })
+		}
+	}
+	if multivals, ok := m["multival_condition"].([]any); ok {
+		for _, raw := range multivals {
+			mm := raw.(map[string]any)
+			items = append(items, func() (bool, error) { return e.matchesMultival(mm, req) })
+		}
+	}
+	return combineNGWAFRuleResults(m["group_operator"].(string), items)
+}
+
+func (e *ngwafRuleEvaluator) matchesCondition(m map[string]any, req *ngwafSampleRequest) (bool, error) {
+	field := m["field"].(string)
+	value, ok := req.field(field)
+	if !ok {
+		e.unsupported[field] = true
+		return false, nil
+	}
+	return e.compare(m["operator"].(string), value, m["value"].(string), false)
+}
+
+// matchesMultival reports whether any (for exists) or no (for
+// does_not_exist) header, cookie or query parameter of the request matches
+// the multival condition's conditions.
+func (e *ngwafRuleEvaluator) matchesMultival(m map[string]any, req *ngwafSampleRequest) (bool, error) {
+	field := m["field"].(string)
+	pairs, ok := req.multivalField(field)
+	if !ok {
+		e.unsupported[field] = true
+		return false, nil
+	}
+
+	// Header names are case-insensitive.
+	foldName := field == "request_header"
+
+	exists := false
+	for _, pair := range pairs {
+		var items []func() (bool, error)
+		for _, raw := range m["condition"].([]any) {
+			cm := raw.(map[string]any)
+			items = append(items, func() (bool, error) {
+				switch f := cm["field"].(string); f {
+				case "name":
+					return e.compare(cm["operator"].(string), pair[0], cm["value"].(string), foldName)
+				case "value", "value_string":
+					return e.compare(cm["operator"].(string), pair[1], cm["value"].(string), false)
+				default:
+					e.unsupported[field+"."+f] = true
+					return false, nil
+				}
+			})
+		}
+		ok, err := combineNGWAFRuleResults(m["group_operator"].(string), items)
+		if err != nil {
+			return false, err
+		}
+		exists = exists || ok
+	}
+
+	switch operator := m["operator"].(string); operator {
+	case "exists":
+		return exists, nil
+	case "does_not_exist":
+		return !exists, nil
+	default:
+		return false, fmt.Errorf("unknown multival operator %q", operator)
+	}
+}
+
+// compare applies an operator to a request's value and a condition's value.
+// If fold is set, equality checks ignore case.
+func (e *ngwafRuleEvaluator) compare(operator, actual, value string, fold bool) (bool, error) {
+	switch operator {
+	case "equals":
+		return actual == value || (fold && strings.EqualFold(actual, value)), nil
+	case "does_not_equal":
+		return !(actual == value || (fold && strings.EqualFold(actual, value))), nil
+	case "contains":
+		return strings.Contains(actual, value), nil
+	case "does_not_contain":
+		return !strings.Contains(actual, value), nil
+	case "like":
+		return matchNGWAFWildcard(value, actual), nil
+	case "not_like":
+		return !matchNGWAFWildcard(value, actual), nil
+	case "matches", "does_not_match":
+		re, ok := e.regexps[value]
+		if !ok {
+			var err error
+			if re, err = regexp.Compile(value); err != nil {
+				return false, fmt.Errorf("invalid regular expression %q: %w", value, err)
+			}
+			e.regexps[value] = re
+		}
+		return re.MatchString(actual) == (operator == "matches"), nil
+	case "greater_equal", "lesser_equal":
+		a, errA := strconv.ParseFloat(actual, 64)
+		v, errV := strconv.ParseFloat(value, 64)
+		if errA != nil || errV != nil {
+			return false, nil
+		}
+		if operator == "greater_equal" {
+			return a >= v, nil
+		}
+		return a <= v, nil
+	case "in_list", "not_in_list":
+		list, ok := e.lists[value]
+		if !ok {
+			return false, fmt.Errorf("list %q is not defined in a list block", value)
+		}
+		return list.contains(actual) == (operator == "in_list"), nil
+	default:
+		return false, fmt.Errorf("unknown operator %q", operator)
+	}
+}
+
+// contains reports whether a value is in the list. Entries of ip lists may
+// be CIDR ranges, and entries of wildcard lists may contain `*`.
+func (l ngwafRuleList) contains(value string) bool {
+	for _, entry := range l.entries {
+		switch l.listType {
+		case "ip":
+			addr, err := netip.ParseAddr(value)
+			if err != nil {
+				continue
+			}
+			if prefix, err := netip.ParsePrefix(entry); err == nil {
+				if prefix.Contains(addr.Unmap()) {
+					return true
+				}
+			} else if entryAddr, err := netip.ParseAddr(entry); err == nil && entryAddr.Unmap() == addr.Unmap() {
+				return true
+			}
+		case "wildcard":
+			if matchNGWAFWildcard(entry, value) {
+				return true
+			}
+		case "country":
+			if strings.EqualFold(entry, value) {
+				return true
+			}
+		default:
+			if entry == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// combineNGWAFRuleResults combines the results of a rule's conditions with
+// a group operator. Every condition is evaluated, so that errors are reported
+// whatever the outcome.
+func combineNGWAFRuleResults(groupOperator string, items []func() (bool, error)) (bool, error) {
+	anyMatched, allMatched := false, true
+	var errs []error
+	for _, item := range items {
+		ok, err := item()
+		if err != nil {
+			errs = append(errs, err)
+		}
+		anyMatched = anyMatched || ok
+		allMatched = allMatched && ok
+	}
+	if err := errors.Join(errs...); err != nil {
+		return false, err
+	}
+	if groupOperator == "any" {
+		return anyMatched, nil
+	}
+	return allMatched, nil
+}
+
+// matchNGWAFWildcard reports whether s matches pattern, in which `*` matches
+// any sequence of characters.
+func matchNGWAFWildcard(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
+
+// header returns the value of a request header, ignoring the case of its
+// name.
+func (r *ngwafSampleRequest) header(name string) (string, bool) {
+	for k, v := range r.headers {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// field returns the value of a single condition's field for the request, or
+// false if it can't be determined from a sample request.
+func (r *ngwafSampleRequest) field(name string) (string, bool) {
+	path, query, _ := strings.Cut(r.path, "?")
+	switch name {
+	case "country":
+		return r.country, true
+	case "domain":
+		host, _ := r.header("Host")
+		if h, _, ok := strings.Cut(host, ":"); ok && !strings.Contains(h, "[") {
+			host = h
+		}
+		return host, true
+	case "ip":
+		return r.ip, true
+	case "method":
+		return r.method, true
+	case "path":
+		return path, true
+	case "query_string":
+		return query, true
+	case "uri":
+		return r.path, true
+	case "user_agent":
+		ua, _ := r.header("User-Agent")
+		return ua, true
+	}
+	return "", false
+}
+
+// multivalField returns the name and value pairs of a multival condition's
+// field for the request, or false if it can't be determined from a sample
+// request.
+func (r *ngwafSampleRequest) multivalField(name string) ([][2]string, bool) {
+	var pairs [][2]string
+	switch name {
+	case "request_header":
+		for k, v := range r.headers {
+			pairs = append(pairs, [2]string{k, v})
+		}
+	case "request_cookie":
+		if cookie, ok := r.header("Cookie"); ok {
+			cookies, _ := http.ParseCookie(cookie)
+			for _, c := range cookies {
+				pairs = append(pairs, [2]string{c.Name, c.Value})
+			}
+		}
+	case "query_parameter":
+		_, query, _ := strings.Cut(r.path, "?")
+		values, _ := url.ParseQuery(query)
+		for k, vs := range values {
+			for _, v := range vs {
+				pairs = append(pairs, [2]string{k, v})
+			}
+		}
+	default:
+		return nil, false
+	}
+	return pairs, true
+}
@@ -0,0 +1,82 @@
+package fastly
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNGWAFRuleEvaluator(t *testing.T) {
+	lists := map[string]ngwafRuleList{
+		"site.blocked":   {listType: "ip", entries: []string{"192.0.2.0/24", "2001:db8::1"}},
+		"site.bots":      {listType: "wildcard", entries: []string{"*bot*"}},
+		"site.countries": {listType: "country", entries: []string{"KP"}},
+	}
+
+	for _, tc := range []struct {
+		expr string
+		req  ngwafSampleRequest
+		want bool
+	}{
+		{
+			expr: `ip in list("site.blocked") and (path ~ "^/admin" or method == "DELETE")`,
+			req:  ngwafSampleRequest{method: "DELETE", path: "/api", ip: "192.0.2.10"},
+			want: true,
+		},
+		{
+			expr: `ip in list("site.blocked") and (path ~ "^/admin" or method == "DELETE")`,
+			req:  ngwafSampleRequest{method: "GET", path: "/api", ip: "192.0.2.10"},
+			want: false,
+		},
+		{
+			expr: `ip not in list("site.blocked") or country in list("site.countries")`,
+			req:  ngwafSampleRequest{ip: "2001:db8::1", country: "kp"},
+			want: true,
+		},
+		{
+			expr: `user_agent in list("site.bots") and domain == "example.com"`,
+			req:  ngwafSampleRequest{headers: map[string]string{"user-agent": "Googlebot/2.1", "Host": "example.com:443"}},
+			want: true,
+		},
+		{
+			expr: `path like "/api/*/users" and query_string contains "debug"`,
+			req:  ngwafSampleRequest{path: "/api/v1/users?debug=1"},
+			want: true,
+		},
+		{
+			expr: `request_header exists (name == "x-api-key" and value_string != "secret")`,
+			req:  ngwafSampleRequest{headers: map[string]string{"X-API-Key": "guess"}},
+			want: true,
+		},
+		{
+			expr: `request_header not exists (name == "X-API-Key")`,
+			req:  ngwafSampleRequest{headers: map[string]string{"X-API-Key": "guess"}},
+			want: false,
+		},
+		{
+			expr: `request_cookie exists (name == "session") or query_parameter exists (name == "token" and value like "a*")`,
+			req:  ngwafSampleRequest{path: "/?token=abc"},
+			want: true,
+		},
+	} {
+		c, err := parseNGWAFRuleExpression(tc.expr)
+		require.NoError(t, err, tc.expr)
+
+		e := newNGWAFRuleEvaluator(lists)
+		got, err := e.matches(c, &tc.req)
+		require.NoError(t, err, tc.expr)
+		require.Equal(t, tc.want, got, tc.expr)
+		require.Empty(t, e.unsupportedFields(), tc.expr)
+	}
+}
+
+func TestNGWAFRuleEvaluatorErrors(t *testing.T) {
+	e := newNGWAFRuleEvaluator(nil)
+
+	c, err := parseNGWAFRuleExpression(`path ~ "(" or ip in list("site.missing") or agent_name == "host-001"`)
+	require.NoError(t, err)
+	_, err = e.matches(c, &ngwafSampleRequest{path: "/"})
+	require.ErrorContains(t, err, `invalid regular expression "("`)
+	require.ErrorContains(t, err, `list "site.missing" is not defined in a list block`)
+	require.Equal(t, []string{"agent_name"}, e.unsupportedFields())
+}
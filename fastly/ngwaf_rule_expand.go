@@ -1,6 +1,8 @@
 package fastly
 
 import (
+	"fmt"
+
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
 	gofastly "github.com/fastly/go-fastly/v17/fastly"
@@ -8,25 +10,42 @@ import (
 	"github.com/fastly/go-fastly/v17/fastly/ngwaf/v1/scope"
 )
 
-func expandNGWAFRuleCreateInput(d *schema.ResourceData, s *scope.Scope) *rules.CreateInput {
-	var actionRaw []any
-	if v, ok := d.GetOk("action"); ok {
-		actionRaw = v.([]any)
+// expandNGWAFRuleConditions returns the rule's conditions in the shape of the
+// condition, group_condition and multival_condition blocks, compiling the
+// expression if one is set.
+func expandNGWAFRuleConditions(d *schema.ResourceData) (*ngwafRuleConditions, error) {
+	if v, ok := d.GetOk("expression"); ok {
+		c, err := parseNGWAFRuleExpression(v.(string))
+		if err != nil {
+			return nil, fmt.Errorf("invalid expression at %w", err)
+		}
+		return c, nil
 	}
 
-	var conditionRaw []any
+	c := &ngwafRuleConditions{
+		groupOperator: d.Get("group_operator").(string),
+	}
 	if v, ok := d.GetOk("condition"); ok {
-		conditionRaw = v.([]any)
+		c.conditions = v.([]any)
 	}
-
-	var groupRaw []any
 	if v, ok := d.GetOk("group_condition"); ok {
-		groupRaw = v.([]any)
+		c.groupConditions = v.([]any)
 	}
-
-	var multivalRaw []any
 	if v, ok := d.GetOk("multival_condition"); ok {
-		multivalRaw = v.([]any)
+		c.multivalConditions = v.([]any)
+	}
+	return c, nil
+}
+
+func expandNGWAFRuleCreateInput(d *schema.ResourceData, s *scope.Scope) (*rules.CreateInput, error) {
+	var actionRaw []any
+	if v, ok := d.GetOk("action"); ok {
+		actionRaw = v.([]any)
+	}
+
+	conditions, err := expandNGWAFRuleConditions(d)
+	if err != nil {
+		return nil, err
 	}
 
 	var rateLimitRaw []any
@@ -39,35 +58,25 @@ func expandNGWAFRuleCreateInput(d *schema.ResourceData, s *scope.Scope) *rules.C
 		Description:        gofastly.ToPointer(d.Get("description").(string)),
 		Scope:              s,
 		Enabled:            gofastly.ToPointer(d.Get("enabled").(bool)),
-		GroupOperator:      gofastly.ToPointer(d.Get("group_operator").(string)),
+		GroupOperator:      gofastly.ToPointer(conditions.groupOperator),
 		RequestLogging:     gofastly.ToPointer(d.Get("request_logging").(string)),
 		Actions:            expandNGWAFRuleCreateActions(actionRaw, string(s.Type)),
-		Conditions:         expandNGWAFRuleCreateConditions(conditionRaw),
-		GroupConditions:    expandNGWAFRuleGroupCreateConditions(groupRaw),
-		MultivalConditions: expandNGWAFRuleMultiValCreateConditions(multivalRaw),
+		Conditions:         expandNGWAFRuleCreateConditions(conditions.conditions),
+		GroupConditions:    expandNGWAFRuleGroupCreateConditions(conditions.groupConditions),
+		MultivalConditions: expandNGWAFRuleMultiValCreateConditions(conditions.multivalConditions),
 		RateLimit:          expandNGWAFRuleCreateRateLimit(rateLimitRaw),
-	}
+	}, nil
 }
 
-func expandNGWAFRuleUpdateInput(d *schema.ResourceData, s *scope.Scope) *rules.UpdateInput {
+func expandNGWAFRuleUpdateInput(d *schema.ResourceData, s *scope.Scope) (*rules.UpdateInput, error) {
 	var actionRaw []any
 	if v, ok := d.GetOk("action"); ok {
 		actionRaw = v.([]any)
 	}
 
-	var conditionRaw []any
-	if v, ok := d.GetOk("condition"); ok {
-		conditionRaw = v.([]any)
-	}
-
-	var groupRaw []any
-	if v, ok := d.GetOk("group_condition"); ok {
-		groupRaw = v.([]any)
-	}
-
-	var multivalRaw []any
-	if v, ok := d.GetOk("multival_condition"); ok {
-		multivalRaw = v.([]any)
+	conditions, err := expandNGWAFRuleConditions(d)
+	if err != nil {
+		return nil, err
 	}
 
 	var rateLimitRaw []any
@@ -81,11 +90,11 @@ func expandNGWAFRuleUpdateInput(d *schema.ResourceData, s *scope.Scope) *rules.U
 		Type:               gofastly.ToPointer(d.Get("type").(string)),
 		Description:        gofastly.ToPointer(d.Get("description").(string)),
 		Enabled:            gofastly.ToPointer(d.Get("enabled").(bool)),
-		GroupOperator:      gofastly.ToPointer(d.Get("group_operator").(string)),
+		GroupOperator:      gofastly.ToPointer(conditions.groupOperator),
 		RequestLogging:     gofastly.ToPointer(d.Get("request_logging").(string)),
-		Conditions:         expandNGWAFRuleUpdateConditions(conditionRaw),
-		GroupConditions:    expandNGWAFRuleGroupUpdateConditions(groupRaw),
-		MultivalConditions: expandNGWAFRuleMultiValUpdateConditions(multivalRaw),
+		Conditions:         expandNGWAFRuleUpdateConditions(conditions.conditions),
+		GroupConditions:    expandNGWAFRuleGroupUpdateConditions(conditions.groupConditions),
+		MultivalConditions: expandNGWAFRuleMultiValUpdateConditions(conditions.multivalConditions),
 		RateLimit:          expandNGWAFRuleUpdateRateLimit(rateLimitRaw),
 	}
 
@@ -94,7 +103,7 @@ func expandNGWAFRuleUpdateInput(d *schema.ResourceData, s *scope.Scope) *rules.U
 		updateInput.Actions = expandNGWAFRuleUpdateActions(actionRaw, string(s.Type))
 	}
 
-	return updateInput
+	return updateInput, nil
 }
 
 func expandNGWAFRuleCreateActions(raw []any, scopeType string) []*rules.CreateAction {
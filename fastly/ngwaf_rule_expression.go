@@ -0,0 +1,553 @@
+package fastly
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// An NGWAF rule expression is a compact way of writing a rule's conditions,
+// for example:
+//
+//	ip in list("site.blocked") and (path ~ "^/admin" or method == "DELETE")
+//
+// Comparisons joined at the top level become the rule's condition blocks,
+// joined by the rule's group_operator. A parenthesized list of comparisons
+// becomes a group_condition, and `field exists (...)` becomes a
+// multival_condition. As the API only supports these two levels, groups
+// can't be nested, and `and` and `or` can't be mixed at the same level.
+
+// ngwafRuleOperators maps the expression syntax of each comparison operator
+// to the operator used by the API.
+var ngwafRuleOperators = []struct {
+	syntax   string
+	operator string
+}{
+	{"==", "equals"},
+	{"!=", "does_not_equal"},
+	{"contains", "contains"},
+	{"not contains", "does_not_contain"},
+	{"like", "like"},
+	{"not like", "not_like"},
+	{"in list", "in_list"},
+	{"not in list", "not_in_list"},
+	{"~", "matches"},
+	{"!~", "does_not_match"},
+	{">=", "greater_equal"},
+	{"<=", "lesser_equal"},
+}
+
+// ngwafRuleConditions holds a rule's conditions in the shape of the
+// condition, group_condition and multival_condition blocks.
+type ngwafRuleConditions struct {
+	groupOperator      string
+	conditions         []any
+	groupConditions    []any
+	multivalConditions []any
+}
+
+// ngwafRuleExpressionError is a syntax error in a rule expression, at a
+// 1-based line and column.
+type ngwafRuleExpressionError struct {
+	line   int
+	column int
+	msg    string
+}
+
+func (e *ngwafRuleExpressionError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.line, e.column, e.msg)
+}
+
+type ngwafRuleTokenKind int
+
+const (
+	ngwafRuleTokenEOF ngwafRuleTokenKind = iota
+	ngwafRuleTokenIdent
+	ngwafRuleTokenNumber
+	ngwafRuleTokenString
+	ngwafRuleTokenSymbol
+)
+
+type ngwafRuleToken struct {
+	kind ngwafRuleTokenKind
+	// text is the identifier, number or symbol, or the unquoted string.
+	text   string
+	line   int
+	column int
+}
+
+func (t ngwafRuleToken) String() string {
+	switch t.kind {
+	case ngwafRuleTokenEOF:
+		return "end of expression"
+	case ngwafRuleTokenString:
+		return "string " + strconv.Quote(t.text)
+	default:
+		return strconv.Quote(t.text)
+	}
+}
+
+func isNGWAFRuleIdentByte(c byte, first bool) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (!first && c >= '0' && c <= '9')
+}
+
+// lexNGWAFRuleExpression splits a rule expression into tokens.
+func lexNGWAFRuleExpression(expr string) ([]ngwafRuleToken, error) {
+	var tokens []ngwafRuleToken
+	line, column := 1, 1
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		start := i
+		tok := ngwafRuleToken{line: line, column: column}
+
+		switch {
+		case c == '\n':
+			i++
+			line, column = line+1, 1
+			continue
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+			column++
+			continue
+		case isNGWAFRuleIdentByte(c, true):
+			for i < len(expr) && isNGWAFRuleIdentByte(expr[i], false) {
+				i++
+			}
+			tok.kind, tok.text = ngwafRuleTokenIdent, expr[start:i]
+		case c >= '0' && c <= '9' || c == '-':
+			i++
+			for i < len(expr) && (expr[i] >= '0' && expr[i] <= '9' || expr[i] == '.') {
+				i++
+			}
+			tok.kind, tok.text = ngwafRuleTokenNumber, expr[start:i]
+			if tok.text == "-" {
+				return nil, &ngwafRuleExpressionError{line, column, `unexpected "-"`}
+			}
+		case c == '"':
+			// Only \" and \\ are escapes, so that regular expressions such
+			// as "^/api/\d+" can be written without doubling backslashes.
+			var b strings.Builder
+			for i++; i < len(expr) && expr[i] != '"'; i++ {
+				if expr[i] == '\\' && i+1 < len(expr) && (expr[i+1] == '"' || expr[i+1] == '\\') {
+					i++
+				}
+				b.WriteByte(expr[i])
+			}
+			if i >= len(expr) {
+				return nil, &ngwafRuleExpressionError{line, column, "unterminated string"}
+			}
+			i++
+			tok.kind, tok.text = ngwafRuleTokenString, b.String()
+		case c == '(' || c == ')' || c == '~':
+			i++
+			tok.kind, tok.text = ngwafRuleTokenSymbol, expr[start:i]
+		case c == '=' || c == '!' || c == '>' || c == '<':
+			i++
+			if i < len(expr) && (expr[i] == '=' || (c == '!' && expr[i] == '~')) {
+				i++
+			}
+			tok.kind, tok.text = ngwafRuleTokenSymbol, expr[start:i]
+			if tok.text == "=" || tok.text == "!" || tok.text == ">" || tok.text == "<" {
+				return nil, &ngwafRuleExpressionError{line, column, fmt.Sprintf("unknown operator %q", tok.text)}
+			}
+		default:
+			r, _ := utf8.DecodeRuneInString(expr[i:])
+			return nil, &ngwafRuleExpressionError{line, column, fmt.Sprintf("unexpected character %q", r)}
+		}
+
+		if n := strings.LastIndexByte(expr[start:i], '\n'); n >= 0 {
+			line += strings.Count(expr[start:i], "\n")
+			column = utf8.RuneCountInString(expr[start+n+1:i]) + 1
+		} else {
+			column += utf8.RuneCountInString(expr[start:i])
+		}
+		tokens = append(tokens, tok)
+	}
+
+	return append(tokens, ngwafRuleToken{kind: ngwafRuleTokenEOF, line: line, column: column}), nil
+}
+
+type ngwafRuleParser struct {
+	tokens []ngwafRuleToken
+	pos    int
+}
+
+func (p *ngwafRuleParser) peek() ngwafRuleToken {
+	return p.tokens[p.pos]
+}
+
+func (p *ngwafRuleParser) peekAt(n int) ngwafRuleToken {
+	if p.pos+n >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+n]
+}
+
+func (p *ngwafRuleParser) next() ngwafRuleToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != ngwafRuleTokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *ngwafRuleParser) errorf(tok ngwafRuleToken, format string, args ...any) error {
+	return &ngwafRuleExpressionError{tok.line, tok.column, fmt.Sprintf(format, args...)}
+}
+
+func (p *ngwafRuleParser) is(tok ngwafRuleToken, kind ngwafRuleTokenKind, text string) bool {
+	return tok.kind == kind && tok.text == text
+}
+
+func (p *ngwafRuleParser) expect(kind ngwafRuleTokenKind, text string) error {
+	if tok := p.next(); !p.is(tok, kind, text) {
+		return p.errorf(tok, "expected %q, got %s", text, tok)
+	}
+	return nil
+}
+
+// parseList parses items joined by `and` or `or`, returning the matching
+// group operator.
+func (p *ngwafRuleParser) parseList(item func() error) (string, error) {
+	groupOperator := ""
+	for {
+		if err := item(); err != nil {
+			return "", err
+		}
+
+		tok := p.peek()
+		if tok.kind != ngwafRuleTokenIdent || (tok.text != "and" && tok.text != "or") {
+			break
+		}
+		p.next()
+
+		op := "all"
+		if tok.text == "or" {
+			op = "any"
+		}
+		if groupOperator != "" && groupOperator != op {
+			return "", p.errorf(tok, "cannot mix \"and\" and \"or\" at the same level, use parentheses to group conditions")
+		}
+		groupOperator = op
+	}
+
+	if groupOperator == "" {
+		groupOperator = "all"
+	}
+	return groupOperator, nil
+}
+
+// parseCondition parses a comparison, or a multival condition when
+// allowMultival is set.
+func (p *ngwafRuleParser) parseCondition(allowMultival bool) (condition map[string]any, multival bool, err error) {
+	field := p.next()
+	if field.kind != ngwafRuleTokenIdent || field.text == "and" || field.text == "or" || field.text == "not" {
+		return nil, false, p.errorf(field, "expected a field name, got %s", field)
+	}
+
+	tok := p.peek()
+	if p.is(tok, ngwafRuleTokenIdent, "exists") || (p.is(tok, ngwafRuleTokenIdent, "not") && p.is(p.peekAt(1), ngwafRuleTokenIdent, "exists")) {
+		if !allowMultival {
+			return nil, false, p.errorf(tok, "multival conditions cannot be nested")
+		}
+		operator := "exists"
+		if p.next().text == "not" {
+			p.next()
+			operator = "does_not_exist"
+		}
+
+		if err := p.expect(ngwafRuleTokenSymbol, "("); err != nil {
+			return nil, false, err
+		}
+		var conditions []any
+		groupOperator, err := p.parseList(func() error {
+			if tok := p.peek(); p.is(tok, ngwafRuleTokenSymbol, "(") {
+				return p.errorf(tok, "conditions of a multival condition cannot be grouped")
+			}
+			c, _, err := p.parseCondition(false)
+			if err != nil {
+				return err
+			}
+			conditions = append(conditions, c)
+			return nil
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		if err := p.expect(ngwafRuleTokenSymbol, ")"); err != nil {
+			return nil, false, err
+		}
+
+		return map[string]any{
+			"field":          field.text,
+			"operator":       operator,
+			"group_operator": groupOperator,
+			"condition":      conditions,
+		}, true, nil
+	}
+
+	operator, err := p.parseOperator()
+	if err != nil {
+		return nil, false, err
+	}
+
+	inList := operator == "in_list" || operator == "not_in_list"
+	if inList {
+		if err := p.expect(ngwafRuleTokenSymbol, "("); err != nil {
+			return nil, false, err
+		}
+	}
+	value := p.next()
+	if value.kind != ngwafRuleTokenString && value.kind != ngwafRuleTokenNumber {
+		return nil, false, p.errorf(value, "expected a quoted value, got %s", value)
+	}
+	if inList {
+		if err := p.expect(ngwafRuleTokenSymbol, ")"); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return map[string]any{
+		"field":    field.text,
+		"operator": operator,
+		"value":    value.text,
+	}, false, nil
+}
+
+// parseOperator parses a comparison operator, returning the API operator.
+func (p *ngwafRuleParser) parseOperator() (string, error) {
+	start := p.peek()
+	var words []string
+	for {
+		tok := p.next()
+		if tok.kind != ngwafRuleTokenSymbol && tok.kind != ngwafRuleTokenIdent {
+			if len(words) > 0 {
+				return "", p.errorf(start, "incomplete operator %q", strings.Join(words, " "))
+			}
+			return "", p.errorf(start, "expected an operator, got %s", start)
+		}
+		words = append(words, tok.text)
+
+		syntax := strings.Join(words, " ")
+		prefix := false
+		for _, op := range ngwafRuleOperators {
+			if op.syntax == syntax {
+				return op.operator, nil
+			}
+			if strings.HasPrefix(op.syntax, syntax+" ") {
+				prefix = true
+			}
+		}
+		if !prefix {
+			return "", p.errorf(start, "expected an operator, got %q", syntax)
+		}
+	}
+}
+
+// parseNGWAFRuleExpression compiles a rule expression into the shape of the
+// condition, group_condition and multival_condition blocks.
+func parseNGWAFRuleExpression(expr string) (*ngwafRuleConditions, error) {
+	tokens, err := lexNGWAFRuleExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &ngwafRuleParser{tokens: tokens}
+
+	c := &ngwafRuleConditions{}
+	c.groupOperator, err = p.parseList(func() error {
+		tok := p.peek()
+		if !p.is(tok, ngwafRuleTokenSymbol, "(") {
+			condition, multival, err := p.parseCondition(true)
+			if err != nil {
+				return err
+			}
+			if multival {
+				c.multivalConditions = append(c.multivalConditions, condition)
+			} else {
+				c.conditions = append(c.conditions, condition)
+			}
+			return nil
+		}
+
+		p.next()
+		var conditions, multivalConditions []any
+		groupOperator, err := p.parseList(func() error {
+			if tok := p.peek(); p.is(tok, ngwafRuleTokenSymbol, "(") {
+				return p.errorf(tok, "groups cannot be nested")
+			}
+			condition, multival, err := p.parseCondition(true)
+			if err != nil {
+				return err
+			}
+			if multival {
+				multivalConditions = append(multivalConditions, condition)
+			} else {
+				conditions = append(conditions, condition)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := p.expect(ngwafRuleTokenSymbol, ")"); err != nil {
+			return err
+		}
+
+		group := map[string]any{
+			"group_operator": groupOperator,
+		}
+		if len(conditions) > 0 {
+			group["condition"] = conditions
+		}
+		if len(multivalConditions) > 0 {
+			group["multival_condition"] = multivalConditions
+		}
+		c.groupConditions = append(c.groupConditions, group)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != ngwafRuleTokenEOF {
+		return nil, p.errorf(tok, "expected \"and\" or \"or\", got %s", tok)
+	}
+
+	return c, nil
+}
+
+// formatNGWAFRuleExpression renders a rule's conditions as a canonical
+// expression: comparisons first, then multival conditions, then groups.
+func formatNGWAFRuleExpression(c *ngwafRuleConditions) (string, error) {
+	return formatNGWAFRuleConditions(c, false)
+}
+
+// formatNGWAFRuleConditions renders a rule's conditions like
+// formatNGWAFRuleExpression. When sorted is set, the items joined at each level
+// are sorted so that the result does not depend on the order of the conditions.
+func formatNGWAFRuleConditions(c *ngwafRuleConditions, sorted bool) (string, error) {
+	var items []string
+	for _, raw := range c.conditions {
+		s, err := formatNGWAFRuleComparison(raw)
+		if err != nil {
+			return "", err
+		}
+		items = append(items, s)
+	}
+	for _, raw := range c.multivalConditions {
+		s, err := formatNGWAFRuleMultival(raw, sorted)
+		if err != nil {
+			return "", err
+		}
+		items = append(items, s)
+	}
+	for _, raw := range c.groupConditions {
+		m := raw.(map[string]any)
+		var groupItems []string
+		if conditions, ok := m["condition"].([]any); ok {
+			for _, raw := range conditions {
+				s, err := formatNGWAFRuleComparison(raw)
+				if err != nil {
+					return "", err
+				}
+				groupItems = append(groupItems, s)
+			}
+		}
+		if multivals, ok := m["multival_condition"].([]any); ok {
+			for _, raw := range multivals {
+				s, err := formatNGWAFRuleMultival(raw, sorted)
+				if err != nil {
+					return "", err
+				}
+				groupItems = append(groupItems, s)
+			}
+		}
+		s, err := joinNGWAFRuleExpression(m["group_operator"].(string), groupItems, sorted)
+		if err != nil {
+			return "", err
+		}
+		items = append(items, "("+s+")")
+	}
+
+	return joinNGWAFRuleExpression(c.groupOperator, items, sorted)
+}
+
+func joinNGWAFRuleExpression(groupOperator string, items []string, sorted bool) (string, error) {
+	if sorted {
+		items = append([]string(nil), items...)
+		sort.Strings(items)
+	}
+	switch groupOperator {
+	case "", "all":
+		return strings.Join(items, " and "), nil
+	case "any":
+		return strings.Join(items, " or "), nil
+	}
+	return "", fmt.Errorf("unknown group operator %q", groupOperator)
+}
+
+func formatNGWAFRuleComparison(raw any) (string, error) {
+	m := raw.(map[string]any)
+	field, operator, value := m["field"].(string), m["operator"].(string), quoteNGWAFRuleValue(m["value"].(string))
+	for _, op := range ngwafRuleOperators {
+		if op.operator != operator {
+			continue
+		}
+		if operator == "in_list" || operator == "not_in_list" {
+			return fmt.Sprintf("%s %s(%s)", field, op.syntax, value), nil
+		}
+		return fmt.Sprintf("%s %s %s", field, op.syntax, value), nil
+	}
+	return "", fmt.Errorf("operator %q of field %q has no expression syntax", operator, field)
+}
+
+// quoteNGWAFRuleValue quotes a value, escaping only quotes and backslashes.
+func quoteNGWAFRuleValue(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func formatNGWAFRuleMultival(raw any, sorted bool) (string, error) {
+	m := raw.(map[string]any)
+	var items []string
+	for _, raw := range m["condition"].([]any) {
+		s, err := formatNGWAFRuleComparison(raw)
+		if err != nil {
+			return "", err
+		}
+		items = append(items, s)
+	}
+	s, err := joinNGWAFRuleExpression(m["group_operator"].(string), items, sorted)
+	if err != nil {
+		return "", err
+	}
+
+	switch operator := m["operator"].(string); operator {
+	case "exists":
+		return fmt.Sprintf("%s exists (%s)", m["field"], s), nil
+	case "does_not_exist":
+		return fmt.Sprintf("%s not exists (%s)", m["field"], s), nil
+	default:
+		return "", fmt.Errorf("multival operator %q of field %q has no expression syntax", operator, m["field"])
+	}
+}
+
+// ngwafRuleExpressionsEquivalent reports whether two rule expressions compile
+// to the same conditions, in any order.
+func ngwafRuleExpressionsEquivalent(a, b string) bool {
+	ca, err := parseNGWAFRuleExpression(a)
+	if err != nil {
+		return false
+	}
+	cb, err := parseNGWAFRuleExpression(b)
+	if err != nil {
+		return false
+	}
+	fa, err := formatNGWAFRuleConditions(ca, true)
+	if err != nil {
+		return false
+	}
+	fb, err := formatNGWAFRuleConditions(cb, true)
+	return err == nil && fa == fb
+}
@@ -0,0 +1,116 @@
+package fastly
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNGWAFRuleExpression(t *testing.T) {
+	c, err := parseNGWAFRuleExpression(`ip in list("site.blocked") and (path ~ "^/admin" or method == "DELETE")`)
+	require.NoError(t, err)
+	require.Equal(t, "all", c.groupOperator)
+	require.Equal(t, []any{
+		map[string]any{"field": "ip", "operator": "in_list", "value": "site.blocked"},
+	}, c.conditions)
+	require.Equal(t, []any{
+		map[string]any{
+			"group_operator": "any",
+			"condition": []any{
+				map[string]any{"field": "path", "operator": "matches", "value": "^/admin"},
+				map[string]any{"field": "method", "operator": "equals", "value": "DELETE"},
+			},
+		},
+	}, c.groupConditions)
+	require.Nil(t, c.multivalConditions)
+
+	c, err = parseNGWAFRuleExpression(`request_header not exists (name == "X-API-Key" or value contains "test") or response_code >= 500`)
+	require.NoError(t, err)
+	require.Equal(t, "any", c.groupOperator)
+	require.Equal(t, []any{
+		map[string]any{"field": "response_code", "operator": "greater_equal", "value": "500"},
+	}, c.conditions)
+	require.Equal(t, []any{
+		map[string]any{
+			"field":          "request_header",
+			"operator":       "does_not_exist",
+			"group_operator": "any",
+			"condition": []any{
+				map[string]any{"field": "name", "operator": "equals", "value": "X-API-Key"},
+				map[string]any{"field": "value", "operator": "contains", "value": "test"},
+			},
+		},
+	}, c.multivalConditions)
+}
+
+func TestFormatNGWAFRuleExpression(t *testing.T) {
+	for _, tc := range []struct {
+		expr string
+		want string
+	}{
+		{
+			expr: `ip in list("site.blocked") and (path ~ "^/admin" or method == "DELETE")`,
+			want: `ip in list("site.blocked") and (path ~ "^/admin" or method == "DELETE")`,
+		},
+		{
+			// Groups and multival conditions are moved after comparisons.
+			expr: "(country == \"AD\")\n  and request_cookie exists (name like \"sess*\")\n  and ip not in list(\"corp.allow\")",
+			want: `ip not in list("corp.allow") and request_cookie exists (name like "sess*") and (country == "AD")`,
+		},
+		{
+			expr: `path ~ "^/api/\d+" and user_agent not contains "say \"hi\"" and response_code <= 404`,
+			want: `path ~ "^/api/\\d+" and user_agent not contains "say \"hi\"" and response_code <= "404"`,
+		},
+	} {
+		c, err := parseNGWAFRuleExpression(tc.expr)
+		require.NoError(t, err, tc.expr)
+		got, err := formatNGWAFRuleExpression(c)
+		require.NoError(t, err, tc.expr)
+		require.Equal(t, tc.want, got)
+		require.True(t, ngwafRuleExpressionsEquivalent(tc.expr, got), tc.expr)
+	}
+}
+
+func TestNGWAFRuleExpressionsEquivalent(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want bool
+	}{
+		{`path == "/a" and method == "GET"`, `method == "GET"  and path == "/a"`, true},
+		{
+			`(method == "GET" or path ~ "^/a") and request_header exists (value == "b" and name == "a")`,
+			`request_header exists (name == "a" and value == "b") and (path ~ "^/a" or method == "GET")`,
+			true,
+		},
+		{`path == "/a" and method == "GET"`, `path == "/a" or method == "GET"`, false},
+		{`path == "/a" and method == "GET"`, `path == "/a" and method == "POST"`, false},
+		{`path == "/a"`, `path ==`, false},
+	} {
+		require.Equal(t, tc.want, ngwafRuleExpressionsEquivalent(tc.a, tc.b), "%s / %s", tc.a, tc.b)
+		require.Equal(t, tc.want, ngwafRuleExpressionsEquivalent(tc.b, tc.a), "%s / %s", tc.b, tc.a)
+	}
+}
+
+func TestParseNGWAFRuleExpressionErrors(t *testing.T) {
+	for _, tc := range []struct {
+		expr string
+		want string
+	}{
+		{``, `1:1: expected a field name, got end of expression`},
+		{`path == "/a" and method == "GET" or ip == "1.2.3.4"`, `1:34: cannot mix "and" and "or" at the same level, use parentheses to group conditions`},
+		{`path = "/a"`, `1:6: unknown operator "="`},
+		{`path is "/a"`, `1:6: expected an operator, got "is"`},
+		{`path not "/a"`, `1:6: incomplete operator "not"`},
+		{`path "/a"`, `1:6: expected an operator, got string "/a"`},
+		{`path == /a`, `1:9: unexpected character '/'`},
+		{`path == "/a`, `1:9: unterminated string`},
+		{`ip in list(blocked)`, `1:12: expected a quoted value, got "blocked"`},
+		{`(path == "/a" and (method == "GET"))`, `1:19: groups cannot be nested`},
+		{"path == \"/a\" and\n  (method == \"GET\"", `2:19: expected ")", got end of expression`},
+		{`request_header exists (name == "a" and value exists (x == "b"))`, `1:46: multival conditions cannot be nested`},
+		{`path == "/a" method == "GET"`, `1:14: expected "and" or "or", got "method"`},
+	} {
+		_, err := parseNGWAFRuleExpression(tc.expr)
+		require.EqualError(t, err, tc.want, tc.expr)
+	}
+}
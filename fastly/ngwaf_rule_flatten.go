@@ -49,10 +49,6 @@ func flattenNGWAFRuleResponse(d *schema.ResourceData, rule *rules.Rule) error {
 		return fmt.Errorf("error setting enabled: %w", err)
 	}
 
-	if err := d.Set("group_operator", rule.GroupOperator); err != nil {
-		return fmt.Errorf("error setting group_operator: %w", err)
-	}
-
 	if err := d.Set("request_logging", rule.RequestLogging); err != nil {
 		return fmt.Errorf("error setting request_logging: %w", err)
 	}
@@ -67,6 +63,32 @@ func flattenNGWAFRuleResponse(d *schema.ResourceData, rule *rules.Rule) error {
 	// Flatten conditions
 	singles, groups, multivals := flattenNGWAFRuleConditionsGeneric(rule.Conditions)
 
+	// Rules configured with an expression keep it, rather than the blocks,
+	// in state. The configured expression is kept unless the rule changed.
+	groupOperator := rule.GroupOperator
+	if expression := d.Get("expression").(string); expression != "" {
+		canonical, err := formatNGWAFRuleExpression(&ngwafRuleConditions{
+			groupOperator:      rule.GroupOperator,
+			conditions:         flattenNGWAFRuleConditionList(singles),
+			groupConditions:    flattenNGWAFRuleConditionList(groups),
+			multivalConditions: flattenNGWAFRuleConditionList(multivals),
+		})
+		if err != nil {
+			return fmt.Errorf("error formatting expression: %w", err)
+		}
+		if !ngwafRuleExpressionsEquivalent(expression, canonical) {
+			if err := d.Set("expression", canonical); err != nil {
+				return fmt.Errorf("error setting expression: %w", err)
+			}
+		}
+		singles, groups, multivals = nil, nil, nil
+		groupOperator = ""
+	}
+
+	if err := d.Set("group_operator", groupOperator); err != nil {
+		return fmt.Errorf("error setting group_operator: %w", err)
+	}
+
 	if err := d.Set("condition", singles); err != nil {
 		return fmt.Errorf("error setting condition: %w", err)
 	}
@@ -121,6 +121,19 @@ func flattenNGWAFRuleConditionsGeneric(items []rules.ConditionItem) ([]map[strin
 	return singles, groups, multivals
 }
 
+// flattenNGWAFRuleConditionList converts flattened conditions to the []any
+// form returned by d.Get, as used when expanding them.
+func flattenNGWAFRuleConditionList(items []map[string]any) []any {
+	if items == nil {
+		return nil
+	}
+	result := make([]any, len(items))
+	for i, item := range items {
+		result[i] = item
+	}
+	return result
+}
+
 func flattenNGWAFRuleActionsGeneric(actions []rules.Action, isWorkspace bool) []map[string]any {
 	var result []map[string]any
 
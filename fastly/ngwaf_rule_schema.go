@@ -100,6 +100,14 @@ func resourceFastlyNGWAFRuleBase() *schema.Resource {
 				Required:    true,
 				Description: "Whether the rule is currently enabled.",
 			},
+			"expression": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "The rule's conditions as an expression, such as `ip in list(\"site.blocked\") and (path ~ \"^/admin\" or method == \"DELETE\")`. Conflicts with `condition`, `group_condition`, `multival_condition` and `group_operator`.",
+				ConflictsWith:    []string{"condition", "group_condition", "group_operator", "multival_condition"},
+				DiffSuppressFunc: suppressEquivalentNGWAFRuleExpression,
+				ValidateDiagFunc: validateNGWAFRuleExpression(),
+			},
 			"group_condition": {
 				Type:        schema.TypeList,
 				Optional:    true,
@@ -313,15 +321,22 @@ func validateGroupConditionNotEmpty(_ context.Context, diff *schema.ResourceDiff
 	return nil
 }
 
-// validateRuleHasConditions ensures that defined rules have at least one condition at the top level
-// (condition, group_condition, or multival_condition).
+// validateRuleHasConditions ensures that defined rules have an expression or at least one condition at
+// the top level (condition, group_condition, or multival_condition).
 func validateRuleHasConditions(_ context.Context, diff *schema.ResourceDiff, _ any) error {
 	hasCondition := false
 
+	// Check for an expression, which may not be known until apply.
+	if _, ok := diff.GetOk("expression"); ok || !diff.NewValueKnown("expression") {
+		hasCondition = true
+	}
+
 	// Check for top-level condition.
-	if conditions, ok := diff.GetOk("condition"); ok {
-		if condList, ok := conditions.([]interface{}); ok && len(condList) > 0 {
-			hasCondition = true
+	if !hasCondition {
+		if conditions, ok := diff.GetOk("condition"); ok {
+			if condList, ok := conditions.([]interface{}); ok && len(condList) > 0 {
+				hasCondition = true
+			}
 		}
 	}
 
@@ -349,8 +364,14 @@ func validateRuleHasConditions(_ context.Context, diff *schema.ResourceDiff, _ a
 		if ruleType, ok := diff.GetOk("type"); ok && ruleType.(string) == "templated_signal" {
 			return nil
 		}
-		return fmt.Errorf("rule must define at least one 'condition', 'group_condition', or 'multival_condition'")
+		return fmt.Errorf("rule must define at least one 'condition', 'group_condition', or 'multival_condition', or an 'expression'")
 	}
 
 	return nil
 }
+
+// suppressEquivalentNGWAFRuleExpression suppresses diffs between expressions
+// that only differ in formatting or in the order of their conditions.
+func suppressEquivalentNGWAFRuleExpression(_, oldValue, newValue string, _ *schema.ResourceData) bool {
+	return ngwafRuleExpressionsEquivalent(oldValue, newValue)
+}
@@ -0,0 +1,623 @@
+package fastly
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	gofastly "github.com/fastly/go-fastly/v17/fastly"
+	"github.com/fastly/go-fastly/v17/fastly/ngwaf/v1/lists"
+	"github.com/fastly/go-fastly/v17/fastly/ngwaf/v1/rules"
+	"github.com/fastly/go-fastly/v17/fastly/ngwaf/v1/scope"
+	"github.com/fastly/go-fastly/v17/fastly/ngwaf/v1/signals"
+	"github.com/fastly/go-fastly/v17/fastly/ngwaf/v1/workspaces/redactions"
+	"github.com/fastly/go-fastly/v17/fastly/ngwaf/v1/workspaces/thresholds"
+	"github.com/fastly/go-fastly/v17/fastly/ngwaf/v1/workspaces/virtualpatches"
+)
+
+// ngwafWorkspaceSnapshot is the configuration of an NGWAF workspace, as
+// exported by fastly_ngwaf_workspace_snapshot and applied to another
+// workspace by fastly_ngwaf_workspace_sync. It holds no IDs of the workspace
+// it was taken from: lists, signals and thresholds are identified by name,
+// redactions by type and field, and rules by their position. Rule conditions
+// are kept as expressions.
+//
+// The unexported id fields hold the IDs of objects read from a workspace and
+// are not part of the JSON form.
+type ngwafWorkspaceSnapshot struct {
+	Lists          []ngwafSnapshotList         `json:"lists"`
+	Redactions     []ngwafSnapshotRedaction    `json:"redactions"`
+	Rules          []ngwafSnapshotRule         `json:"rules"`
+	Signals        []ngwafSnapshotSignal       `json:"signals"`
+	Thresholds     []ngwafSnapshotThreshold    `json:"thresholds"`
+	VirtualPatches []ngwafSnapshotVirtualPatch `json:"virtual_patches"`
+}
+
+type ngwafSnapshotList struct {
+	id          string
+	Description string   `json:"description"`
+	Entries     []string `json:"entries"`
+	Name        string   `json:"name"`
+	ReferenceID string   `json:"reference_id"`
+	Type        string   `json:"type"`
+}
+
+type ngwafSnapshotRedaction struct {
+	id    string
+	Field string `json:"field"`
+	Type  string `json:"type"`
+}
+
+type ngwafSnapshotRule struct {
+	id             string
+	Action         []ngwafSnapshotRuleAction `json:"action"`
+	Description    string                    `json:"description"`
+	Enabled        bool                      `json:"enabled"`
+	Expression     string                    `json:"expression"`
+	RateLimit      *ngwafSnapshotRateLimit   `json:"rate_limit,omitempty"`
+	RequestLogging string                    `json:"request_logging"`
+	Type           string                    `json:"type"`
+}
+
+type ngwafSnapshotRuleAction struct {
+	AllowInteractive bool   `json:"allow_interactive,omitempty"`
+	DeceptionType    string `json:"deception_type,omitempty"`
+	RedirectURL      string `json:"redirect_url,omitempty"`
+	ResponseCode     int    `json:"response_code,omitempty"`
+	Signal           string `json:"signal,omitempty"`
+	Type             string `json:"type"`
+}
+
+type ngwafSnapshotRateLimit struct {
+	ClientIdentifiers []ngwafSnapshotClientIdentifier `json:"client_identifiers"`
+	Duration          int                             `json:"duration"`
+	Interval          int                             `json:"interval"`
+	Signal            string                          `json:"signal"`
+	Threshold         int                             `json:"threshold"`
+}
+
+type ngwafSnapshotClientIdentifier struct {
+	Key  string `json:"key,omitempty"`
+	Name string `json:"name,omitempty"`
+	Type string `json:"type"`
+}
+
+type ngwafSnapshotSignal struct {
+	id          string
+	Description string `json:"description"`
+	Name        string `json:"name"`
+	ReferenceID string `json:"reference_id"`
+}
+
+type ngwafSnapshotThreshold struct {
+	id         string
+	Action     string `json:"action"`
+	DontNotify bool   `json:"dont_notify"`
+	Duration   int    `json:"duration"`
+	Enabled    bool   `json:"enabled"`
+	Interval   int    `json:"interval"`
+	Limit      int    `json:"limit"`
+	Name       string `json:"name"`
+	Signal     string `json:"signal"`
+}
+
+type ngwafSnapshotVirtualPatch struct {
+	Enabled bool   `json:"enabled"`
+	ID      string `json:"id"`
+	Mode    string `json:"mode"`
+}
+
+// readNGWAFWorkspaceSnapshot reads the configuration of a workspace. Lists,
+// rules and signals of the account that apply to the workspace are left out.
+func readNGWAFWorkspaceSnapshot(ctx context.Context, conn *gofastly.Client, workspaceID string) (*ngwafWorkspaceSnapshot, error) {
+	ctx = gofastly.NewContextForResourceID(ctx, workspaceID)
+	scopeObj := &scope.Scope{
+		Type:      scope.ScopeTypeWorkspace,
+		AppliesTo: []string{workspaceID},
+	}
+	isWorkspace := func(scopeType string) bool {
+		return scopeType == "" || scopeType == string(scope.ScopeTypeWorkspace)
+	}
+
+	s := &ngwafWorkspaceSnapshot{}
+
+	remoteLists, err := lists.ListLists(ctx, conn, &lists.ListInput{Scope: scopeObj})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching lists: %w", err)
+	}
+	for _, list := range remoteLists.Data {
+		if !isWorkspace(list.Scope.Type) {
+			continue
+		}
+		s.Lists = append(s.Lists, ngwafSnapshotList{
+			id:          list.ListID,
+			Description: list.Description,
+			Entries:     list.Entries,
+			Name:        list.Name,
+			ReferenceID: list.ReferenceID,
+			Type:        list.Type,
+		})
+	}
+
+	remoteSignals, err := signals.List(ctx, conn, &signals.ListInput{Scope: scopeObj})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching signals: %w", err)
+	}
+	for _, signal := range remoteSignals.Data {
+		if !isWorkspace(signal.Scope.Type) {
+			continue
+		}
+		s.Signals = append(s.Signals, ngwafSnapshotSignal{
+			id:          signal.SignalID,
+			Description: signal.Description,
+			Name:        signal.Name,
+			ReferenceID: signal.ReferenceID,
+		})
+	}
+
+	remoteRules, err := rules.List(ctx, conn, &rules.ListInput{Scope: scopeObj})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching rules: %w", err)
+	}
+	for i := range remoteRules.Data {
+		if !isWorkspace(remoteRules.Data[i].Scope.Type) {
+			continue
+		}
+		rule, err := ngwafSnapshotRuleFromAPI(&remoteRules.Data[i])
+		if err != nil {
+			return nil, err
+		}
+		s.Rules = append(s.Rules, rule)
+	}
+
+	remoteRedactions, err := redactions.List(ctx, conn, &redactions.ListInput{WorkspaceID: &workspaceID})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching redactions: %w", err)
+	}
+	for _, r := range remoteRedactions.Data {
+		s.Redactions = append(s.Redactions, ngwafSnapshotRedaction{
+			id:    r.RedactionID,
+			Field: r.Field,
+			Type:  r.Type,
+		})
+	}
+
+	remoteThresholds, err := thresholds.List(ctx, conn, &thresholds.ListInput{WorkspaceID: &workspaceID})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching thresholds: %w", err)
+	}
+	for _, t := range remoteThresholds.Data {
+		s.Thresholds = append(s.Thresholds, ngwafSnapshotThreshold{
+			id:         t.ThresholdID,
+			Action:     t.Action,
+			DontNotify: t.DontNotify,
+			Duration:   t.Duration,
+			Enabled:    t.Enabled,
+			Interval:   t.Interval,
+			Limit:      t.Limit,
+			Name:       t.Name,
+			Signal:     t.Signal,
+		})
+	}
+
+	remoteVirtualPatches, err := virtualpatches.List(ctx, conn, &virtualpatches.ListInput{WorkspaceID: &workspaceID})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching virtual patches: %w", err)
+	}
+	for _, vp := range remoteVirtualPatches.Data {
+		s.VirtualPatches = append(s.VirtualPatches, ngwafSnapshotVirtualPatch{
+			Enabled: vp.Enabled,
+			ID:      vp.ID,
+			Mode:    vp.Mode,
+		})
+	}
+
+	if err := s.normalize(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func ngwafSnapshotRuleFromAPI(rule *rules.Rule) (ngwafSnapshotRule, error) {
+	singles, groups, multivals := flattenNGWAFRuleConditionsGeneric(rule.Conditions)
+	expression, err := formatNGWAFRuleExpression(&ngwafRuleConditions{
+		groupOperator:      rule.GroupOperator,
+		conditions:         flattenNGWAFRuleConditionList(singles),
+		groupConditions:    flattenNGWAFRuleConditionList(groups),
+		multivalConditions: flattenNGWAFRuleConditionList(multivals),
+	})
+	if err != nil {
+		return ngwafSnapshotRule{}, fmt.Errorf("error formatting conditions of rule (%s): %w", rule.RuleID, err)
+	}
+
+	r := ngwafSnapshotRule{
+		id:             rule.RuleID,
+		Description:    rule.Description,
+		Enabled:        rule.Enabled,
+		Expression:     expression,
+		RequestLogging: rule.RequestLogging,
+		Type:           rule.Type,
+	}
+	for _, a := range rule.Actions {
+		r.Action = append(r.Action, ngwafSnapshotRuleAction{
+			AllowInteractive: a.AllowInteractive != nil && *a.AllowInteractive,
+			DeceptionType:    a.DeceptionType,
+			RedirectURL:      a.RedirectURL,
+			ResponseCode:     a.ResponseCode,
+			Signal:           a.Signal,
+			Type:             a.Type,
+		})
+	}
+	if rl := rule.RateLimit; rl != nil {
+		r.RateLimit = &ngwafSnapshotRateLimit{
+			Duration:  rl.Duration,
+			Interval:  rl.Interval,
+			Signal:    rl.Signal,
+			Threshold: rl.Threshold,
+		}
+		for _, ci := range rl.ClientIdentifiers {
+			r.RateLimit.ClientIdentifiers = append(r.RateLimit.ClientIdentifiers, ngwafSnapshotClientIdentifier{
+				Key:  ci.Key,
+				Name: ci.Name,
+				Type: ci.Type,
+			})
+		}
+	}
+	return r, nil
+}
+
+// parseNGWAFWorkspaceSnapshot parses and validates the JSON form of a
+// snapshot, returning it normalized.
+func parseNGWAFWorkspaceSnapshot(raw string) (*ngwafWorkspaceSnapshot, error) {
+	dec := json.NewDecoder(strings.NewReader(raw))
+	dec.DisallowUnknownFields()
+
+	s := &ngwafWorkspaceSnapshot{}
+	if err := dec.Decode(s); err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+
+	seen := map[string]bool{}
+	unique := func(kind, key string) error {
+		if seen[kind+"\x00"+key] {
+			return fmt.Errorf("invalid snapshot: duplicate %s %q", kind, key)
+		}
+		seen[kind+"\x00"+key] = true
+		return nil
+	}
+	for _, l := range s.Lists {
+		if err := unique("list", l.Name); err != nil {
+			return nil, err
+		}
+	}
+	for _, r := range s.Redactions {
+		if err := unique("redaction", r.key()); err != nil {
+			return nil, err
+		}
+	}
+	for _, sig := range s.Signals {
+		if err := unique("signal", sig.Name); err != nil {
+			return nil, err
+		}
+	}
+	for _, t := range s.Thresholds {
+		if err := unique("threshold", t.Name); err != nil {
+			return nil, err
+		}
+	}
+	for _, vp := range s.VirtualPatches {
+		if err := unique("virtual patch", vp.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.normalize(); err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+	return s, nil
+}
+
+// key identifies a redaction, as `<type>/<field>`.
+func (r ngwafSnapshotRedaction) key() string {
+	return r.Type + "/" + r.Field
+}
+
+// normalize writes rule expressions in canonical form, applies defaults and
+// sorts the snapshot, so that equivalent snapshots have the same JSON form.
+// Rules are sorted by their JSON form, as they have no name.
+func (s *ngwafWorkspaceSnapshot) normalize() error {
+	for i := range s.Lists {
+		s.Lists[i].Entries = nonNilSlice(slices.Sorted(slices.Values(s.Lists[i].Entries)))
+	}
+	for i := range s.Rules {
+		r := &s.Rules[i]
+		r.Action = nonNilSlice(r.Action)
+		if r.Expression != "" {
+			c, err := parseNGWAFRuleExpression(r.Expression)
+			if err != nil {
+				return fmt.Errorf("rule %q: invalid expression at %w", r.Description, err)
+			}
+			if r.Expression, err = formatNGWAFRuleExpression(c); err != nil {
+				return fmt.Errorf("rule %q: %w", r.Description, err)
+			}
+		}
+		if r.RateLimit != nil {
+			r.RateLimit.ClientIdentifiers = nonNilSlice(r.RateLimit.ClientIdentifiers)
+			slices.SortFunc(r.RateLimit.ClientIdentifiers, func(a, b ngwafSnapshotClientIdentifier) int {
+				return strings.Compare(a.Type+"\x00"+a.Name+"\x00"+a.Key, b.Type+"\x00"+b.Name+"\x00"+b.Key)
+			})
+		}
+	}
+	for i := range s.Thresholds {
+		if s.Thresholds[i].Duration == 0 {
+			s.Thresholds[i].Duration = 86400
+		}
+	}
+
+	s.Lists = nonNilSlice(s.Lists)
+	s.Redactions = nonNilSlice(s.Redactions)
+	s.Rules = nonNilSlice(s.Rules)
+	s.Signals = nonNilSlice(s.Signals)
+	s.Thresholds = nonNilSlice(s.Thresholds)
+	s.VirtualPatches = nonNilSlice(s.VirtualPatches)
+
+	slices.SortFunc(s.Lists, func(a, b ngwafSnapshotList) int { return strings.Compare(a.Name, b.Name) })
+	slices.SortFunc(s.Redactions, func(a, b ngwafSnapshotRedaction) int { return strings.Compare(a.key(), b.key()) })
+	slices.SortFunc(s.Signals, func(a, b ngwafSnapshotSignal) int { return strings.Compare(a.Name, b.Name) })
+	slices.SortFunc(s.Thresholds, func(a, b ngwafSnapshotThreshold) int { return strings.Compare(a.Name, b.Name) })
+	slices.SortFunc(s.VirtualPatches, func(a, b ngwafSnapshotVirtualPatch) int { return strings.Compare(a.ID, b.ID) })
+
+	slices.SortStableFunc(s.Rules, func(a, b ngwafSnapshotRule) int {
+		return strings.Compare(a.key(), b.key())
+	})
+	return nil
+}
+
+// key identifies a rule by its JSON form.
+func (r ngwafSnapshotRule) key() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func nonNilSlice[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+// json returns the indented JSON form of the snapshot.
+func (s *ngwafWorkspaceSnapshot) json() (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// remap returns a copy of the snapshot in which the reference IDs of lists
+// and signals are replaced as given by refs. Reference IDs are replaced in
+// the entries of signal lists, in rule condition values, in the signals of
+// rule actions and rate limits, and in the signals of thresholds. Reference
+// IDs not in refs, such as those of account lists and signals, are kept.
+func (s *ngwafWorkspaceSnapshot) remap(refs map[string]string) (*ngwafWorkspaceSnapshot, error) {
+	ref := func(v string) string {
+		if r, ok := refs[v]; ok {
+			return r
+		}
+		return v
+	}
+
+	out := &ngwafWorkspaceSnapshot{
+		Lists:          slices.Clone(s.Lists),
+		Redactions:     slices.Clone(s.Redactions),
+		Rules:          slices.Clone(s.Rules),
+		Signals:        slices.Clone(s.Signals),
+		Thresholds:     slices.Clone(s.Thresholds),
+		VirtualPatches: slices.Clone(s.VirtualPatches),
+	}
+	for i := range out.Lists {
+		l := &out.Lists[i]
+		l.ReferenceID = ref(l.ReferenceID)
+		if l.Type == "signal" {
+			entries := make([]string, len(l.Entries))
+			for j, e := range l.Entries {
+				entries[j] = ref(e)
+			}
+			l.Entries = entries
+		}
+	}
+	for i := range out.Signals {
+		out.Signals[i].ReferenceID = ref(out.Signals[i].ReferenceID)
+	}
+	for i := range out.Thresholds {
+		out.Thresholds[i].Signal = ref(out.Thresholds[i].Signal)
+	}
+	for i := range out.Rules {
+		r := &out.Rules[i]
+		if r.Expression != "" {
+			expression, err := remapNGWAFRuleExpression(r.Expression, ref)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q: %w", r.Description, err)
+			}
+			r.Expression = expression
+		}
+		actions := slices.Clone(r.Action)
+		for j := range actions {
+			actions[j].Signal = ref(actions[j].Signal)
+		}
+		r.Action = actions
+		if r.RateLimit != nil {
+			rl := *r.RateLimit
+			rl.Signal = ref(rl.Signal)
+			r.RateLimit = &rl
+		}
+	}
+	return out, nil
+}
+
+// remapNGWAFRuleExpression replaces the condition values of an expression.
+func remapNGWAFRuleExpression(expr string, ref func(string) string) (string, error) {
+	c, err := parseNGWAFRuleExpression(expr)
+	if err != nil {
+		return "", fmt.Errorf("invalid expression at %w", err)
+	}
+
+	var remapConditions func(items []any)
+	remapConditions = func(items []any) {
+		for _, raw := range items {
+			m := raw.(map[string]any)
+			if v, ok := m["value"].(string); ok {
+				m["value"] = ref(v)
+			}
+			if nested, ok := m["condition"].([]any); ok {
+				remapConditions(nested)
+			}
+			if nested, ok := m["multival_condition"].([]any); ok {
+				remapConditions(nested)
+			}
+		}
+	}
+	remapConditions(c.conditions)
+	remapConditions(c.groupConditions)
+	remapConditions(c.multivalConditions)
+
+	return formatNGWAFRuleExpression(c)
+}
+
+// ngwafWorkspaceSyncState holds the IDs of the objects of a workspace that a
+// fastly_ngwaf_workspace_sync manages, and the reference IDs of its lists and
+// signals by their reference IDs in the snapshot. adopted holds the IDs of the
+// managed objects that existed in the workspace before the sync applied them,
+// rather than being created by it.
+type ngwafWorkspaceSyncState struct {
+	adopted    map[string]bool
+	lists      map[string]string
+	redactions map[string]string
+	references map[string]string
+	rules      []string
+	signals    map[string]string
+	thresholds map[string]string
+}
+
+// adopt records that the object with the given ID, if any, already existed
+// in the workspace, and returns the ID.
+func (st *ngwafWorkspaceSyncState) adopt(id string) string {
+	if id != "" {
+		st.adopted[id] = true
+	}
+	return id
+}
+
+// release forgets whether the object with the given ID was adopted, and
+// reports whether it should be left in the workspace rather than deleted
+// when the sync stops managing it.
+func (st *ngwafWorkspaceSyncState) release(id string, deleteAdopted bool) bool {
+	adopted := st.adopted[id]
+	delete(st.adopted, id)
+	return adopted && !deleteAdopted
+}
+
+// observe returns the part of target, a snapshot of the workspace, that the
+// sync manages, written in terms of applied, the last snapshot applied.
+// Objects that no longer exist in the workspace are forgotten, so that the
+// next apply creates them again.
+func (st *ngwafWorkspaceSyncState) observe(target, applied *ngwafWorkspaceSnapshot) (*ngwafWorkspaceSnapshot, error) {
+	observed := &ngwafWorkspaceSnapshot{}
+	listIDs, redactionIDs, signalIDs, thresholdIDs := map[string]string{}, map[string]string{}, map[string]string{}, map[string]string{}
+	for _, l := range target.Lists {
+		if id := st.lists[l.Name]; id != "" && id == l.id {
+			observed.Lists = append(observed.Lists, l)
+			listIDs[l.Name] = id
+		}
+	}
+	for _, r := range target.Redactions {
+		if id := st.redactions[r.key()]; id != "" && id == r.id {
+			observed.Redactions = append(observed.Redactions, r)
+			redactionIDs[r.key()] = id
+		}
+	}
+	for _, sig := range target.Signals {
+		if id := st.signals[sig.Name]; id != "" && id == sig.id {
+			observed.Signals = append(observed.Signals, sig)
+			signalIDs[sig.Name] = id
+		}
+	}
+	for _, t := range target.Thresholds {
+		if id := st.thresholds[t.Name]; id != "" && id == t.id {
+			observed.Thresholds = append(observed.Thresholds, t)
+			thresholdIDs[t.Name] = id
+		}
+	}
+	st.lists, st.redactions, st.signals, st.thresholds = listIDs, redactionIDs, signalIDs, thresholdIDs
+
+	adopted := map[string]bool{}
+	for _, ids := range []map[string]string{listIDs, redactionIDs, signalIDs, thresholdIDs} {
+		for _, id := range ids {
+			if st.adopted[id] {
+				adopted[id] = true
+			}
+		}
+	}
+	st.adopted = adopted
+
+	rulesByID := map[string]ngwafSnapshotRule{}
+	for _, r := range target.Rules {
+		rulesByID[r.id] = r
+	}
+	var ruleIDs []string
+	for _, id := range st.rules {
+		if r, ok := rulesByID[id]; ok {
+			observed.Rules = append(observed.Rules, r)
+			ruleIDs = append(ruleIDs, id)
+		}
+	}
+	st.rules = ruleIDs
+
+	virtualPatches := map[string]bool{}
+	for _, vp := range applied.VirtualPatches {
+		virtualPatches[vp.ID] = true
+	}
+	for _, vp := range target.VirtualPatches {
+		if virtualPatches[vp.ID] {
+			observed.VirtualPatches = append(observed.VirtualPatches, vp)
+		}
+	}
+
+	sources := map[string]string{}
+	for source, ref := range st.references {
+		sources[ref] = source
+	}
+	observed, err := observed.remap(sources)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reference IDs of lists and signals are kept as in the applied snapshot,
+	// which may leave them out.
+	listRefs := map[string]string{}
+	for _, l := range applied.Lists {
+		listRefs[l.Name] = l.ReferenceID
+	}
+	for i := range observed.Lists {
+		observed.Lists[i].ReferenceID = listRefs[observed.Lists[i].Name]
+	}
+	signalRefs := map[string]string{}
+	for _, sig := range applied.Signals {
+		signalRefs[sig.Name] = sig.ReferenceID
+	}
+	for i := range observed.Signals {
+		observed.Signals[i].ReferenceID = signalRefs[observed.Signals[i].Name]
+	}
+
+	if err := observed.normalize(); err != nil {
+		return nil, err
+	}
+	return observed, nil
+}
@@ -0,0 +1,228 @@
+package fastly
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testNGWAFWorkspaceSnapshot = `{
+  "lists": [
+    {"name": "blocked", "type": "ip", "entries": ["192.0.2.2", "192.0.2.1"], "reference_id": "site.blocked"},
+    {"name": "admin-signals", "type": "signal", "entries": ["site.admin", "CMDEXE"], "reference_id": "site.admin-signals"}
+  ],
+  "redactions": [
+    {"field": "password", "type": "request_parameter"}
+  ],
+  "rules": [
+    {
+      "type": "request",
+      "description": "Block admin access",
+      "enabled": true,
+      "request_logging": "sampled",
+      "expression": "ip  in list(\"site.blocked\") and (path ~ \"^/admin\" or method==\"DELETE\")",
+      "action": [{"type": "add_signal", "signal": "site.admin"}]
+    },
+    {
+      "type": "rate_limit",
+      "description": "Rate limit logins",
+      "enabled": true,
+      "request_logging": "sampled",
+      "expression": "path == \"/login\"",
+      "action": [{"type": "block_signal", "signal": "site.admin"}],
+      "rate_limit": {
+        "client_identifiers": [{"type": "ip"}],
+        "duration": 300,
+        "interval": 60,
+        "signal": "site.admin",
+        "threshold": 100
+      }
+    }
+  ],
+  "signals": [
+    {"name": "admin", "description": "Admin access", "reference_id": "site.admin"}
+  ],
+  "thresholds": [
+    {"name": "admin", "action": "block", "enabled": true, "interval": 60, "limit": 10, "signal": "site.admin"}
+  ],
+  "virtual_patches": []
+}`
+
+func TestParseNGWAFWorkspaceSnapshot(t *testing.T) {
+	s, err := parseNGWAFWorkspaceSnapshot(testNGWAFWorkspaceSnapshot)
+	require.NoError(t, err)
+
+	require.Equal(t, "admin-signals", s.Lists[0].Name)
+	require.Equal(t, []string{"CMDEXE", "site.admin"}, s.Lists[0].Entries)
+	require.Equal(t, []string{"192.0.2.1", "192.0.2.2"}, s.Lists[1].Entries)
+	require.Equal(t, "rate_limit", s.Rules[1].Type)
+	require.Equal(t, `ip in list("site.blocked") and (path ~ "^/admin" or method == "DELETE")`, s.Rules[0].Expression)
+	require.Equal(t, 86400, s.Thresholds[0].Duration)
+	require.NotNil(t, s.VirtualPatches)
+
+	// The JSON form of a normalized snapshot parses to the same snapshot.
+	raw, err := s.json()
+	require.NoError(t, err)
+	again, err := parseNGWAFWorkspaceSnapshot(raw)
+	require.NoError(t, err)
+	rawAgain, err := again.json()
+	require.NoError(t, err)
+	require.Equal(t, raw, rawAgain)
+}
+
+func TestParseNGWAFWorkspaceSnapshotErrors(t *testing.T) {
+	for _, tc := range []struct {
+		raw  string
+		want string
+	}{
+		{`{"lists": [{"name": "a"}, {"name": "a"}]}`, `duplicate list "a"`},
+		{`{"redactions": [{"field": "a", "type": "request_header"}, {"field": "a", "type": "request_header"}]}`, `duplicate redaction "request_header/a"`},
+		{`{"rules": [{"description": "bad", "expression": "path =="}]}`, `rule "bad": invalid expression at 1:8`},
+		{`{"workspace_id": "abc"}`, `unknown field "workspace_id"`},
+		{`[]`, `invalid snapshot`},
+	} {
+		_, err := parseNGWAFWorkspaceSnapshot(tc.raw)
+		require.ErrorContains(t, err, tc.want, tc.raw)
+	}
+}
+
+func TestNGWAFWorkspaceSnapshotRemap(t *testing.T) {
+	s, err := parseNGWAFWorkspaceSnapshot(testNGWAFWorkspaceSnapshot)
+	require.NoError(t, err)
+
+	remapped, err := s.remap(map[string]string{
+		"site.admin":   "site.admin-2",
+		"site.blocked": "site.blocked-2",
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"CMDEXE", "site.admin-2"}, remapped.Lists[0].Entries)
+	require.Equal(t, "site.blocked-2", remapped.Lists[1].ReferenceID)
+	require.Equal(t, []string{"192.0.2.1", "192.0.2.2"}, remapped.Lists[1].Entries)
+	require.Equal(t, `ip in list("site.blocked-2") and (path ~ "^/admin" or method == "DELETE")`, remapped.Rules[0].Expression)
+	require.Equal(t, "site.admin-2", remapped.Rules[0].Action[0].Signal)
+	require.Equal(t, "site.admin-2", remapped.Rules[1].RateLimit.Signal)
+	require.Equal(t, "site.admin-2", remapped.Signals[0].ReferenceID)
+	require.Equal(t, "site.admin-2", remapped.Thresholds[0].Signal)
+
+	// The original snapshot is left unchanged.
+	require.Equal(t, "site.admin", s.Rules[0].Action[0].Signal)
+	require.Equal(t, "site.admin", s.Rules[1].RateLimit.Signal)
+	require.Equal(t, []string{"CMDEXE", "site.admin"}, s.Lists[0].Entries)
+}
+
+func TestNGWAFWorkspaceSyncStateObserve(t *testing.T) {
+	applied, err := parseNGWAFWorkspaceSnapshot(testNGWAFWorkspaceSnapshot)
+	require.NoError(t, err)
+
+	target := &ngwafWorkspaceSnapshot{
+		Lists: []ngwafSnapshotList{
+			{id: "l1", Name: "blocked", Type: "ip", Entries: []string{"192.0.2.1"}, ReferenceID: "site.blocked-2"},
+			{id: "l2", Name: "unmanaged", Type: "ip", Entries: []string{"198.51.100.1"}},
+		},
+		Rules: []ngwafSnapshotRule{
+			{id: "r1", Type: "request", Description: "Block admin access", Enabled: true, RequestLogging: "sampled", Expression: `ip in list("site.blocked-2")`, Action: []ngwafSnapshotRuleAction{{Type: "add_signal", Signal: "site.admin-2"}}},
+			{id: "r3", Type: "request", Description: "Unmanaged"},
+		},
+		Signals: []ngwafSnapshotSignal{
+			{id: "s1", Name: "admin", Description: "Admin access", ReferenceID: "site.admin-2"},
+		},
+		Thresholds: []ngwafSnapshotThreshold{
+			{id: "t1", Name: "admin", Action: "block", Enabled: true, Interval: 60, Limit: 10, Signal: "site.admin-2"},
+		},
+		VirtualPatches: []ngwafSnapshotVirtualPatch{
+			{ID: "CVE-2017-5638", Mode: "block"},
+		},
+	}
+	state := &ngwafWorkspaceSyncState{
+		adopted:    map[string]bool{"l1": true, "l3": true, "x1": true},
+		lists:      map[string]string{"blocked": "l1", "admin-signals": "l3"},
+		redactions: map[string]string{"request_parameter/password": "x1"},
+		references: map[string]string{"site.admin": "site.admin-2", "site.blocked": "site.blocked-2", "site.admin-signals": "site.admin-signals"},
+		rules:      []string{"r1", "r2"},
+		signals:    map[string]string{"admin": "s1"},
+		thresholds: map[string]string{"admin": "t1"},
+	}
+
+	observed, err := state.observe(target, applied)
+	require.NoError(t, err)
+
+	// Objects deleted from the workspace are forgotten.
+	require.Equal(t, map[string]string{"blocked": "l1"}, state.lists)
+	require.Empty(t, state.redactions)
+	require.Equal(t, []string{"r1"}, state.rules)
+	require.Equal(t, map[string]bool{"l1": true}, state.adopted)
+
+	// Managed objects are written in terms of the applied snapshot.
+	require.Len(t, observed.Lists, 1)
+	require.Equal(t, "site.blocked", observed.Lists[0].ReferenceID)
+	require.Equal(t, []string{"192.0.2.1"}, observed.Lists[0].Entries)
+	require.Len(t, observed.Rules, 1)
+	require.Equal(t, `ip in list("site.blocked")`, observed.Rules[0].Expression)
+	require.Equal(t, "site.admin", observed.Rules[0].Action[0].Signal)
+	require.Equal(t, "site.admin", observed.Signals[0].ReferenceID)
+	require.Equal(t, "site.admin", observed.Thresholds[0].Signal)
+	require.Equal(t, 86400, observed.Thresholds[0].Duration)
+	require.Empty(t, observed.VirtualPatches)
+	require.Empty(t, observed.Redactions)
+
+	// A workspace matching the applied snapshot has no drift.
+	state = &ngwafWorkspaceSyncState{
+		lists:      map[string]string{},
+		redactions: map[string]string{},
+		references: map[string]string{},
+		signals:    map[string]string{},
+		thresholds: map[string]string{},
+	}
+	target = &ngwafWorkspaceSnapshot{}
+	for i, l := range applied.Lists {
+		l.id = "l" + string(rune('a'+i))
+		state.lists[l.Name] = l.id
+		target.Lists = append(target.Lists, l)
+	}
+	for i, r := range applied.Rules {
+		r.id = "r" + string(rune('a'+i))
+		state.rules = append(state.rules, r.id)
+		target.Rules = append(target.Rules, r)
+	}
+	for _, r := range applied.Redactions {
+		r.id = "x"
+		state.redactions[r.key()] = r.id
+		target.Redactions = append(target.Redactions, r)
+	}
+	for _, s := range applied.Signals {
+		s.id = "s"
+		state.signals[s.Name] = s.id
+		target.Signals = append(target.Signals, s)
+	}
+	for _, th := range applied.Thresholds {
+		th.id = "t"
+		state.thresholds[th.Name] = th.id
+		target.Thresholds = append(target.Thresholds, th)
+	}
+	observed, err = state.observe(target, applied)
+	require.NoError(t, err)
+	want, err := applied.json()
+	require.NoError(t, err)
+	got, err := observed.json()
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
+
+func TestNGWAFWorkspaceSyncStateAdoptRelease(t *testing.T) {
+	state := &ngwafWorkspaceSyncState{adopted: map[string]bool{}}
+
+	require.Equal(t, "", state.adopt(""))
+	require.Equal(t, "l1", state.adopt("l1"))
+	require.Equal(t, map[string]bool{"l1": true}, state.adopted)
+
+	// Adopted objects are kept unless deleteAdopted is set, and created
+	// objects are always deleted.
+	require.True(t, state.release("l1", false))
+	require.Empty(t, state.adopted)
+	require.False(t, state.release("l2", false))
+
+	state.adopt("l1")
+	require.False(t, state.release("l1", true))
+	require.Empty(t, state.adopted)
+}
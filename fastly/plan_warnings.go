@@ -0,0 +1,60 @@
+package fastly
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+type planWarningsKey struct{}
+
+// planWarnings collects the warnings raised while planning a resource.
+type planWarnings struct {
+	mu    sync.Mutex
+	diags diag.Diagnostics
+}
+
+// withPlanWarnings returns a context in which addPlanWarning records
+// warnings to w.
+func withPlanWarnings(ctx context.Context) (context.Context, *planWarnings) {
+	w := &planWarnings{}
+	return context.WithValue(ctx, planWarningsKey{}, w), w
+}
+
+// addPlanWarning records a warning to be shown with the plan of the resource
+// being planned. CustomizeDiff functions can only return errors, so this is
+// the only way for them to warn. The warning is always logged, which is all
+// that happens when ctx does not come from PlanResourceChange (e.g. in unit
+// tests).
+func addPlanWarning(ctx context.Context, summary, detail string) {
+	log.Printf("[WARN] %s: %s", summary, detail)
+
+	w, ok := ctx.Value(planWarningsKey{}).(*planWarnings)
+	if !ok {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// CustomizeDiff can run more than once for a single plan.
+	for _, d := range w.diags {
+		if d.Summary == summary && d.Detail == detail {
+			return
+		}
+	}
+	w.diags = append(w.diags, diag.Diagnostic{
+		Severity: diag.Warning,
+		Summary:  summary,
+		Detail:   detail,
+	})
+}
+
+// diagnostics returns the recorded warnings.
+func (w *planWarnings) diagnostics() diag.Diagnostics {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append(diag.Diagnostics(nil), w.diags...)
+}
@@ -3,9 +3,12 @@ package fastly
 import (
 	"context"
 	"os"
+	"time"
 
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
 	gofastly "github.com/fastly/go-fastly/v17/fastly"
 
@@ -26,10 +29,17 @@ func Provider() *schema.Provider {
 	provider := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"api_key": {
-				Type:        schema.TypeString,
-				Optional:    true,
-				DefaultFunc: schema.EnvDefaultFunc("FASTLY_API_KEY", nil),
-				Description: "Fastly API Key from https://app.fastly.com/#account",
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("FASTLY_API_KEY", nil),
+				Description:   "Fastly API Key from https://app.fastly.com/#account. Conflicts with `api_key_command`, `profile` and `config_file`, which take precedence over the `FASTLY_API_KEY` environment variable",
+				ConflictsWith: []string{"api_key_command", "profile", "config_file"},
+			},
+			"api_key_command": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "A command that prints the Fastly API Key to stdout, e.g. a credential helper for a secrets manager. It is run with `sh -c` (`cmd /C` on Windows) once per provider process. Conflicts with `api_key`, `profile` and `config_file`",
+				ConflictsWith: []string{"api_key", "profile", "config_file"},
 			},
 			"base_url": {
 				Type:        schema.TypeString,
@@ -37,18 +47,51 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("FASTLY_API_URL", gofastly.DefaultEndpoint),
 				Description: "Fastly API URL",
 			},
+			"config_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "The path of the Fastly CLI config file to read `profile` from. Defaults to the Fastly CLI's own config file, e.g. `~/.config/fastly/config.toml` on Linux. Conflicts with `api_key` and `api_key_command`",
+				ConflictsWith: []string{"api_key", "api_key_command"},
+			},
+			"expected_customer_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The ID of the Fastly customer the API Key must belong to. If set, the API Key is verified when the provider is configured, so a key for the wrong account fails before any changes are made",
+			},
 			"force_http2": {
 				Type:        schema.TypeBool,
 				Optional:    true,
 				Default:     false,
 				Description: "Set this to `true` to disable HTTP/1.x fallback mechanism that the underlying Go library will attempt upon connection to `api.fastly.com:443` by default. This may slightly improve the provider's performance and reduce unnecessary TLS handshakes. Default: `false`",
 			},
+			"guardrails": guardrailsSchema(),
 			"no_auth": {
 				Type:        schema.TypeBool,
 				Optional:    true,
 				Default:     false,
 				Description: "Set to `true` if your configuration only consumes data sources that do not require authentication, such as `fastly_ip_ranges`",
 			},
+			"profile": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "The name of a Fastly CLI profile (see `fastly profile list`) whose token is used as the API Key. If only `config_file` is set, its default profile is used. Conflicts with `api_key` and `api_key_command`",
+				ConflictsWith: []string{"api_key", "api_key_command"},
+			},
+			"required_scopes": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "The scopes the API Key must have, verified when the provider is configured. One or more of `global`, `global:read`, `purge_all` and `purge_select`. A `global` key also has the `global:read` scope, and a `purge_all` key the `purge_select` scope",
+				Elem: &schema.Schema{
+					Type:             schema.TypeString,
+					ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(tokenScopes, false)),
+				},
+			},
+			"token_expiry_warning": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "Warn when the API Key expires within this duration, e.g. `168h`, verified when the provider is configured",
+				ValidateDiagFunc: validateDuration(),
+			},
 		},
 		DataSourcesMap: map[string]*schema.Resource{
 			"fastly_api_security_operations":                 dataSourceFastlyAPISecurityOperations(),
@@ -56,6 +99,7 @@ func Provider() *schema.Provider {
 			"fastly_api_security_discovered_operations":      dataSourceFastlyAPISecurityDiscoveredOperations(),
 			"fastly_compute_acls":                            dataSourceFastlyComputeACLs(),
 			"fastly_configstores":                            dataSourceFastlyConfigStores(),
+			"fastly_current_user":                            dataSourceFastlyCurrentUser(),
 			"fastly_datacenters":                             dataSourceFastlyDatacenters(),
 			"fastly_dictionaries":                            dataSourceFastlyDictionaries(),
 			"fastly_dns_zones":                               dataSourceFastlyDNSZones(),
@@ -63,6 +107,7 @@ func Provider() *schema.Provider {
 			"fastly_domains_v1":                              dataSourceFastlyDomainsV1(),
 			"fastly_ip_ranges":                               dataSourceFastlyIPRanges(),
 			"fastly_kvstores":                                dataSourceFastlyKVStores(),
+			"fastly_mtls_bundle":                             dataSourceFastlyMTLSBundle(),
 			"fastly_ngwaf_alert_datadog_integration":         dataSourceFastlyNGWAFAlertDatadogIntegration(),
 			"fastly_ngwaf_alert_jira_integration":            dataSourceFastlyNGWAFAlertJiraIntegration(),
 			"fastly_ngwaf_alert_mailing_list_integration":    dataSourceFastlyNGWAFAlertMailingListIntegration(),
@@ -75,14 +120,17 @@ func Provider() *schema.Provider {
 			"fastly_ngwaf_account_rules":                     dataSourceFastlyNGWAFAccountRules(),
 			"fastly_ngwaf_account_signals":                   dataSourceFastlyNGWAFAccountSignals(),
 			"fastly_ngwaf_redactions":                        dataSourceFastlyNGWAFRedactions(),
+			"fastly_ngwaf_rule_test":                         dataSourceFastlyNGWAFRuleTest(),
 			"fastly_ngwaf_thresholds":                        dataSourceFastlyNGWAFThresholds(),
 			"fastly_ngwaf_workspace_lists":                   dataSourceFastlyNGWAFWorkspaceLists(),
 			"fastly_ngwaf_workspace_rules":                   dataSourceFastlyNGWAFWorkspaceRules(),
 			"fastly_ngwaf_workspace_signals":                 dataSourceFastlyNGWAFWorkspaceSignals(),
+			"fastly_ngwaf_workspace_snapshot":                dataSourceFastlyNGWAFWorkspaceSnapshot(),
 			"fastly_ngwaf_virtual_patches":                   dataSourceFastlyNGWAFVirtualPatches(),
 			"fastly_ngwaf_workspaces":                        dataSourceFastlyNGWAFWorkspaces(),
 			"fastly_package_hash":                            dataSourceFastlyPackageHash(),
 			"fastly_secretstores":                            dataSourceFastlySecretStores(),
+			"fastly_service":                                 dataSourceFastlyService(),
 			"fastly_services":                                dataSourceFastlyServices(),
 			"fastly_staging_ips":                             dataSourceFastlyStagingIPs(),
 			"fastly_tls_activation":                          dataSourceFastlyTLSActivation(),
@@ -107,7 +155,7 @@ func Provider() *schema.Provider {
 			"fastly_alert":                                   resourceFastlyAlert(),
 			"fastly_compute_acl_entries":                     resourceFastlyComputeACLEntries(),
 			"fastly_compute_acl":                             resourceFastlyComputeACL(),
-			"fastly_configstore":                             resourceFastlyConfigStore(),
+			"fastly_configstore":                             resourceWithIdentity(resourceFastlyConfigStore()),
 			"fastly_configstore_entries":                     resourceFastlyConfigStoreEntries(),
 			"fastly_custom_dashboard":                        resourceFastlyCustomDashboard(),
 			"fastly_dns_zone":                                resourceFastlyDNSZone(),
@@ -116,7 +164,7 @@ func Provider() *schema.Provider {
 			"fastly_domain_service_link":                     resourceFastlyDomainServiceLink(),
 			"fastly_domain_v1_service_link":                  resourceFastlyDomainServiceLinkV1(),
 			"fastly_integration":                             resourceFastlyIntegration(),
-			"fastly_kvstore":                                 resourceFastlyKVStore(),
+			"fastly_kvstore":                                 resourceWithIdentity(resourceFastlyKVStore()),
 			"fastly_ngwaf_account_list":                      resourceFastlyNGWAFAccountList(),
 			"fastly_ngwaf_account_rule":                      resourceFastlyNGWAFAccountRule(),
 			"fastly_ngwaf_account_signal":                    resourceFastlyNGWAFAccountSignal(),
@@ -128,44 +176,82 @@ func Provider() *schema.Provider {
 			"fastly_ngwaf_alert_pagerduty_integration":       resourceFastlyNGWAFAlertPagerDutyIntegration(),
 			"fastly_ngwaf_alert_slack_integration":           resourceFastlyNGWAFAlertSlackIntegration(),
 			"fastly_ngwaf_alert_webhook_integration":         resourceFastlyNGWAFAlertWebhookIntegration(),
+			"fastly_ngwaf_list_entries":                      resourceFastlyNGWAFListEntries(),
 			"fastly_ngwaf_redaction":                         resourceFastlyNGWAFRedaction(),
 			"fastly_ngwaf_thresholds":                        resourceFastlyNGWAFThresholds(),
 			"fastly_ngwaf_virtual_patches":                   resourceFastlyNGWAFVirtualPatches(),
-			"fastly_ngwaf_workspace":                         resourceFastlyNGWAFWorkspace(),
+			"fastly_ngwaf_workspace":                         resourceWithIdentity(resourceFastlyNGWAFWorkspace()),
 			"fastly_ngwaf_workspace_list":                    resourceFastlyNGWAFWorkspaceList(),
 			"fastly_ngwaf_workspace_rule":                    resourceFastlyNGWAFWorkspaceRule(),
 			"fastly_ngwaf_workspace_signal":                  resourceFastlyNGWAFWorkspaceSignal(),
+			"fastly_ngwaf_workspace_sync":                    resourceFastlyNGWAFWorkspaceSync(),
 			"fastly_object_storage_access_keys":              resourceObjectStorageAccessKey(),
-			"fastly_secretstore":                             resourceFastlySecretStore(),
+			"fastly_secretstore":                             resourceWithIdentity(resourceFastlySecretStore()),
 			"fastly_service_acl_entries":                     resourceServiceACLEntries(),
 			"fastly_service_authorization":                   resourceServiceAuthorization(),
-			"fastly_service_compute":                         resourceServiceCompute(),
+			"fastly_service_compute":                         resourceWithIdentity(resourceServiceCompute()),
 			"fastly_service_dictionary_items":                resourceServiceDictionaryItems(),
 			"fastly_service_dynamic_snippet_content":         resourceServiceDynamicSnippetContent(),
-			"fastly_service_vcl":                             resourceServiceVCL(),
+			"fastly_service_vcl":                             resourceWithIdentity(resourceServiceVCL()),
 			"fastly_tls_activation":                          resourceFastlyTLSActivation(),
+			"fastly_tls_activations":                         resourceFastlyTLSActivations(),
 			"fastly_tsig_key":                                resourceFastlyTSIGKey(),
 			"fastly_tls_certificate":                         resourceFastlyTLSCertificate(),
 			"fastly_tls_mutual_authentication":               resourceFastlyTLSMutualAuthentication(),
 			"fastly_tls_platform_certificate":                resourceFastlyTLSPlatformCertificate(),
 			"fastly_tls_private_key":                         resourceFastlyTLSPrivateKey(),
-			"fastly_tls_subscription":                        resourceFastlyTLSSubscription(),
+			"fastly_tls_subscription":                        resourceWithIdentity(resourceFastlyTLSSubscription()),
 			"fastly_tls_subscription_validation":             resourceFastlyTLSSubscriptionValidation(),
 			"fastly_user":                                    resourceUser(),
 		},
 	}
 
 	provider.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (any, diag.Diagnostics) {
+		guardrails, err := expandGuardrails(d.Get("guardrails").([]any))
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+
+		var tokenExpiryWarning time.Duration
+		if v := d.Get("token_expiry_warning").(string); v != "" {
+			tokenExpiryWarning, err = time.ParseDuration(v)
+			if err != nil {
+				return nil, diag.FromErr(err)
+			}
+		}
+
+		var requiredScopes []string
+		for _, scope := range d.Get("required_scopes").(*schema.Set).List() {
+			requiredScopes = append(requiredScopes, scope.(string))
+		}
+
 		config := Config{
-			APIKey:     d.Get("api_key").(string),
-			BaseURL:    d.Get("base_url").(string),
-			ForceHTTP2: d.Get("force_http2").(bool),
-			NoAuth:     d.Get("no_auth").(bool),
-			UserAgent:  provider.UserAgent(TerraformProviderProductUserAgent, version.ProviderVersion),
-			Context:    ctx,
+			APIKey:             d.Get("api_key").(string),
+			APIKeyFromEnv:      !providerArgumentSet(d.GetRawConfig(), "api_key"),
+			APIKeyCommand:      d.Get("api_key_command").(string),
+			BaseURL:            d.Get("base_url").(string),
+			ConfigFile:         d.Get("config_file").(string),
+			ExpectedCustomerID: d.Get("expected_customer_id").(string),
+			ForceHTTP2:         d.Get("force_http2").(bool),
+			Guardrails:         guardrails,
+			NoAuth:             d.Get("no_auth").(bool),
+			Profile:            d.Get("profile").(string),
+			RequiredScopes:     requiredScopes,
+			TokenExpiryWarning: tokenExpiryWarning,
+			UserAgent:          provider.UserAgent(TerraformProviderProductUserAgent, version.ProviderVersion),
+			Context:            ctx,
 		}
 		return config.Client()
 	}
 
 	return provider
 }
+
+// providerArgumentSet reports whether an argument is set in the provider
+// configuration, as opposed to taken from its DefaultFunc.
+func providerArgumentSet(config cty.Value, name string) bool {
+	if config.IsNull() || !config.IsKnown() || !config.Type().IsObjectType() || !config.Type().HasAttribute(name) {
+		return false
+	}
+	return !config.GetAttr(name).IsNull()
+}
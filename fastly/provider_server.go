@@ -0,0 +1,414 @@
+package fastly
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-mux/tf5muxserver"
+	"github.com/hashicorp/terraform-plugin-mux/tf5to6server"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	"github.com/fastly/terraform-provider-fastly/version"
+)
+
+// ProviderServerFactory returns a function that creates the plugin protocol
+// server for the provider.
+//
+// The server muxes the SDK provider with the terraform-plugin-framework
+// provider in framework_provider.go. The SDK does not support list
+// resources, so the SDK's server is also wrapped to serve the list resources
+// defined in list_resources.go.
+//
+// The provider is served over protocol version 5, which the SDK speaks
+// natively and every supported Terraform version accepts. Use
+// ProviderServerFactoryV6 where a protocol version 6 server is required.
+func ProviderServerFactory(ctx context.Context) (func() tfprotov5.ProviderServer, error) {
+	sdk := Provider()
+
+	servers := []func() tfprotov5.ProviderServer{
+		// The SDK provider must be first so that it is configured before
+		// the framework provider, which reuses its API client.
+		func() tfprotov5.ProviderServer {
+			return newProviderServer(sdk)
+		},
+		providerserver.NewProtocol5(newFrameworkProvider(sdk, version.ProviderVersion)),
+	}
+
+	muxServer, err := tf5muxserver.NewMuxServer(ctx, servers...)
+	if err != nil {
+		return nil, err
+	}
+
+	return muxServer.ProviderServer, nil
+}
+
+// ProviderServerFactoryV6 returns a function that creates a protocol version
+// 6 server for the provider, for muxing it with protocol version 6 providers
+// or testing it with ProtoV6ProviderFactories. It upgrades the server from
+// ProviderServerFactory, so both serve the same resources and list resources.
+func ProviderServerFactoryV6(ctx context.Context) (func() tfprotov6.ProviderServer, error) {
+	v5, err := ProviderServerFactory(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	server, err := tf5to6server.UpgradeServer(ctx, v5)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() tfprotov6.ProviderServer {
+		return server
+	}, nil
+}
+
+// providerServer serves the SDK provider and its list resources.
+type providerServer struct {
+	*schema.GRPCProviderServer
+
+	provider *schema.Provider
+	lists    map[string]*listResource
+}
+
+func newProviderServer(p *schema.Provider) *providerServer {
+	return &providerServer{
+		GRPCProviderServer: schema.NewGRPCProviderServer(p),
+		provider:           p,
+		lists:              listResources(),
+	}
+}
+
+// listIdentityType is the type of the identity of every listed resource, as
+// declared by resourceWithIdentity.
+var listIdentityType = tftypes.Object{AttributeTypes: map[string]tftypes.Type{"id": tftypes.String}}
+
+// GetMetadata adds the list resources to the SDK provider's metadata.
+func (s *providerServer) GetMetadata(ctx context.Context, req *tfprotov5.GetMetadataRequest) (*tfprotov5.GetMetadataResponse, error) {
+	resp, err := s.GRPCProviderServer.GetMetadata(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+
+	for _, name := range s.listNames() {
+		resp.ListResources = append(resp.ListResources, tfprotov5.ListResourceMetadata{TypeName: name})
+	}
+
+	return resp, nil
+}
+
+// GetProviderSchema adds the list resource schemas to the SDK provider's
+// schema.
+func (s *providerServer) GetProviderSchema(ctx context.Context, req *tfprotov5.GetProviderSchemaRequest) (*tfprotov5.GetProviderSchemaResponse, error) {
+	resp, err := s.GRPCProviderServer.GetProviderSchema(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+
+	if resp.ListResourceSchemas == nil {
+		resp.ListResourceSchemas = make(map[string]*tfprotov5.Schema, len(s.lists))
+	}
+	for name, lr := range s.lists {
+		resp.ListResourceSchemas[name] = listResourceProtoSchema(lr.Schema)
+	}
+
+	return resp, nil
+}
+
+// ValidateListResourceConfig validates a list block's config against the list
+// resource schema.
+func (s *providerServer) ValidateListResourceConfig(ctx context.Context, req *tfprotov5.ValidateListResourceConfigRequest) (*tfprotov5.ValidateListResourceConfigResponse, error) {
+	lr, ok := s.lists[req.TypeName]
+	if !ok {
+		return s.GRPCProviderServer.ValidateListResourceConfig(ctx, req)
+	}
+
+	raw, err := listResourceConfig(lr.Schema, req.Config)
+	if err != nil {
+		return &tfprotov5.ValidateListResourceConfigResponse{
+			Diagnostics: protoDiagnostics(diag.FromErr(err)),
+		}, nil
+	}
+
+	diags := (&schema.Resource{Schema: lr.Schema}).Validate(terraform.NewResourceConfigRaw(raw))
+	return &tfprotov5.ValidateListResourceConfigResponse{
+		Diagnostics: protoDiagnostics(diags),
+	}, nil
+}
+
+// ListResource returns the identities, and optionally the full state, of the
+// remote objects matching a list block's config.
+func (s *providerServer) ListResource(ctx context.Context, req *tfprotov5.ListResourceRequest) (*tfprotov5.ListResourceServerStream, error) {
+	lr, ok := s.lists[req.TypeName]
+	if !ok {
+		return s.GRPCProviderServer.ListResource(ctx, req)
+	}
+	res := s.provider.ResourcesMap[req.TypeName]
+	meta := s.provider.Meta()
+
+	items, err := s.listItems(ctx, lr, req.Config, meta)
+	if err != nil {
+		return listResourceError(err), nil
+	}
+
+	return &tfprotov5.ListResourceServerStream{
+		Results: func(yield func(tfprotov5.ListResourceResult) bool) {
+			for i, item := range items {
+				if req.Limit > 0 && int64(i) >= req.Limit {
+					return
+				}
+				if !yield(listResourceResult(ctx, res, lr, item, req.IncludeResource, meta)) {
+					return
+				}
+			}
+		},
+	}, nil
+}
+
+// listItems decodes the list block's config and runs the list resource's
+// List function with it.
+func (s *providerServer) listItems(ctx context.Context, lr *listResource, config *tfprotov5.DynamicValue, meta any) ([]listResourceItem, error) {
+	if meta == nil {
+		return nil, fmt.Errorf("the provider has not been configured")
+	}
+
+	raw, err := listResourceConfig(lr.Schema, config)
+	if err != nil {
+		return nil, err
+	}
+
+	d := (&schema.Resource{Schema: lr.Schema}).Data(nil)
+	for k, v := range raw {
+		if err := d.Set(k, v); err != nil {
+			return nil, fmt.Errorf("error setting %s: %w", k, err)
+		}
+	}
+
+	return lr.List(ctx, d, meta)
+}
+
+// PlanResourceChange returns the warnings recorded with addPlanWarning while
+// planning, which the SDK has no other way to surface from CustomizeDiff.
+func (s *providerServer) PlanResourceChange(ctx context.Context, req *tfprotov5.PlanResourceChangeRequest) (*tfprotov5.PlanResourceChangeResponse, error) {
+	ctx, warnings := withPlanWarnings(ctx)
+
+	resp, err := s.GRPCProviderServer.PlanResourceChange(ctx, req)
+	if resp != nil {
+		resp.Diagnostics = append(resp.Diagnostics, protoDiagnostics(warnings.diagnostics())...)
+	}
+	return resp, err
+}
+
+// listNames returns the names of the list resources in a stable order.
+func (s *providerServer) listNames() []string {
+	names := make([]string, 0, len(s.lists))
+	for name := range s.lists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// listResourceResult builds the result for a single listed remote object.
+// When includeResource is set, the object is read with the resource's
+// importer and Read function, exactly as `terraform import` would.
+func listResourceResult(ctx context.Context, res *schema.Resource, lr *listResource, item listResourceItem, includeResource bool, meta any) tfprotov5.ListResourceResult {
+	result := tfprotov5.ListResourceResult{DisplayName: item.Name}
+	if result.DisplayName == "" {
+		result.DisplayName = item.ID
+	}
+
+	identity, err := tfprotov5.NewDynamicValue(listIdentityType, tftypes.NewValue(listIdentityType, map[string]tftypes.Value{
+		"id": tftypes.NewValue(tftypes.String, item.ID),
+	}))
+	if err != nil {
+		result.Diagnostics = protoDiagnostics(diag.FromErr(err))
+		return result
+	}
+	result.Identity = &tfprotov5.ResourceIdentityData{IdentityData: &identity}
+
+	if !includeResource {
+		return result
+	}
+
+	d, err := exportReadResource(ctx, res, item.ID, meta, lr.Prepare)
+	if err != nil {
+		result.Diagnostics = protoDiagnostics(diag.Errorf("error reading %s (%s): %s", item.Name, item.ID, err))
+		return result
+	}
+
+	state, err := d.TfTypeResourceState()
+	if err != nil {
+		result.Diagnostics = protoDiagnostics(diag.FromErr(err))
+		return result
+	}
+	resource, err := tfprotov5.NewDynamicValue(state.Type(), *state)
+	if err != nil {
+		result.Diagnostics = protoDiagnostics(diag.FromErr(err))
+		return result
+	}
+	result.Resource = &resource
+
+	return result
+}
+
+// listResourceError returns a stream containing a single error result.
+func listResourceError(err error) *tfprotov5.ListResourceServerStream {
+	return &tfprotov5.ListResourceServerStream{
+		Results: func(yield func(tfprotov5.ListResourceResult) bool) {
+			yield(tfprotov5.ListResourceResult{Diagnostics: protoDiagnostics(diag.FromErr(err))})
+		},
+	}
+}
+
+// protoDiagnostics converts SDK diagnostics to protocol diagnostics.
+func protoDiagnostics(diags diag.Diagnostics) []*tfprotov5.Diagnostic {
+	result := make([]*tfprotov5.Diagnostic, 0, len(diags))
+	for _, d := range diags {
+		severity := tfprotov5.DiagnosticSeverityError
+		if d.Severity == diag.Warning {
+			severity = tfprotov5.DiagnosticSeverityWarning
+		}
+		result = append(result, &tfprotov5.Diagnostic{
+			Severity: severity,
+			Summary:  d.Summary,
+			Detail:   d.Detail,
+		})
+	}
+	return result
+}
+
+// listResourceProtoSchema converts the arguments of a list resource to a
+// protocol schema. List resource arguments are limited to primitives and
+// lists or sets of primitives.
+func listResourceProtoSchema(sm map[string]*schema.Schema) *tfprotov5.Schema {
+	names := make([]string, 0, len(sm))
+	for name := range sm {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	block := &tfprotov5.SchemaBlock{}
+	for _, name := range names {
+		s := sm[name]
+		block.Attributes = append(block.Attributes, &tfprotov5.SchemaAttribute{
+			Name:            name,
+			Type:            listResourceAttributeType(s),
+			Description:     s.Description,
+			DescriptionKind: tfprotov5.StringKindPlain,
+			Required:        s.Required,
+			Optional:        s.Optional,
+		})
+	}
+
+	return &tfprotov5.Schema{Block: block}
+}
+
+// listResourceAttributeType returns the protocol type of an argument.
+func listResourceAttributeType(s *schema.Schema) tftypes.Type {
+	switch s.Type {
+	case schema.TypeBool:
+		return tftypes.Bool
+	case schema.TypeInt, schema.TypeFloat:
+		return tftypes.Number
+	case schema.TypeList, schema.TypeSet:
+		elem := tftypes.Type(tftypes.String)
+		if e, ok := s.Elem.(*schema.Schema); ok {
+			elem = listResourceAttributeType(e)
+		}
+		if s.Type == schema.TypeList {
+			return tftypes.List{ElementType: elem}
+		}
+		return tftypes.Set{ElementType: elem}
+	default:
+		return tftypes.String
+	}
+}
+
+// listResourceConfig decodes a list block's config into a map of the values
+// that are set and known.
+func listResourceConfig(sm map[string]*schema.Schema, config *tfprotov5.DynamicValue) (map[string]any, error) {
+	raw := map[string]any{}
+	if config == nil {
+		return raw, nil
+	}
+
+	val, err := config.Unmarshal(listResourceProtoSchema(sm).ValueType())
+	if err != nil {
+		return nil, fmt.Errorf("error decoding list config: %w", err)
+	}
+	if val.IsNull() || !val.IsKnown() {
+		return raw, nil
+	}
+
+	var attrs map[string]tftypes.Value
+	if err := val.As(&attrs); err != nil {
+		return nil, fmt.Errorf("error decoding list config: %w", err)
+	}
+
+	for name, attr := range attrs {
+		v, err := listResourceConfigValue(attr)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding %s: %w", name, err)
+		}
+		if v != nil {
+			raw[name] = v
+		}
+	}
+
+	return raw, nil
+}
+
+// listResourceConfigValue converts a config value to the Go value used by
+// ResourceData. Null and unknown values are returned as nil.
+func listResourceConfigValue(val tftypes.Value) (any, error) {
+	if val.IsNull() || !val.IsKnown() {
+		return nil, nil
+	}
+
+	switch {
+	case val.Type().Is(tftypes.String):
+		var s string
+		err := val.As(&s)
+		return s, err
+	case val.Type().Is(tftypes.Bool):
+		var b bool
+		err := val.As(&b)
+		return b, err
+	case val.Type().Is(tftypes.Number):
+		n := new(big.Float)
+		if err := val.As(&n); err != nil {
+			return nil, err
+		}
+		if i, accuracy := n.Int64(); accuracy == big.Exact {
+			return int(i), nil
+		}
+		f, _ := n.Float64()
+		return f, nil
+	case val.Type().Is(tftypes.List{}), val.Type().Is(tftypes.Set{}):
+		var elems []tftypes.Value
+		if err := val.As(&elems); err != nil {
+			return nil, err
+		}
+		result := make([]any, 0, len(elems))
+		for _, elem := range elems {
+			v, err := listResourceConfigValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			if v != nil {
+				result = append(result, v)
+			}
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", val.Type())
+	}
+}
@@ -0,0 +1,117 @@
+package fastly
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestListResourceConfig(t *testing.T) {
+	sm := listResourceTLSSubscriptions().Schema
+	typ := listResourceProtoSchema(sm).ValueType()
+
+	config, err := tfprotov5.NewDynamicValue(typ, tftypes.NewValue(typ, map[string]tftypes.Value{
+		"certificate_authority": tftypes.NewValue(tftypes.String, "lets-encrypt"),
+		"configuration_id":      tftypes.NewValue(tftypes.String, nil),
+		"domains": tftypes.NewValue(tftypes.Set{ElementType: tftypes.String}, []tftypes.Value{
+			tftypes.NewValue(tftypes.String, "example.com"),
+			tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+		}),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := listResourceConfig(sm, &config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]any{
+		"certificate_authority": "lets-encrypt",
+		"domains":               []any{"example.com"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected config (-want +got):\n%s", diff)
+	}
+}
+
+func TestFilterListResourceItems(t *testing.T) {
+	items := []listResourceItem{
+		{ID: "1", Name: "prod-www"},
+		{ID: "2", Name: "staging-www"},
+		{ID: "3", Name: "prod-api"},
+	}
+
+	d := (&schema.Resource{Schema: listResourceNameSchema("services")}).Data(nil)
+	if got := filterListResourceItems(d, items); len(got) != 3 {
+		t.Errorf("expected all items without a prefix, got %v", got)
+	}
+
+	if err := d.Set("name_prefix", "prod-"); err != nil {
+		t.Fatal(err)
+	}
+	want := []listResourceItem{{ID: "1", Name: "prod-www"}, {ID: "3", Name: "prod-api"}}
+	if diff := cmp.Diff(want, filterListResourceItems(d, items)); diff != "" {
+		t.Errorf("unexpected items (-want +got):\n%s", diff)
+	}
+}
+
+func TestProviderServer_ListResourceSchemas(t *testing.T) {
+	ctx := context.Background()
+	s := newProviderServer(Provider())
+
+	metadata, err := s.GetMetadata(ctx, &tfprotov5.GetMetadataRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemas, err := s.GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	identities, err := s.GetResourceIdentitySchemas(ctx, &tfprotov5.GetResourceIdentitySchemasRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(metadata.ListResources) != len(listResources()) {
+		t.Errorf("expected %d list resources, got %d", len(listResources()), len(metadata.ListResources))
+	}
+	for _, lr := range metadata.ListResources {
+		if _, ok := schemas.ListResourceSchemas[lr.TypeName]; !ok {
+			t.Errorf("missing list resource schema for %s", lr.TypeName)
+		}
+		// Every listed resource must declare the identity that is returned
+		// in list results.
+		if _, ok := identities.IdentitySchemas[lr.TypeName]; !ok {
+			t.Errorf("missing identity schema for %s", lr.TypeName)
+		}
+	}
+}
+
+func TestProviderServerFactoryV6(t *testing.T) {
+	ctx := context.Background()
+
+	factory, err := ProviderServerFactoryV6(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemas, err := factory().GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range schemas.Diagnostics {
+		t.Errorf("unexpected diagnostic: %s: %s", d.Summary, d.Detail)
+	}
+	for name := range listResources() {
+		if _, ok := schemas.ListResourceSchemas[name]; !ok {
+			t.Errorf("missing list resource schema for %s", name)
+		}
+	}
+}
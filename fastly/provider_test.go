@@ -1,15 +1,40 @@
 package fastly
 
 import (
+	"context"
+	"hash/fnv"
+	mathrand "math/rand"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 var (
 	testAccProviders map[string]func() (*schema.Provider, error)
 	testAccProvider  *schema.Provider
+
+	// testAccProtoV5ProviderFactories serve the muxed SDK and framework
+	// providers. Tests of framework resources must use these instead of
+	// testAccProviders.
+	testAccProtoV5ProviderFactories = map[string]func() (tfprotov5.ProviderServer, error){
+		"fastly": func() (tfprotov5.ProviderServer, error) {
+			providerServer, err := ProviderServerFactory(context.Background())
+			if err != nil {
+				return nil, err
+			}
+			return providerServer(), nil
+		},
+	}
 )
 
 func init() {
@@ -31,7 +56,144 @@ func TestProvider_impl(_ *testing.T) {
 	_ = Provider()
 }
 
+func TestProviderArgumentSet(t *testing.T) {
+	config := cty.ObjectVal(map[string]cty.Value{
+		"api_key": cty.StringVal("key"),
+		"profile": cty.NullVal(cty.String),
+	})
+
+	if !providerArgumentSet(config, "api_key") {
+		t.Error("expected api_key to be set")
+	}
+	if providerArgumentSet(config, "profile") {
+		t.Error("expected profile not to be set")
+	}
+	if providerArgumentSet(cty.NullVal(cty.EmptyObject), "api_key") {
+		t.Error("expected api_key not to be set without a configuration")
+	}
+}
+
+func TestProviderServerFactory(t *testing.T) {
+	ctx := context.Background()
+
+	providerServer, err := ProviderServerFactory(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The mux server reports differing provider schemas, and resource types
+	// served by both providers, as diagnostics.
+	resp, err := providerServer().GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range resp.Diagnostics {
+		t.Errorf("%s: %s", d.Summary, d.Detail)
+	}
+}
+
+func TestFrameworkProvider_schema(t *testing.T) {
+	ctx := context.Background()
+	sdk := Provider()
+
+	sdkResp, err := schema.NewGRPCProviderServer(sdk).GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fwResp, err := providerserver.NewProtocol5(newFrameworkProvider(sdk, "test"))().GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sortAttributes := cmpopts.SortSlices(func(a, b *tfprotov5.SchemaAttribute) bool { return a.Name < b.Name })
+	// tf5muxserver ignores block MinItems and MaxItems when comparing the
+	// provider schemas, as the framework enforces them with validators.
+	ignoreItemLimits := cmpopts.IgnoreFields(tfprotov5.SchemaNestedBlock{}, "MinItems", "MaxItems")
+	if diff := cmp.Diff(sdkResp.Provider, fwResp.Provider, sortAttributes, ignoreItemLimits); diff != "" {
+		t.Errorf("framework provider schema differs from the SDK provider schema (-sdk +framework):\n%s", diff)
+	}
+}
+
+// testAccCassette is the provider of an acceptance test that records its API
+// interactions to, or replays them from, a cassette named after the test, when
+// FASTLY_TEST_RECORD=1 or FASTLY_TEST_REPLAY=1 is set. Without either variable
+// it behaves exactly like testAccProviders.
+type testAccCassette struct {
+	// Providers are the provider factories for the test case.
+	Providers map[string]func() (*schema.Provider, error)
+	// Provider is the provider that Providers return. Check functions must
+	// use its API client, so that their requests are recorded too.
+	Provider *schema.Provider
+
+	rand *mathrand.Rand
+}
+
+// testAccCassetteFor returns the cassette provider for a test. Recorded
+// interactions are written when the test finishes.
+func testAccCassetteFor(t *testing.T) *testAccCassette {
+	ct := &testAccCassette{
+		Provider: Provider(),
+	}
+	ct.Providers = map[string]func() (*schema.Provider, error){
+		"fastly": func() (*schema.Provider, error) {
+			return ct.Provider, nil
+		},
+	}
+
+	mode := cassetteModeFromEnv()
+	if mode == cassetteOff {
+		return ct
+	}
+
+	// Resource names must be the same when recording and replaying, as
+	// they are part of the requests that are matched.
+	seed := fnv.New64a()
+	_, _ = seed.Write([]byte(t.Name()))
+	ct.rand = mathrand.New(mathrand.NewSource(int64(seed.Sum64())))
+
+	path := filepath.Join("test_fixtures", "cassettes", strings.ReplaceAll(t.Name(), "/", "_")+".json")
+	c, err := openCassette(mode, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		switch mode {
+		case cassetteRecord:
+			if err := c.save(); err != nil {
+				t.Error(err)
+			}
+		case cassetteReplay:
+			if n := c.unused(); n > 0 {
+				t.Logf("%d interactions of cassette (%s) were not replayed", n, path)
+			}
+		}
+	})
+
+	configure := ct.Provider.ConfigureContextFunc
+	ct.Provider.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (any, diag.Diagnostics) {
+		return configure(withCassette(ctx, c), d)
+	}
+	return ct
+}
+
+// RandString returns a random string of n lowercase letters, like
+// acctest.RandString. When recording or replaying, the string is derived
+// from the test name, so that it is the same on every run.
+func (ct *testAccCassette) RandString(n int) string {
+	if ct.rand == nil {
+		return acctest.RandString(n)
+	}
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('a' + ct.rand.Intn(26))
+	}
+	return string(b)
+}
+
 func testAccPreCheck(t *testing.T) {
+	if cassetteModeFromEnv() == cassetteReplay {
+		return
+	}
 	if v := os.Getenv("FASTLY_API_KEY"); v == "" {
 		t.Fatal("FASTLY_API_KEY must be set for acceptance tests")
 	}
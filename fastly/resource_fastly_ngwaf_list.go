@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -17,20 +18,22 @@ import (
 func resourceFastlyNGWAFWorkspaceList() *schema.Resource {
 	r := resourceFastlyNGWAFListBase()
 
-	r.Importer = customNGWAFScopeImporter(scope.ScopeTypeWorkspace, "list")
+	r.Importer = ngwafListImporter(scope.ScopeTypeWorkspace)
 
 	r.Schema["workspace_id"] = &schema.Schema{
 		Type:     schema.TypeString,
 		Required: true,
 	}
 
+	r.StateUpgraders = []schema.StateUpgrader{ngwafListStateUpgraderV0(r)}
+
 	return r
 }
 
 func resourceFastlyNGWAFAccountList() *schema.Resource {
 	r := resourceFastlyNGWAFListBase()
 
-	r.Importer = customNGWAFScopeImporter(scope.ScopeTypeAccount, "list")
+	r.Importer = ngwafListImporter(scope.ScopeTypeAccount)
 
 	// Internal field for provider logic compatibility.
 	// Not exposed to users but required by shared scope helpers.
@@ -44,6 +47,8 @@ func resourceFastlyNGWAFAccountList() *schema.Resource {
 		},
 	}
 
+	r.StateUpgraders = []schema.StateUpgrader{ngwafListStateUpgraderV0(r)}
+
 	return r
 }
 
@@ -54,6 +59,8 @@ func resourceFastlyNGWAFListBase() *schema.Resource {
 		ReadContext:   resourceFastlyNGWAFListRead,
 		UpdateContext: resourceFastlyNGWAFListUpdate,
 		DeleteContext: resourceFastlyNGWAFListDelete,
+		CustomizeDiff: resourceFastlyNGWAFListCustomizeDiff,
+		SchemaVersion: 1,
 		Schema: map[string]*schema.Schema{
 			"description": {
 				Type:        schema.TypeString,
@@ -62,12 +69,20 @@ func resourceFastlyNGWAFListBase() *schema.Resource {
 			},
 			"entries": {
 				Type:        schema.TypeList,
-				Required:    true,
-				Description: "The values in the list.",
+				Optional:    true,
+				Computed:    true,
+				Description: "The values in the list. Omit to leave the entries unmanaged and out of the state, e.g. when they are managed by `fastly_ngwaf_list_entries`.",
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
 			},
+			// Internal field recording whether the entries are managed, so
+			// that an empty list of entries is still read back.
+			"entries_managed": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "INTERNAL: Whether the entries are configured or imported. Not user-configurable.",
+			},
 			"name": {
 				Type:        schema.TypeString,
 				Required:    true,
@@ -85,6 +100,102 @@ func resourceFastlyNGWAFListBase() *schema.Resource {
 	}
 }
 
+// ngwafListImporter imports a list, including its entries. Like lists with
+// entries in their configuration, imported lists keep their entries in the
+// state until entries is omitted from the configuration.
+func ngwafListImporter(scopeType scope.Type) *schema.ResourceImporter {
+	importer := customNGWAFScopeImporter(scopeType, "list")
+	return &schema.ResourceImporter{
+		StateContext: func(ctx context.Context, d *schema.ResourceData, meta any) ([]*schema.ResourceData, error) {
+			result, err := importer.StateContext(ctx, d, meta)
+			if err != nil {
+				return nil, err
+			}
+
+			rsc, err := resolveScopeAndContext(ctx, d)
+			if err != nil {
+				return nil, err
+			}
+			list, err := lists.Get(rsc.ctx, meta.(*APIClient).conn, &lists.GetInput{
+				ListID: gofastly.ToPointer(d.Id()),
+				Scope:  rsc.scope,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if err := d.Set("entries", list.Entries); err != nil {
+				return nil, err
+			}
+			if err := d.Set("entries_managed", true); err != nil {
+				return nil, err
+			}
+
+			return result, nil
+		},
+	}
+}
+
+// ngwafListStateUpgraderV0 upgrades the state of a list from before entries
+// was optional. Entries were required then, so they are marked as managed.
+func ngwafListStateUpgraderV0(r *schema.Resource) schema.StateUpgrader {
+	v0 := make(map[string]*schema.Schema, len(r.Schema))
+	for k, s := range r.Schema {
+		if k != "entries_managed" {
+			v0[k] = s
+		}
+	}
+
+	return schema.StateUpgrader{
+		Version: 0,
+		Type:    (&schema.Resource{Schema: v0}).CoreConfigSchema().ImpliedType(),
+		Upgrade: upgradeNGWAFListStateV0toV1,
+	}
+}
+
+func upgradeNGWAFListStateV0toV1(_ context.Context, rawState map[string]any, _ any) (map[string]any, error) {
+	if rawState == nil {
+		return rawState, nil
+	}
+
+	log.Println("[DEBUG] Upgrading NGWAF list state from v0 to v1")
+
+	rawState["entries_managed"] = true
+	return rawState, nil
+}
+
+// resourceFastlyNGWAFListCustomizeDiff marks the entries of a list as
+// managed while entries is set in the configuration. Once it is omitted, the
+// entries are removed from the state, so that lists whose entries are
+// managed elsewhere don't store them. The list's entries are left as they
+// are.
+func resourceFastlyNGWAFListCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ any) error {
+	managed := d.Get("entries_managed").(bool)
+
+	if ngwafListEntriesConfigured(d.GetRawConfig()) {
+		if managed {
+			return nil
+		}
+		return d.SetNew("entries_managed", true)
+	}
+
+	if !managed && len(d.Get("entries").([]any)) == 0 {
+		return nil
+	}
+	if err := d.SetNew("entries", []string{}); err != nil {
+		return err
+	}
+	return d.SetNew("entries_managed", false)
+}
+
+// ngwafListEntriesConfigured reports whether entries is set in the
+// configuration of a list. An unavailable configuration is assumed to set it.
+func ngwafListEntriesConfigured(config cty.Value) bool {
+	if config.IsNull() || !config.IsKnown() || !config.Type().IsObjectType() || !config.Type().HasAttribute("entries") {
+		return true
+	}
+	return !config.GetAttr("entries").IsNull()
+}
+
 func resourceFastlyNGWAFListCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	// Inject applies_to=["*"] if it's account-scoped and not explicitly set
 	if _, hasWorkspaceID := d.GetOk("workspace_id"); !hasWorkspaceID {
@@ -113,6 +224,10 @@ func resourceFastlyNGWAFListCreate(ctx context.Context, d *schema.ResourceData,
 
 	d.SetId(list.ListID)
 
+	if err := d.Set("entries_managed", ngwafListEntriesConfigured(d.GetRawConfig())); err != nil {
+		return diag.FromErr(err)
+	}
+
 	return resourceFastlyNGWAFListRead(ctx, d, meta)
 }
 
@@ -220,7 +335,9 @@ func expandNGWAFListUpdateInput(d *schema.ResourceData, scope *scope.Scope) *lis
 		desc := d.Get("description").(string)
 		input.Description = &desc
 	}
-	if d.HasChange("entries") {
+	// Entries that have just become managed are sent even if they match the
+	// state, which doesn't hold the list's entries while they are unmanaged.
+	if d.Get("entries_managed").(bool) && d.HasChanges("entries", "entries_managed") {
 		entries := expandStringList(d.Get("entries").([]any))
 		input.Entries = &entries
 	}
@@ -268,8 +385,12 @@ func flattenNGWAFListResponse(d *schema.ResourceData, list *lists.List) error {
 	if err := d.Set("type", list.Type); err != nil {
 		return fmt.Errorf("error setting type: %w", err)
 	}
-	if err := d.Set("entries", list.Entries); err != nil {
-		return fmt.Errorf("error setting entries: %w", err)
+	// Entries are only stored for lists that manage them, see
+	// resourceFastlyNGWAFListCustomizeDiff.
+	if d.Get("entries_managed").(bool) {
+		if err := d.Set("entries", list.Entries); err != nil {
+			return fmt.Errorf("error setting entries: %w", err)
+		}
 	}
 
 	return nil
@@ -0,0 +1,260 @@
+package fastly
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	gofastly "github.com/fastly/go-fastly/v17/fastly"
+	"github.com/fastly/go-fastly/v17/fastly/ngwaf/v1/lists"
+)
+
+func resourceFastlyNGWAFListEntries() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Manages the entries of a Fastly Next-Gen WAF list from a file.",
+		CreateContext: resourceFastlyNGWAFListEntriesCreate,
+		ReadContext:   resourceFastlyNGWAFListEntriesRead,
+		UpdateContext: resourceFastlyNGWAFListEntriesUpdate,
+		DeleteContext: resourceFastlyNGWAFListEntriesDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceFastlyNGWAFListEntriesImport,
+		},
+		CustomizeDiff: resourceFastlyNGWAFListEntriesCustomizeDiff,
+		Schema: map[string]*schema.Schema{
+			// Internal field for provider logic compatibility, as for
+			// fastly_ngwaf_account_list.
+			"applies_to": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "INTERNAL: Used to build scope for account-scoped lists. Not user-configurable.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"chunk_size": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      10000,
+				Description:  "The maximum number of entries added or removed by each update of the list. Default `10000`.",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"entries_hash": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The SHA-256 of the normalized entries of the list.",
+			},
+			"entry_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of normalized entries in the list.",
+			},
+			"list_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The ID of the list.",
+			},
+			"source_file": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Path to a file with one entry per line. Blank lines and lines starting with `#` are ignored.",
+			},
+			"workspace_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The ID of the workspace of a workspace list. Omit for an account list.",
+			},
+		},
+	}
+}
+
+// resourceFastlyNGWAFListEntriesCustomizeDiff plans an update when the
+// normalized entries of source_file differ from those of the list.
+func resourceFastlyNGWAFListEntriesCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ any) error {
+	if !d.NewValueKnown("source_file") {
+		if err := d.SetNewComputed("entries_hash"); err != nil {
+			return err
+		}
+		return d.SetNewComputed("entry_count")
+	}
+
+	entries, err := readNGWAFListEntriesFile(d.Get("source_file").(string))
+	if err != nil {
+		return err
+	}
+	if hash := hashNGWAFListEntries(entries); hash != d.Get("entries_hash").(string) {
+		if err := d.SetNew("entries_hash", hash); err != nil {
+			return err
+		}
+		return d.SetNew("entry_count", len(entries))
+	}
+	return nil
+}
+
+func resourceFastlyNGWAFListEntriesCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	if _, ok := d.GetOk("workspace_id"); !ok {
+		if err := d.Set("applies_to", []string{"*"}); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	d.SetId(d.Get("list_id").(string))
+
+	return resourceFastlyNGWAFListEntriesUpdate(ctx, d, meta)
+}
+
+// resourceFastlyNGWAFListEntriesRead records only a hash of the list's
+// entries, so that large lists don't bloat the state.
+func resourceFastlyNGWAFListEntriesRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	rsc, err := resolveScopeAndContext(ctx, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	i := &lists.GetInput{
+		ListID: gofastly.ToPointer(d.Id()),
+		Scope:  rsc.scope,
+	}
+
+	log.Printf("[DEBUG] READ: NGWAF %s list entries input: %#v", rsc.scope.Type, i)
+
+	list, err := lists.Get(rsc.ctx, conn, i)
+	if err != nil {
+		if e, ok := err.(*gofastly.HTTPError); ok && e.IsNotFound() {
+			log.Printf("[WARN] NGWAF list (%s) not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	entries := normalizeNGWAFListEntries(list.Entries)
+	if err := d.Set("list_id", list.ListID); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("entries_hash", hashNGWAFListEntries(entries)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("entry_count", len(entries)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// resourceFastlyNGWAFListEntriesUpdate diffs the entries of source_file
+// against those of the list and applies the changes chunk_size at a time.
+// The API replaces a list's entries as a whole, so each update sends the
+// full list with the next chunk of changes applied. If an update fails, the
+// entries applied so far are recorded and the next apply continues from them.
+func resourceFastlyNGWAFListEntriesUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	rsc, err := resolveScopeAndContext(ctx, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	desired, err := readNGWAFListEntriesFile(d.Get("source_file").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	list, err := lists.Get(rsc.ctx, conn, &lists.GetInput{
+		ListID: gofastly.ToPointer(d.Id()),
+		Scope:  rsc.scope,
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	current := normalizeNGWAFListEntries(list.Entries)
+
+	updates := planNGWAFListEntryUpdates(current, desired, d.Get("chunk_size").(int))
+	for n, entries := range updates {
+		i := &lists.UpdateInput{
+			ListID:  gofastly.ToPointer(d.Id()),
+			Scope:   rsc.scope,
+			Entries: &entries,
+		}
+
+		log.Printf("[DEBUG] UPDATE: NGWAF %s list entries update %d of %d with %d entries", rsc.scope.Type, n+1, len(updates), len(entries))
+
+		if _, err := lists.Update(rsc.ctx, conn, i); err != nil {
+			if err := d.Set("entries_hash", hashNGWAFListEntries(current)); err != nil {
+				return diag.FromErr(err)
+			}
+			if err := d.Set("entry_count", len(current)); err != nil {
+				return diag.FromErr(err)
+			}
+			return diag.Errorf("error updating entries of NGWAF list (%s) after %d of %d updates, the next apply continues from there: %s", d.Id(), n, len(updates), err)
+		}
+		current = entries
+	}
+
+	return resourceFastlyNGWAFListEntriesRead(ctx, d, meta)
+}
+
+// resourceFastlyNGWAFListEntriesDelete removes all entries from the list. The
+// list itself is managed by fastly_ngwaf_workspace_list or
+// fastly_ngwaf_account_list.
+func resourceFastlyNGWAFListEntriesDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	rsc, err := resolveScopeAndContext(ctx, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	i := &lists.UpdateInput{
+		ListID:  gofastly.ToPointer(d.Id()),
+		Scope:   rsc.scope,
+		Entries: &[]string{},
+	}
+
+	log.Printf("[DEBUG] DELETE: NGWAF %s list entries input: %#v", rsc.scope.Type, i)
+
+	if _, err := lists.Update(rsc.ctx, conn, i); err != nil {
+		if e, ok := err.(*gofastly.HTTPError); !ok || !e.IsNotFound() {
+			return diag.FromErr(err)
+		}
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+// resourceFastlyNGWAFListEntriesImport accepts `<workspaceID>/<listID>` for
+// workspace lists and `<listID>` for account lists.
+func resourceFastlyNGWAFListEntriesImport(_ context.Context, d *schema.ResourceData, _ any) ([]*schema.ResourceData, error) {
+	if workspaceID, listID, ok := strings.Cut(d.Id(), "/"); ok {
+		if workspaceID == "" || listID == "" {
+			return nil, fmt.Errorf("invalid ID format %q. Expected workspace_id/list_id or list_id", d.Id())
+		}
+		if err := d.Set("workspace_id", workspaceID); err != nil {
+			return nil, err
+		}
+		d.SetId(listID)
+	} else if err := d.Set("applies_to", []string{"*"}); err != nil {
+		return nil, err
+	}
+
+	if err := d.Set("list_id", d.Id()); err != nil {
+		return nil, err
+	}
+	if err := d.Set("chunk_size", 10000); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,95 @@
+package fastly
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccFastlyNGWAFListEntries_basic(t *testing.T) {
+	workspaceName := fmt.Sprintf("tf-ws-%s", acctest.RandString(5))
+	listName := fmt.Sprintf("ws-list-%s", acctest.RandString(5))
+	sourceFile := filepath.Join(t.TempDir(), "entries.txt")
+
+	writeEntries := func(content string) func() {
+		return func() {
+			if err := os.WriteFile(sourceFile, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      nil, // Lists are deleted when workspace is destroyed
+		Steps: []resource.TestStep{
+			{
+				PreConfig: writeEntries("# blocked\n10.0.0.1\n10.0.1.0/24\n10.0.0.1/32\n"),
+				Config:    testAccNGWAFListEntriesConfig(workspaceName, listName, sourceFile, 1),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("fastly_ngwaf_list_entries.example", "entry_count", "2"),
+					resource.TestCheckResourceAttr("fastly_ngwaf_list_entries.example", "entries_hash", hashNGWAFListEntries([]string{"10.0.0.1", "10.0.1.0/24"})),
+				),
+			},
+			{
+				PreConfig: writeEntries("10.0.1.0/24\n192.168.1.1\n172.16.0.1\n"),
+				Config:    testAccNGWAFListEntriesConfig(workspaceName, listName, sourceFile, 1),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("fastly_ngwaf_list_entries.example", "entry_count", "3"),
+					resource.TestCheckResourceAttr("fastly_ngwaf_list_entries.example", "entries_hash", hashNGWAFListEntries([]string{"10.0.1.0/24", "172.16.0.1", "192.168.1.1"})),
+				),
+			},
+			{
+				ResourceName:            "fastly_ngwaf_list_entries.example",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"chunk_size", "source_file"},
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					workspace := s.RootModule().Resources["fastly_ngwaf_workspace.example"]
+					list := s.RootModule().Resources["fastly_ngwaf_workspace_list.example"]
+					return fmt.Sprintf("%s/%s", workspace.Primary.ID, list.Primary.ID), nil
+				},
+			},
+		},
+	})
+}
+
+func testAccNGWAFListEntriesConfig(workspaceName, listName, sourceFile string, chunkSize int) string {
+	return fmt.Sprintf(`
+resource "fastly_ngwaf_workspace" "example" {
+  name                            = "%s"
+  description                     = "Workspace for list entries testing"
+  mode                            = "block"
+  ip_anonymization                = "hashed"
+  client_ip_headers               = ["X-Real-IP"]
+  default_blocking_response_code = 403
+
+  attack_signal_thresholds {
+    one_minute  = 100
+    ten_minutes = 500
+    one_hour    = 1000
+    immediate   = true
+  }
+}
+
+resource "fastly_ngwaf_workspace_list" "example" {
+  workspace_id = fastly_ngwaf_workspace.example.id
+  name         = "%s"
+  description  = "Entries managed by fastly_ngwaf_list_entries"
+  type         = "ip"
+}
+
+resource "fastly_ngwaf_list_entries" "example" {
+  workspace_id = fastly_ngwaf_workspace.example.id
+  list_id      = fastly_ngwaf_workspace_list.example.id
+  source_file  = "%s"
+  chunk_size   = %d
+}
+`, workspaceName, listName, sourceFile, chunkSize)
+}
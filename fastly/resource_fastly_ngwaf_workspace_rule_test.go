@@ -724,6 +724,129 @@ resource "fastly_ngwaf_workspace_rule" "example" {
 `, workspaceName, ruleName)
 }
 
+func TestFlattenNGWAFRuleResponse_expression(t *testing.T) {
+	expression := `path ~ "^/admin"
+  and (method == "DELETE" or ip in list("site.blocked"))`
+
+	schemaMap := resourceFastlyNGWAFWorkspaceRule().Schema
+	d := schema.TestResourceDataRaw(t, schemaMap, map[string]any{
+		"expression": expression,
+	})
+
+	rule := &rules.Rule{
+		RuleID:        "example-rule-id",
+		Type:          "request",
+		Enabled:       true,
+		GroupOperator: "all",
+		Scope: rules.Scope{
+			Type:      "workspace",
+			AppliesTo: []string{"workspace-123"},
+		},
+		Actions: []rules.Action{
+			{Type: "block"},
+		},
+		Conditions: []rules.ConditionItem{
+			{
+				Type:   "single",
+				Fields: rules.SingleCondition{Field: "path", Operator: "matches", Value: "^/admin"},
+			},
+			{
+				Type: "group",
+				Fields: rules.GroupCondition{
+					GroupOperator: "any",
+					Conditions: []rules.GroupConditionItem{
+						{
+							Type:   "single",
+							Fields: rules.Condition{Type: "single", Field: "method", Operator: "equals", Value: "DELETE"},
+						},
+						{
+							Type:   "single",
+							Fields: rules.Condition{Type: "single", Field: "ip", Operator: "in_list", Value: "site.blocked"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// The configured expression is kept while it matches the rule, and the
+	// blocks are left empty.
+	require.NoError(t, flattenNGWAFRuleResponse(d, rule))
+	require.Equal(t, expression, d.Get("expression"))
+	require.Equal(t, "", d.Get("group_operator"))
+	require.Empty(t, d.Get("condition"))
+	require.Empty(t, d.Get("group_condition"))
+
+	// Drift is reported as the canonical expression.
+	rule.GroupOperator = "any"
+	require.NoError(t, flattenNGWAFRuleResponse(d, rule))
+	require.Equal(t, `path ~ "^/admin" or (method == "DELETE" or ip in list("site.blocked"))`, d.Get("expression"))
+}
+
+func TestAccFastlyNGWAFWorkspaceRule_expression(t *testing.T) {
+	workspaceName := fmt.Sprintf("Test WAF Workspace %s", acctest.RandString(5))
+	ruleDescription := fmt.Sprintf("Terraform Rule Expression %s", acctest.RandString(5))
+
+	expression := `path ~ "^/admin" and (method == "DELETE" or ip == "127.0.0.1")`
+	updatedExpression := `request_header exists (name == "X-API-Key" and value contains "test") or path == "/login"`
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      nil, // Rule is deleted implicitly when workspace is destroyed
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNGWAFWorkspaceRuleConfigExpression(workspaceName, ruleDescription, expression),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("fastly_ngwaf_workspace_rule.example", "description", ruleDescription),
+					resource.TestCheckResourceAttr("fastly_ngwaf_workspace_rule.example", "expression", expression),
+					resource.TestCheckResourceAttr("fastly_ngwaf_workspace_rule.example", "condition.#", "0"),
+					resource.TestCheckResourceAttr("fastly_ngwaf_workspace_rule.example", "group_condition.#", "0"),
+				),
+			},
+			{
+				Config: testAccNGWAFWorkspaceRuleConfigExpression(workspaceName, ruleDescription, updatedExpression),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("fastly_ngwaf_workspace_rule.example", "expression", updatedExpression),
+					resource.TestCheckResourceAttr("fastly_ngwaf_workspace_rule.example", "multival_condition.#", "0"),
+				),
+			},
+			{
+				Config:      testAccNGWAFWorkspaceRuleConfigExpression(workspaceName, ruleDescription, `path == "/a" and method == "GET" or ip == "127.0.0.1"`),
+				ExpectError: regexp.MustCompile(`invalid expression at 1:34: cannot mix "and" and "or"`),
+			},
+		},
+	})
+}
+
+func testAccNGWAFWorkspaceRuleConfigExpression(workspaceName, ruleName, expression string) string {
+	return fmt.Sprintf(`
+resource "fastly_ngwaf_workspace" "example" {
+  name                            = "%s"
+  description                     = "Test NGWAF Workspace"
+  mode                            = "block"
+  ip_anonymization                = "hashed"
+  client_ip_headers               = ["X-Forwarded-For", "X-Real-IP"]
+  default_blocking_response_code = 429
+
+  attack_signal_thresholds {}
+}
+
+resource "fastly_ngwaf_workspace_rule" "example" {
+  workspace_id     = fastly_ngwaf_workspace.example.id
+  type             = "request"
+  description      = "%s"
+  enabled          = true
+  request_logging  = "sampled"
+  expression       = %q
+
+  action {
+    type = "block"
+  }
+}
+`, workspaceName, ruleName, expression)
+}
+
 func TestAccFastlyNGWAFWorkspaceRule_rateLimit(t *testing.T) {
 	workspaceName := fmt.Sprintf("Test WAF Workspace %s", acctest.RandString(5))
 	ruleDescription := "some description"
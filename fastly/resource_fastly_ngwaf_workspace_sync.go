@@ -0,0 +1,648 @@
+package fastly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	gofastly "github.com/fastly/go-fastly/v17/fastly"
+	"github.com/fastly/go-fastly/v17/fastly/ngwaf/v1/lists"
+	"github.com/fastly/go-fastly/v17/fastly/ngwaf/v1/rules"
+	"github.com/fastly/go-fastly/v17/fastly/ngwaf/v1/scope"
+	"github.com/fastly/go-fastly/v17/fastly/ngwaf/v1/signals"
+	"github.com/fastly/go-fastly/v17/fastly/ngwaf/v1/workspaces/redactions"
+	"github.com/fastly/go-fastly/v17/fastly/ngwaf/v1/workspaces/thresholds"
+	"github.com/fastly/go-fastly/v17/fastly/ngwaf/v1/workspaces/virtualpatches"
+)
+
+func resourceFastlyNGWAFWorkspaceSync() *schema.Resource {
+	idMap := func(description string) *schema.Schema {
+		return &schema.Schema{
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Description: description,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		}
+	}
+
+	return &schema.Resource{
+		Description:   "Applies a snapshot of the configuration of a Fastly Next-Gen WAF workspace to another workspace.",
+		CreateContext: resourceFastlyNGWAFWorkspaceSyncCreate,
+		ReadContext:   resourceFastlyNGWAFWorkspaceSyncRead,
+		UpdateContext: resourceFastlyNGWAFWorkspaceSyncUpdate,
+		DeleteContext: resourceFastlyNGWAFWorkspaceSyncDelete,
+		CustomizeDiff: resourceFastlyNGWAFWorkspaceSyncCustomizeDiff,
+		Schema: map[string]*schema.Schema{
+			"adopted_ids": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Description: "The IDs of the managed lists, redactions, signals and thresholds that already existed in the workspace when the snapshot was first applied. Unless `delete_adopted` is set, they are left in the workspace when they are removed from the snapshot or the sync is destroyed.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"delete_adopted": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether objects that already existed in the workspace and were adopted by the sync are deleted when they are removed from the snapshot or the sync is destroyed. Default `false`.",
+			},
+			"list_ids":      idMap("The IDs of the lists managed in the workspace, by name."),
+			"redaction_ids": idMap("The IDs of the redactions managed in the workspace, by `<type>/<field>`."),
+			"reference_ids": idMap("The reference IDs of the lists and signals in the workspace, by their reference IDs in the snapshot."),
+			"rule_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The IDs of the rules managed in the workspace, in the order of the rules of the snapshot.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"signal_ids": idMap("The IDs of the signals managed in the workspace, by name."),
+			"snapshot": {
+				Type:             schema.TypeString,
+				Required:         true,
+				Description:      "The snapshot to apply, as JSON. Usually the `json` attribute of a `fastly_ngwaf_workspace_snapshot` data source.",
+				DiffSuppressFunc: suppressEquivalentNGWAFWorkspaceSnapshot,
+				ValidateDiagFunc: validateNGWAFWorkspaceSnapshot(),
+			},
+			"threshold_ids": idMap("The IDs of the thresholds managed in the workspace, by name."),
+			"workspace_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The ID of the workspace to apply the snapshot to.",
+			},
+		},
+	}
+}
+
+// suppressEquivalentNGWAFWorkspaceSnapshot suppresses differences in
+// formatting and ordering between snapshots.
+func suppressEquivalentNGWAFWorkspaceSnapshot(_, oldValue, newValue string, _ *schema.ResourceData) bool {
+	o, err := parseNGWAFWorkspaceSnapshot(oldValue)
+	if err != nil {
+		return false
+	}
+	n, err := parseNGWAFWorkspaceSnapshot(newValue)
+	if err != nil {
+		return false
+	}
+	oj, err := o.json()
+	if err != nil {
+		return false
+	}
+	nj, err := n.json()
+	return err == nil && oj == nj
+}
+
+func resourceFastlyNGWAFWorkspaceSyncCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ any) error {
+	if !d.HasChange("snapshot") {
+		return nil
+	}
+	for _, key := range []string{"adopted_ids", "list_ids", "redaction_ids", "reference_ids", "rule_ids", "signal_ids", "threshold_ids"} {
+		if err := d.SetNewComputed(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readNGWAFWorkspaceSyncState(d *schema.ResourceData) *ngwafWorkspaceSyncState {
+	ids := func(key string) map[string]string {
+		result := map[string]string{}
+		for k, v := range d.Get(key).(map[string]any) {
+			result[k] = v.(string)
+		}
+		return result
+	}
+
+	adopted := map[string]bool{}
+	for _, id := range d.Get("adopted_ids").(*schema.Set).List() {
+		adopted[id.(string)] = true
+	}
+
+	return &ngwafWorkspaceSyncState{
+		adopted:    adopted,
+		lists:      ids("list_ids"),
+		redactions: ids("redaction_ids"),
+		references: ids("reference_ids"),
+		rules:      expandStringList(d.Get("rule_ids").([]any)),
+		signals:    ids("signal_ids"),
+		thresholds: ids("threshold_ids"),
+	}
+}
+
+func (st *ngwafWorkspaceSyncState) write(d *schema.ResourceData) error {
+	adopted := make([]string, 0, len(st.adopted))
+	for id := range st.adopted {
+		adopted = append(adopted, id)
+	}
+
+	for key, value := range map[string]any{
+		"adopted_ids":   adopted,
+		"list_ids":      st.lists,
+		"redaction_ids": st.redactions,
+		"reference_ids": st.references,
+		"rule_ids":      st.rules,
+		"signal_ids":    st.signals,
+		"threshold_ids": st.thresholds,
+	} {
+		if err := d.Set(key, value); err != nil {
+			return fmt.Errorf("error setting %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func resourceFastlyNGWAFWorkspaceSyncCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	d.SetId(d.Get("workspace_id").(string))
+
+	return resourceFastlyNGWAFWorkspaceSyncUpdate(ctx, d, meta)
+}
+
+// resourceFastlyNGWAFWorkspaceSyncRead detects drift of the managed objects
+// from the applied snapshot, and records the observed configuration in place
+// of the snapshot so that the next apply restores it.
+func resourceFastlyNGWAFWorkspaceSyncRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	log.Printf("[DEBUG] REFRESH: NGWAF workspace sync for workspace: %s", d.Id())
+
+	target, err := readNGWAFWorkspaceSnapshot(ctx, conn, d.Id())
+	if err != nil {
+		var e *gofastly.HTTPError
+		if errors.As(err, &e) && e.IsNotFound() {
+			log.Printf("[WARN] NGWAF workspace (%s) not found, removing sync from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	applied, err := parseNGWAFWorkspaceSnapshot(d.Get("snapshot").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	state := readNGWAFWorkspaceSyncState(d)
+	observed, err := state.observe(target, applied)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	appliedJSON, err := applied.json()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	observedJSON, err := observed.json()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if observedJSON != appliedJSON {
+		log.Printf("[DEBUG] REFRESH: NGWAF workspace (%s) differs from the applied snapshot", d.Id())
+		if err := d.Set("snapshot", observedJSON); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if err := d.Set("workspace_id", d.Id()); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := state.write(d); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func resourceFastlyNGWAFWorkspaceSyncUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	snapshot, err := parseNGWAFWorkspaceSnapshot(d.Get("snapshot").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	state := readNGWAFWorkspaceSyncState(d)
+	applyErr := applyNGWAFWorkspaceSnapshot(ctx, conn, d.Id(), snapshot, state, d.Get("delete_adopted").(bool))
+
+	// The IDs of the objects applied so far are recorded even if the sync
+	// failed part way, so that the next apply updates rather than duplicates
+	// them. The previous snapshot is kept so that the next apply retries.
+	if err := state.write(d); err != nil {
+		return diag.FromErr(err)
+	}
+	if applyErr != nil {
+		if o, _ := d.GetChange("snapshot"); o.(string) != "" {
+			if err := d.Set("snapshot", o); err != nil {
+				return diag.FromErr(err)
+			}
+		}
+		return diag.FromErr(applyErr)
+	}
+
+	return resourceFastlyNGWAFWorkspaceSyncRead(ctx, d, meta)
+}
+
+// resourceFastlyNGWAFWorkspaceSyncDelete removes the lists, redactions,
+// rules, signals and thresholds created by the sync from the workspace.
+// Adopted objects are only deleted if delete_adopted is set, and virtual
+// patches are left as they are. A sync tainted by a failed create is
+// destroyed the same way, so objects that existed before it are kept.
+func resourceFastlyNGWAFWorkspaceSyncDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	state := readNGWAFWorkspaceSyncState(d)
+	if err := applyNGWAFWorkspaceSnapshot(ctx, conn, d.Id(), &ngwafWorkspaceSnapshot{}, state, d.Get("delete_adopted").(bool)); err != nil {
+		var e *gofastly.HTTPError
+		if errors.As(err, &e) && e.IsNotFound() {
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// applyNGWAFWorkspaceSnapshot brings the objects of a workspace managed by a
+// sync in line with a snapshot, recording their IDs in state as it goes.
+//
+// Signals are applied first, as lists, rules and thresholds refer to them,
+// then lists, as rules refer to them. The reference IDs of the lists and
+// signals in the workspace replace those of the snapshot before the lists,
+// rules and thresholds referring to them are applied. Objects removed from
+// the snapshot are deleted in the reverse order. Lists, signals and
+// thresholds that already exist in the workspace under the same name, and
+// identical redactions, are adopted: they are updated and managed from then
+// on, but only deleted if deleteAdopted is set.
+func applyNGWAFWorkspaceSnapshot(ctx context.Context, conn *gofastly.Client, workspaceID string, snapshot *ngwafWorkspaceSnapshot, state *ngwafWorkspaceSyncState, deleteAdopted bool) error {
+	target, err := readNGWAFWorkspaceSnapshot(ctx, conn, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	ctx = gofastly.NewContextForResourceID(ctx, workspaceID)
+	scopeObj := &scope.Scope{
+		Type:      scope.ScopeTypeWorkspace,
+		AppliesTo: []string{workspaceID},
+	}
+	ignoreNotFound := func(err error) error {
+		if e, ok := err.(*gofastly.HTTPError); ok && e.IsNotFound() {
+			return nil
+		}
+		return err
+	}
+
+	state.references = map[string]string{}
+
+	targetSignals := map[string]ngwafSnapshotSignal{}
+	for _, s := range target.Signals {
+		targetSignals[s.Name] = s
+	}
+	for _, s := range snapshot.Signals {
+		id := state.signals[s.Name]
+		if id == "" {
+			id = state.adopt(targetSignals[s.Name].id)
+		}
+
+		var signal *signals.Signal
+		if id != "" {
+			log.Printf("[DEBUG] SYNC: updating NGWAF signal %q (%s)", s.Name, id)
+			signal, err = signals.Update(ctx, conn, &signals.UpdateInput{
+				Description: gofastly.ToPointer(s.Description),
+				SignalID:    gofastly.ToPointer(id),
+				Scope:       scopeObj,
+			})
+		} else {
+			log.Printf("[DEBUG] SYNC: creating NGWAF signal %q", s.Name)
+			signal, err = signals.Create(ctx, conn, &signals.CreateInput{
+				Description: gofastly.ToPointer(s.Description),
+				Name:        gofastly.ToPointer(s.Name),
+				Scope:       scopeObj,
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("error applying signal %q: %w", s.Name, err)
+		}
+		state.signals[s.Name] = signal.SignalID
+		if s.ReferenceID != "" {
+			state.references[s.ReferenceID] = signal.ReferenceID
+		}
+	}
+
+	desired, err := snapshot.remap(state.references)
+	if err != nil {
+		return err
+	}
+	targetLists := map[string]ngwafSnapshotList{}
+	for _, l := range target.Lists {
+		targetLists[l.Name] = l
+	}
+	for i, l := range desired.Lists {
+		id := state.lists[l.Name]
+		if id == "" {
+			id = state.adopt(targetLists[l.Name].id)
+		}
+		if t, ok := targetLists[l.Name]; ok && t.id == id && t.Type != l.Type {
+			return fmt.Errorf("error applying list %q: the list has type %q in the workspace, not %q", l.Name, t.Type, l.Type)
+		}
+
+		entries := l.Entries
+		var list *lists.List
+		if id != "" {
+			log.Printf("[DEBUG] SYNC: updating NGWAF list %q (%s)", l.Name, id)
+			list, err = lists.Update(ctx, conn, &lists.UpdateInput{
+				Description: gofastly.ToPointer(l.Description),
+				Entries:     &entries,
+				ListID:      gofastly.ToPointer(id),
+				Scope:       scopeObj,
+			})
+		} else {
+			log.Printf("[DEBUG] SYNC: creating NGWAF list %q", l.Name)
+			list, err = lists.Create(ctx, conn, &lists.CreateInput{
+				Description: gofastly.ToPointer(l.Description),
+				Entries:     &entries,
+				Name:        gofastly.ToPointer(l.Name),
+				Scope:       scopeObj,
+				Type:        gofastly.ToPointer(l.Type),
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("error applying list %q: %w", l.Name, err)
+		}
+		state.lists[l.Name] = list.ListID
+		if ref := snapshot.Lists[i].ReferenceID; ref != "" {
+			state.references[ref] = list.ReferenceID
+		}
+	}
+
+	desired, err = snapshot.remap(state.references)
+	if err != nil {
+		return err
+	}
+
+	targetRules := map[string]ngwafSnapshotRule{}
+	for _, r := range target.Rules {
+		targetRules[r.id] = r
+	}
+	for i, r := range desired.Rules {
+		var id string
+		if i < len(state.rules) {
+			id = state.rules[i]
+		}
+		id, err = applyNGWAFWorkspaceSnapshotRule(ctx, conn, workspaceID, scopeObj, id, targetRules, r)
+		if err != nil {
+			return fmt.Errorf("error applying rule %q: %w", r.Description, err)
+		}
+		if i < len(state.rules) {
+			state.rules[i] = id
+		} else {
+			state.rules = append(state.rules, id)
+		}
+	}
+	for len(state.rules) > len(desired.Rules) {
+		id := state.rules[len(state.rules)-1]
+		log.Printf("[DEBUG] SYNC: deleting NGWAF rule %s", id)
+		if err := ignoreNotFound(rules.Delete(ctx, conn, &rules.DeleteInput{
+			RuleID: gofastly.ToPointer(id),
+			Scope:  scopeObj,
+		})); err != nil {
+			return fmt.Errorf("error deleting rule (%s): %w", id, err)
+		}
+		state.rules = state.rules[:len(state.rules)-1]
+	}
+
+	targetThresholds := map[string]ngwafSnapshotThreshold{}
+	for _, t := range target.Thresholds {
+		targetThresholds[t.Name] = t
+	}
+	desiredThresholds := map[string]bool{}
+	for _, t := range desired.Thresholds {
+		desiredThresholds[t.Name] = true
+
+		id := state.thresholds[t.Name]
+		if id == "" {
+			id = state.adopt(targetThresholds[t.Name].id)
+		}
+		if id != "" {
+			log.Printf("[DEBUG] SYNC: updating NGWAF threshold %q (%s)", t.Name, id)
+			_, err = thresholds.Update(ctx, conn, &thresholds.UpdateInput{
+				Action:      gofastly.ToPointer(t.Action),
+				DontNotify:  gofastly.ToPointer(t.DontNotify),
+				Duration:    gofastly.ToPointer(t.Duration),
+				Enabled:     gofastly.ToPointer(t.Enabled),
+				Interval:    gofastly.ToPointer(t.Interval),
+				Limit:       gofastly.ToPointer(t.Limit),
+				Name:        gofastly.ToPointer(t.Name),
+				Signal:      gofastly.ToPointer(t.Signal),
+				ThresholdID: gofastly.ToPointer(id),
+				WorkspaceID: gofastly.ToPointer(workspaceID),
+			})
+		} else {
+			log.Printf("[DEBUG] SYNC: creating NGWAF threshold %q", t.Name)
+			var threshold *thresholds.Threshold
+			threshold, err = thresholds.Create(ctx, conn, &thresholds.CreateInput{
+				Action:      gofastly.ToPointer(t.Action),
+				DontNotify:  gofastly.ToPointer(t.DontNotify),
+				Duration:    gofastly.ToPointer(t.Duration),
+				Enabled:     gofastly.ToPointer(t.Enabled),
+				Interval:    gofastly.ToPointer(t.Interval),
+				Limit:       gofastly.ToPointer(t.Limit),
+				Name:        gofastly.ToPointer(t.Name),
+				Signal:      gofastly.ToPointer(t.Signal),
+				WorkspaceID: gofastly.ToPointer(workspaceID),
+			})
+			if err == nil {
+				id = threshold.ThresholdID
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("error applying threshold %q: %w", t.Name, err)
+		}
+		state.thresholds[t.Name] = id
+	}
+	for name, id := range state.thresholds {
+		if desiredThresholds[name] {
+			continue
+		}
+		if state.release(id, deleteAdopted) {
+			log.Printf("[DEBUG] SYNC: releasing adopted NGWAF threshold %q (%s)", name, id)
+		} else {
+			log.Printf("[DEBUG] SYNC: deleting NGWAF threshold %q (%s)", name, id)
+			if err := ignoreNotFound(thresholds.Delete(ctx, conn, &thresholds.DeleteInput{
+				ThresholdID: gofastly.ToPointer(id),
+				WorkspaceID: gofastly.ToPointer(workspaceID),
+			})); err != nil {
+				return fmt.Errorf("error deleting threshold %q: %w", name, err)
+			}
+		}
+		delete(state.thresholds, name)
+	}
+
+	targetRedactions := map[string]ngwafSnapshotRedaction{}
+	for _, r := range target.Redactions {
+		targetRedactions[r.key()] = r
+	}
+	desiredRedactions := map[string]bool{}
+	for _, r := range desired.Redactions {
+		desiredRedactions[r.key()] = true
+
+		id := state.redactions[r.key()]
+		if id == "" {
+			id = state.adopt(targetRedactions[r.key()].id)
+		}
+		if id == "" {
+			log.Printf("[DEBUG] SYNC: creating NGWAF redaction %q", r.key())
+			redaction, err := redactions.Create(ctx, conn, &redactions.CreateInput{
+				Field:       gofastly.ToPointer(r.Field),
+				Type:        gofastly.ToPointer(r.Type),
+				WorkspaceID: gofastly.ToPointer(workspaceID),
+			})
+			if err != nil {
+				return fmt.Errorf("error applying redaction %q: %w", r.key(), err)
+			}
+			id = redaction.RedactionID
+		}
+		state.redactions[r.key()] = id
+	}
+	for key, id := range state.redactions {
+		if desiredRedactions[key] {
+			continue
+		}
+		if state.release(id, deleteAdopted) {
+			log.Printf("[DEBUG] SYNC: releasing adopted NGWAF redaction %q (%s)", key, id)
+		} else {
+			log.Printf("[DEBUG] SYNC: deleting NGWAF redaction %q (%s)", key, id)
+			if err := ignoreNotFound(redactions.Delete(ctx, conn, &redactions.DeleteInput{
+				RedactionID: gofastly.ToPointer(id),
+				WorkspaceID: gofastly.ToPointer(workspaceID),
+			})); err != nil {
+				return fmt.Errorf("error deleting redaction %q: %w", key, err)
+			}
+		}
+		delete(state.redactions, key)
+	}
+
+	for _, vp := range desired.VirtualPatches {
+		log.Printf("[DEBUG] SYNC: updating NGWAF virtual patch %s", vp.ID)
+		if _, err := virtualpatches.Update(ctx, conn, &virtualpatches.UpdateInput{
+			Enabled:        gofastly.ToPointer(vp.Enabled),
+			Mode:           gofastly.ToPointer(vp.Mode),
+			VirtualPatchID: gofastly.ToPointer(vp.ID),
+			WorkspaceID:    gofastly.ToPointer(workspaceID),
+		}); err != nil {
+			return fmt.Errorf("error applying virtual patch %s: %w", vp.ID, err)
+		}
+	}
+
+	desiredLists := map[string]bool{}
+	for _, l := range desired.Lists {
+		desiredLists[l.Name] = true
+	}
+	for name, id := range state.lists {
+		if desiredLists[name] {
+			continue
+		}
+		if state.release(id, deleteAdopted) {
+			log.Printf("[DEBUG] SYNC: releasing adopted NGWAF list %q (%s)", name, id)
+		} else {
+			log.Printf("[DEBUG] SYNC: deleting NGWAF list %q (%s)", name, id)
+			if err := ignoreNotFound(lists.Delete(ctx, conn, &lists.DeleteInput{
+				ListID: gofastly.ToPointer(id),
+				Scope:  scopeObj,
+			})); err != nil {
+				return fmt.Errorf("error deleting list %q: %w", name, err)
+			}
+		}
+		delete(state.lists, name)
+	}
+
+	desiredSignals := map[string]bool{}
+	for _, s := range desired.Signals {
+		desiredSignals[s.Name] = true
+	}
+	for name, id := range state.signals {
+		if desiredSignals[name] {
+			continue
+		}
+		if state.release(id, deleteAdopted) {
+			log.Printf("[DEBUG] SYNC: releasing adopted NGWAF signal %q (%s)", name, id)
+		} else {
+			log.Printf("[DEBUG] SYNC: deleting NGWAF signal %q (%s)", name, id)
+			if err := ignoreNotFound(signals.Delete(ctx, conn, &signals.DeleteInput{
+				SignalID: gofastly.ToPointer(id),
+				Scope:    scopeObj,
+			})); err != nil {
+				return fmt.Errorf("error deleting signal %q: %w", name, err)
+			}
+		}
+		delete(state.signals, name)
+	}
+
+	return nil
+}
+
+// applyNGWAFWorkspaceSnapshotRule creates or updates the rule with the given
+// ID, if any, returning its ID. Unchanged rules are left alone. Rules that
+// change type, and changed templated_signal rules, are replaced, as for
+// fastly_ngwaf_workspace_rule. The rule's input is built as for that
+// resource, from ResourceData holding the rule.
+func applyNGWAFWorkspaceSnapshotRule(ctx context.Context, conn *gofastly.Client, workspaceID string, scopeObj *scope.Scope, id string, targetRules map[string]ngwafSnapshotRule, r ngwafSnapshotRule) (string, error) {
+	existing, exists := targetRules[id]
+	if exists && existing.key() == r.key() {
+		return id, nil
+	}
+
+	d := resourceFastlyNGWAFWorkspaceRule().Data(nil)
+	for key, value := range flattenNGWAFSnapshotRules([]ngwafSnapshotRule{r})[0] {
+		if err := d.Set(key, value); err != nil {
+			return id, fmt.Errorf("error setting %s: %w", key, err)
+		}
+	}
+	if err := d.Set("workspace_id", workspaceID); err != nil {
+		return id, err
+	}
+
+	if exists && existing.Type == r.Type && r.Type != "templated_signal" {
+		d.SetId(id)
+		i, err := expandNGWAFRuleUpdateInput(d, scopeObj)
+		if err != nil {
+			return id, err
+		}
+
+		log.Printf("[DEBUG] SYNC: updating NGWAF rule %s", id)
+
+		_, err = rules.Update(ctx, conn, i)
+		return id, err
+	}
+
+	if exists {
+		log.Printf("[DEBUG] SYNC: replacing NGWAF rule %s", id)
+		if err := rules.Delete(ctx, conn, &rules.DeleteInput{
+			RuleID: gofastly.ToPointer(id),
+			Scope:  scopeObj,
+		}); err != nil {
+			return id, err
+		}
+	}
+
+	i, err := expandNGWAFRuleCreateInput(d, scopeObj)
+	if err != nil {
+		return id, err
+	}
+
+	log.Printf("[DEBUG] SYNC: creating NGWAF rule")
+
+	rule, err := rules.Create(ctx, conn, i)
+	if err != nil {
+		return id, err
+	}
+	return rule.RuleID, nil
+}
@@ -0,0 +1,212 @@
+package fastly
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccFastlyNGWAFWorkspaceSync_basic(t *testing.T) {
+	h := generateHex()
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      nil, // Synced objects are deleted when the target workspace is destroyed
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNGWAFWorkspaceSyncConfig(h, "10.0.0.1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("fastly_ngwaf_workspace_sync.example", "workspace_id", "fastly_ngwaf_workspace.target", "id"),
+					resource.TestCheckResourceAttr("fastly_ngwaf_workspace_sync.example", "list_ids.%", "1"),
+					resource.TestCheckResourceAttr("fastly_ngwaf_workspace_sync.example", "signal_ids.%", "1"),
+					resource.TestCheckResourceAttr("fastly_ngwaf_workspace_sync.example", "rule_ids.#", "1"),
+					testAccCheckNGWAFWorkspaceSyncReference("fastly_ngwaf_workspace_signal.example"),
+				),
+			},
+			{
+				Config: testAccNGWAFWorkspaceSyncConfig(h, "10.0.0.2"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.fastly_ngwaf_workspace_snapshot.target", "lists.0.entries.0", "10.0.0.2"),
+					resource.TestCheckResourceAttrPair(
+						"data.fastly_ngwaf_workspace_snapshot.target", "rules.0.action.0.signal",
+						"data.fastly_ngwaf_workspace_snapshot.target", "signals.0.reference_id",
+					),
+				),
+			},
+		},
+	})
+}
+
+func TestAccFastlyNGWAFWorkspaceSync_adopted(t *testing.T) {
+	h := generateHex()
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      nil, // Synced objects are deleted when the target workspace is destroyed
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNGWAFWorkspaceSyncConfigAdopted(h, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("fastly_ngwaf_workspace_sync.example", "adopted_ids.#", "1"),
+					resource.TestCheckTypeSetElemAttrPair("fastly_ngwaf_workspace_sync.example", "adopted_ids.*", "fastly_ngwaf_workspace_list.existing", "id"),
+					resource.TestCheckResourceAttrPair("fastly_ngwaf_workspace_sync.example", "list_ids.blocked-"+h, "fastly_ngwaf_workspace_list.existing", "id"),
+				),
+			},
+			{
+				// Destroying the sync keeps the adopted list, so the list
+				// resource sees no drift.
+				Config: testAccNGWAFWorkspaceSyncConfigAdopted(h, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("fastly_ngwaf_workspace_list.existing", "entries.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func testAccNGWAFWorkspaceSyncConfigAdopted(h string, sync bool) string {
+	config := fmt.Sprintf(`
+%s
+
+resource "fastly_ngwaf_workspace" "target" {
+  name                           = "tf_%s_target"
+  description                    = "Target NGWAF Workspace"
+  mode                           = "log"
+  ip_anonymization               = "hashed"
+  client_ip_headers              = ["X-Real-IP"]
+  default_blocking_response_code = 403
+
+  attack_signal_thresholds {
+    one_minute  = 100
+    ten_minutes = 500
+    one_hour    = 1000
+    immediate   = true
+  }
+}
+
+resource "fastly_ngwaf_workspace_list" "example" {
+  workspace_id = fastly_ngwaf_workspace.example.id
+  name         = "blocked-%s"
+  description  = "Blocked addresses"
+  type         = "ip"
+  entries      = ["10.0.0.1"]
+}
+
+resource "fastly_ngwaf_workspace_list" "existing" {
+  workspace_id = fastly_ngwaf_workspace.target.id
+  name         = "blocked-%s"
+  description  = "Blocked addresses"
+  type         = "ip"
+  entries      = ["10.0.0.1"]
+}
+`, testAccNGWAFWorkspaceConfig(fmt.Sprintf("tf_%s", h)), h, h, h)
+	if !sync {
+		return config
+	}
+	return config + `
+data "fastly_ngwaf_workspace_snapshot" "source" {
+  workspace_id = fastly_ngwaf_workspace.example.id
+
+  depends_on = [fastly_ngwaf_workspace_list.example]
+}
+
+resource "fastly_ngwaf_workspace_sync" "example" {
+  workspace_id = fastly_ngwaf_workspace.target.id
+  snapshot     = data.fastly_ngwaf_workspace_snapshot.source.json
+
+  depends_on = [fastly_ngwaf_workspace_list.existing]
+}
+`
+}
+
+// testAccCheckNGWAFWorkspaceSyncReference checks that the reference ID of a
+// signal in the source workspace was mapped to a signal in the target
+// workspace.
+func testAccCheckNGWAFWorkspaceSyncReference(signal string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		source := s.RootModule().Resources[signal].Primary.Attributes["reference_id"]
+		a := s.RootModule().Resources["fastly_ngwaf_workspace_sync.example"].Primary.Attributes
+		target, ok := a["reference_ids."+source]
+		if !ok {
+			return fmt.Errorf("expected reference_ids to contain %q", source)
+		}
+		if target == "" {
+			return fmt.Errorf("expected %q to be mapped to a reference ID", source)
+		}
+		return nil
+	}
+}
+
+func testAccNGWAFWorkspaceSyncConfig(h, entry string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "fastly_ngwaf_workspace" "target" {
+  name                           = "tf_%s_target"
+  description                    = "Target NGWAF Workspace"
+  mode                           = "log"
+  ip_anonymization               = "hashed"
+  client_ip_headers              = ["X-Real-IP"]
+  default_blocking_response_code = 403
+
+  attack_signal_thresholds {
+    one_minute  = 100
+    ten_minutes = 500
+    one_hour    = 1000
+    immediate   = true
+  }
+}
+
+resource "fastly_ngwaf_workspace_list" "example" {
+  workspace_id = fastly_ngwaf_workspace.example.id
+  name         = "blocked-%s"
+  description  = "Blocked addresses"
+  type         = "ip"
+  entries      = ["%s"]
+}
+
+resource "fastly_ngwaf_workspace_signal" "example" {
+  workspace_id = fastly_ngwaf_workspace.example.id
+  name         = "admin-%s"
+  description  = "Admin access"
+}
+
+resource "fastly_ngwaf_workspace_rule" "example" {
+  workspace_id    = fastly_ngwaf_workspace.example.id
+  type            = "request"
+  description     = "Flag admin access"
+  enabled         = true
+  request_logging = "sampled"
+  expression      = "path ~ \"^/admin\""
+
+  action {
+    type   = "add_signal"
+    signal = fastly_ngwaf_workspace_signal.example.reference_id
+  }
+}
+
+data "fastly_ngwaf_workspace_snapshot" "source" {
+  workspace_id = fastly_ngwaf_workspace.example.id
+
+  depends_on = [
+    fastly_ngwaf_workspace_list.example,
+    fastly_ngwaf_workspace_rule.example,
+  ]
+}
+
+resource "fastly_ngwaf_workspace_sync" "example" {
+  workspace_id = fastly_ngwaf_workspace.target.id
+  snapshot     = data.fastly_ngwaf_workspace_snapshot.source.json
+}
+
+data "fastly_ngwaf_workspace_snapshot" "target" {
+  workspace_id = fastly_ngwaf_workspace_sync.example.workspace_id
+
+  depends_on = [fastly_ngwaf_workspace_sync.example]
+}
+`, testAccNGWAFWorkspaceConfig(fmt.Sprintf("tf_%s", h)), h, h, entry, h)
+}
@@ -21,6 +21,7 @@ func resourceServiceDictionaryItems() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceServiceDictionaryItemsImport,
 		},
+		CustomizeDiff: validateDictionaryItemsGuardrails,
 		Schema: map[string]*schema.Schema{
 			"dictionary_id": {
 				Type:        schema.TypeString,
@@ -44,6 +45,7 @@ func resourceServiceDictionaryItems() *schema.Resource {
 				Optional:    true,
 				Description: "Whether to reapply changes if the state of the items drifts, i.e. if items are managed externally",
 			},
+			"override_guardrails": overrideGuardrailsSchema(),
 			"service_id": {
 				Type:        schema.TypeString,
 				Required:    true,
@@ -225,6 +227,41 @@ func flattenDictionaryItems(remoteState []*gofastly.DictionaryItem) map[string]s
 	return result
 }
 
+// dictionaryIsWriteOnly reports whether a dictionary is write_only. The
+// dictionary is looked up in the active version of the service, or in its
+// latest version if no version is active.
+func dictionaryIsWriteOnly(ctx context.Context, conn *gofastly.Client, serviceID, dictionaryID string) (bool, error) {
+	ctx = gofastly.NewContextForResourceID(ctx, serviceID)
+
+	service, err := conn.GetServiceDetails(ctx, &gofastly.GetServiceDetailsInput{
+		ServiceID: serviceID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("error reading service (%s): %w", serviceID, err)
+	}
+	version := service.ActiveVersion
+	if version == nil || version.Number == nil {
+		version = service.Version
+	}
+	if version == nil {
+		return false, nil
+	}
+
+	dictionaries, err := conn.ListDictionaries(ctx, &gofastly.ListDictionariesInput{
+		ServiceID:      serviceID,
+		ServiceVersion: gofastly.ToValue(version.Number),
+	})
+	if err != nil {
+		return false, fmt.Errorf("error listing dictionaries of service (%s): %w", serviceID, err)
+	}
+	for _, dictionary := range dictionaries {
+		if gofastly.ToValue(dictionary.DictionaryID) == dictionaryID {
+			return gofastly.ToValue(dictionary.WriteOnly), nil
+		}
+	}
+	return false, nil
+}
+
 func executeBatchDictionaryOperations(ctx context.Context, conn *gofastly.Client, serviceID, dictionaryID string, batchDictionaryItems []*gofastly.BatchDictionaryItem) error {
 	batchSize := gofastly.BatchModifyMaximumOperations
 
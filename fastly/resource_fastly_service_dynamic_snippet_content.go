@@ -21,6 +21,7 @@ func resourceServiceDynamicSnippetContent() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceServiceDynamicSnippetContentImport,
 		},
+		CustomizeDiff: validateDynamicSnippetContentGuardrails,
 		Schema: map[string]*schema.Schema{
 			"content": {
 				Type:        schema.TypeString,
@@ -36,6 +37,7 @@ func resourceServiceDynamicSnippetContent() *schema.Resource {
 				Optional:    true,
 				Description: "Whether to reapply changes if the state of the snippets drifts, i.e. if snippets are managed externally",
 			},
+			"override_guardrails": overrideGuardrailsSchema(),
 			"service_id": {
 				Type:        schema.TypeString,
 				Required:    true,
@@ -10,6 +10,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 
 	gofastly "github.com/fastly/go-fastly/v17/fastly"
@@ -137,14 +138,15 @@ func TestAccFastlyServiceVCL_updateBackend(t *testing.T) {
 // 409 conflict error is produced by this test because it reads the new version when making a plan, plans to re-add in
 // the deleted backend, but clones the original version which still had the backend and fails with a conflict.
 func TestAccFastlyServiceVCL_activateNewVersionExternally(t *testing.T) {
+	ct := testAccCassetteFor(t)
 	var service gofastly.ServiceDetail
-	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
-	domain := fmt.Sprintf("fastly-test.tf-%s.com", acctest.RandString(10))
-	backendName := fmt.Sprintf("%s.aws.amazon.com", acctest.RandString(3))
-	backendName2 := fmt.Sprintf("%s.aws.amazon.com", acctest.RandString(3))
+	name := fmt.Sprintf("tf-test-%s", ct.RandString(10))
+	domain := fmt.Sprintf("fastly-test.tf-%s.com", ct.RandString(10))
+	backendName := fmt.Sprintf("%s.aws.amazon.com", ct.RandString(3))
+	backendName2 := fmt.Sprintf("%s.aws.amazon.com", ct.RandString(3))
 
 	activateNewVersion := func(*terraform.State) error {
-		conn := testAccProvider.Meta().(*APIClient).conn
+		conn := ct.Provider.Meta().(*APIClient).conn
 		version, err := conn.CloneVersion(context.TODO(), &gofastly.CloneVersionInput{
 			ServiceID:      gofastly.ToValue(service.ServiceID),
 			ServiceVersion: gofastly.ToValue(service.ActiveVersion.Number),
@@ -173,14 +175,14 @@ func TestAccFastlyServiceVCL_activateNewVersionExternally(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
-		ProviderFactories: testAccProviders,
-		CheckDestroy:      testAccCheckServiceVCLDestroy,
+		ProviderFactories: ct.Providers,
+		CheckDestroy:      testAccCheckServiceVCLDestroyWithProvider(ct.Provider),
 		Steps: []resource.TestStep{
 			{
 				Config: testAccServiceVCLConfigBackendUpdate(name, domain, backendName, backendName2),
 				Check: resource.ComposeTestCheckFunc(
-					testAccCheckServiceExists("fastly_service_vcl.foo", &service),
-					testAccCheckFastlyServiceAttributesBackends(&service, name, []string{backendName, backendName2}, 1),
+					testAccCheckServiceExistsWithProvider(ct.Provider, "fastly_service_vcl.foo", &service),
+					testAccCheckFastlyServiceAttributesBackendsWithProvider(ct.Provider, &service, name, []string{backendName, backendName2}, 1),
 					activateNewVersion,
 				),
 				ExpectNonEmptyPlan: true,
@@ -189,8 +191,8 @@ func TestAccFastlyServiceVCL_activateNewVersionExternally(t *testing.T) {
 			{
 				Config: testAccServiceVCLConfigBackendUpdate(name, domain, backendName, backendName2),
 				Check: resource.ComposeTestCheckFunc(
-					testAccCheckServiceExists("fastly_service_vcl.foo", &service),
-					testAccCheckFastlyServiceAttributesBackends(&service, name, []string{backendName, backendName2}, 3),
+					testAccCheckServiceExistsWithProvider(ct.Provider, "fastly_service_vcl.foo", &service),
+					testAccCheckFastlyServiceAttributesBackendsWithProvider(ct.Provider, &service, name, []string{backendName, backendName2}, 3),
 					resource.TestCheckResourceAttr(
 						"fastly_service_vcl.foo", "active_version", "3"),
 					resource.TestCheckResourceAttr(
@@ -511,6 +513,13 @@ resource "fastly_service_vcl" "foo" {
 }
 
 func testAccCheckServiceExists(n string, service *gofastly.ServiceDetail) resource.TestCheckFunc {
+	return testAccCheckServiceExistsWithProvider(testAccProvider, n, service)
+}
+
+// testAccCheckServiceExistsWithProvider is testAccCheckServiceExists using
+// the API client of the given provider, such as the provider of a
+// testAccCassette.
+func testAccCheckServiceExistsWithProvider(p *schema.Provider, n string, service *gofastly.ServiceDetail) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[n]
 		if !ok {
@@ -521,7 +530,7 @@ func testAccCheckServiceExists(n string, service *gofastly.ServiceDetail) resour
 			return fmt.Errorf("no Service ID is set")
 		}
 
-		conn := testAccProvider.Meta().(*APIClient).conn
+		conn := p.Meta().(*APIClient).conn
 		latest, err := conn.GetServiceDetails(context.TODO(), &gofastly.GetServiceDetailsInput{
 			ServiceID: rs.Primary.ID,
 		})
@@ -664,23 +673,24 @@ func TestAccFastlyServiceVCL_defaultHost(t *testing.T) {
 // version when activate=true, and means that the version we read from, and the one we clone from in order to make changes,
 // are different, meaning the plan is applied to a different version and 409 conflict errors can occur.
 func TestAccFastlyServiceVCL_brokenSnippet(t *testing.T) {
+	ct := testAccCassetteFor(t)
 	var service gofastly.ServiceDetail
-	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
-	domain := fmt.Sprintf("fastly-test.tf-%s.test", acctest.RandString(10))
+	name := fmt.Sprintf("tf-test-%s", ct.RandString(10))
+	domain := fmt.Sprintf("fastly-test.tf-%s.test", ct.RandString(10))
 
 	resource.ParallelTest(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
-		ProviderFactories: testAccProviders,
-		CheckDestroy:      testAccCheckServiceVCLDestroy,
+		ProviderFactories: ct.Providers,
+		CheckDestroy:      testAccCheckServiceVCLDestroyWithProvider(ct.Provider),
 		Steps: []resource.TestStep{
 			{
 				Config: testAccServiceVCLConfigBrokenSnippet(name, domain, "backend1", `if (req.url !~ "^/anything") {
                        set req.url = "/anything" req.url;
                      }`),
 				Check: resource.ComposeTestCheckFunc(
-					testAccCheckServiceExists("fastly_service_vcl.foo", &service),
+					testAccCheckServiceExistsWithProvider(ct.Provider, "fastly_service_vcl.foo", &service),
 				),
 			},
 			{
@@ -694,7 +704,7 @@ func TestAccFastlyServiceVCL_brokenSnippet(t *testing.T) {
                        set req.url = "/anything" req.url;
                      }`),
 				Check: resource.ComposeTestCheckFunc(
-					testAccCheckServiceExists("fastly_service_vcl.foo", &service),
+					testAccCheckServiceExistsWithProvider(ct.Provider, "fastly_service_vcl.foo", &service),
 				),
 			},
 		},
@@ -732,25 +742,34 @@ func TestAccFastlyServiceVCL_createZeroDefaultTTL(t *testing.T) {
 }
 
 func testAccCheckServiceVCLDestroy(s *terraform.State) error {
-	for _, rs := range s.RootModule().Resources {
-		if rs.Type != "fastly_service_vcl" {
-			continue
-		}
+	return testAccCheckServiceVCLDestroyWithProvider(testAccProvider)(s)
+}
 
-		conn := testAccProvider.Meta().(*APIClient).conn
-		l, err := conn.ListServices(context.TODO(), &gofastly.ListServicesInput{})
-		if err != nil {
-			return fmt.Errorf("error listing services when deleting Fastly Service (%s): %s", rs.Primary.ID, err)
-		}
+// testAccCheckServiceVCLDestroyWithProvider is testAccCheckServiceVCLDestroy
+// using the API client of the given provider, such as the provider of a
+// testAccCassette.
+func testAccCheckServiceVCLDestroyWithProvider(p *schema.Provider) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "fastly_service_vcl" {
+				continue
+			}
 
-		for _, s := range l {
-			if gofastly.ToValue(s.ServiceID) == rs.Primary.ID {
-				// service still found
-				return fmt.Errorf("tried deleting Service (%s), but was still found", rs.Primary.ID)
+			conn := p.Meta().(*APIClient).conn
+			l, err := conn.ListServices(context.TODO(), &gofastly.ListServicesInput{})
+			if err != nil {
+				return fmt.Errorf("error listing services when deleting Fastly Service (%s): %s", rs.Primary.ID, err)
+			}
+
+			for _, s := range l {
+				if gofastly.ToValue(s.ServiceID) == rs.Primary.ID {
+					// service still found
+					return fmt.Errorf("tried deleting Service (%s), but was still found", rs.Primary.ID)
+				}
 			}
 		}
+		return nil
 	}
-	return nil
 }
 
 func testAccServiceVCLConfig(name, domain string) string {
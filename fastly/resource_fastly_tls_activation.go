@@ -3,7 +3,10 @@ package fastly
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -21,6 +24,7 @@ func resourceFastlyTLSActivation() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		CustomizeDiff: validateTLSActivationDomain,
 		Schema: map[string]*schema.Schema{
 			"certificate_id": {
 				Type:        schema.TypeString,
@@ -184,3 +188,51 @@ func resourceFastlyTLSActivationDelete(ctx context.Context, d *schema.ResourceDa
 
 	return nil
 }
+
+// validateTLSActivationDomain checks that the certificate covers the domain
+// being activated, which Fastly otherwise rejects with an obscure error.
+func validateTLSActivationDomain(ctx context.Context, d *schema.ResourceDiff, meta any) error {
+	if !d.HasChanges("certificate_id", "domain") || !d.NewValueKnown("certificate_id") || !d.NewValueKnown("domain") {
+		return nil
+	}
+	client, ok := meta.(*APIClient)
+	if !ok {
+		return nil
+	}
+	return checkCertificateCoversDomains(ctx, client.conn, d.Get("certificate_id").(string), []string{d.Get("domain").(string)})
+}
+
+// checkCertificateCoversDomains returns an error for each domain that is not
+// covered by the Subject Alternative Names of the certificate.
+func checkCertificateCoversDomains(ctx context.Context, conn *fastly.Client, certificateID string, domains []string) error {
+	if certificateID == "" || len(domains) == 0 {
+		return nil
+	}
+
+	cert, err := conn.GetCustomTLSCertificate(ctx, &fastly.GetCustomTLSCertificateInput{
+		ID: certificateID,
+	})
+	if err != nil {
+		// Leave it to the API to reject the activation.
+		log.Printf("[WARN] Unable to read TLS certificate (%s) to check its domains: %s", certificateID, err)
+		return nil
+	}
+
+	names := make([]string, 0, len(cert.Domains))
+	for _, domain := range cert.Domains {
+		names = append(names, domain.ID)
+	}
+	sort.Strings(names)
+
+	var uncovered []string
+	for _, domain := range domains {
+		if !certificateCoversDomain(names, domain) {
+			uncovered = append(uncovered, domain)
+		}
+	}
+	if len(uncovered) == 0 {
+		return nil
+	}
+	sort.Strings(uncovered)
+	return fmt.Errorf("TLS certificate (%s) does not cover %s. Its Subject Alternative Names are: %s", certificateID, quoteNames(uncovered), strings.Join(names, ", "))
+}
@@ -0,0 +1,519 @@
+package fastly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/fastly/go-fastly/v17/fastly"
+)
+
+const (
+	// tlsActivationsConcurrency is the maximum number of TLS activations
+	// fastly_tls_activations creates, updates, deletes or reads at once.
+	tlsActivationsConcurrency = 8
+	tlsActivationsPageSize    = 100
+)
+
+func resourceFastlyTLSActivations() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceFastlyTLSActivationsCreate,
+		ReadContext:   resourceFastlyTLSActivationsRead,
+		UpdateContext: resourceFastlyTLSActivationsUpdate,
+		DeleteContext: resourceFastlyTLSActivationsDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceFastlyTLSActivationsImport,
+		},
+		CustomizeDiff: customdiff.All(
+			customdiff.ComputedIf("activations", func(_ context.Context, d *schema.ResourceDiff, _ any) bool {
+				return d.HasChange("domains")
+			}),
+			validateTLSActivationsDomains,
+		),
+		Schema: map[string]*schema.Schema{
+			"activations": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "A map of each domain to the ID of its TLS activation.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"certificate_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "ID of certificate to use. Must have all of the `domains` in the certificate's Subject Alternative Names. Changing it updates the existing activations to the new certificate in place.",
+			},
+			"configuration_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Computed:    true,
+				Description: "ID of TLS configuration to be used to terminate TLS traffic, or use the default one if missing.",
+			},
+			"domains": {
+				Type:        schema.TypeSet,
+				Required:    true,
+				MinItems:    1,
+				Description: "Domains to enable TLS on. Each must be assigned to an existing Fastly Service.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"mutual_authentication_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "An alphanumeric string identifying a mutual authentication.",
+			},
+		},
+	}
+}
+
+func resourceFastlyTLSActivationsCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	certificateID := d.Get("certificate_id").(string)
+	if certificateID == "" {
+		return diag.Errorf(
+			"certificate_id is empty: the certificate for the referenced TLS subscription has not been issued yet. " +
+				"Reference `fastly_tls_subscription_validation.<name>.certificate_id` instead of " +
+				"`fastly_tls_subscription.<name>.certificate_id` so the activations wait for issuance within a single apply.",
+		)
+	}
+
+	// The ID does not change if certificate_id is later updated.
+	d.SetId(certificateID)
+
+	diags := resourceFastlyTLSActivationsUpdate(ctx, d, meta)
+	if !diags.HasError() {
+		return diags
+	}
+
+	// A resource that fails to be created is tainted, and replacing it would
+	// delete the activations created so far anyway. Delete them now, so that
+	// the next apply creates all of them again.
+	var ids []string
+	for _, id := range d.Get("activations").(map[string]any) {
+		ids = append(ids, id.(string))
+	}
+	sort.Strings(ids)
+	log.Printf("[DEBUG] Rolling back %d TLS activations for (%s)", len(ids), d.Id())
+
+	if err := deleteTLSActivations(context.WithoutCancel(ctx), meta.(*APIClient).conn, ids); err != nil {
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Error rolling back TLS activations",
+			Detail:   fmt.Sprintf("Some TLS activations created for certificate %s could not be deleted and must be deleted manually: %s", certificateID, err),
+		})
+	}
+	d.SetId("")
+
+	return diags
+}
+
+// resourceFastlyTLSActivationsRead only considers the activations recorded in
+// the activations attribute, so other activations of the same certificate are
+// left alone. A domain whose activation no longer uses certificate_id, or the
+// configured mutual_authentication_id, is removed from domains so that the
+// next apply updates it.
+func resourceFastlyTLSActivationsRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	log.Printf("[DEBUG] Refreshing TLS Activations for (%s)", d.Id())
+
+	conn := meta.(*APIClient).conn
+	certificateID := d.Get("certificate_id").(string)
+
+	activations, err := listTLSActivationsByDomain(ctx, conn, certificateID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Fetch the recorded activations that no longer use the certificate, so
+	// they are updated rather than recreated.
+	recorded := d.Get("activations").(map[string]any)
+	var moved []string
+	for domain, id := range recorded {
+		if a, ok := activations[domain]; !ok || a.ID != id.(string) {
+			moved = append(moved, id.(string))
+		}
+	}
+	var mu sync.Mutex
+	err = runTLSActivationOperations(moved, func(id string) error {
+		a, err := conn.GetTLSActivation(ctx, &fastly.GetTLSActivationInput{ID: id})
+		if err != nil {
+			if httpErr, ok := err.(*fastly.HTTPError); ok && httpErr.IsNotFound() {
+				log.Printf("[WARN] TLS activation (%s) not found, removing from state", id)
+				return nil
+			}
+			return fmt.Errorf("error reading TLS activation (%s): %w", id, err)
+		}
+		if a.Domain != nil {
+			mu.Lock()
+			activations[a.Domain.ID] = a
+			mu.Unlock()
+		}
+		return nil
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	mtlsID := d.Get("mutual_authentication_id").(string)
+
+	ids := map[string]string{}
+	var domains []string
+	for domain, a := range activations {
+		if _, ok := recorded[domain]; !ok {
+			continue
+		}
+		ids[domain] = a.ID
+		if tlsActivationInSync(a, certificateID, mtlsID) {
+			domains = append(domains, domain)
+		}
+	}
+	sort.Strings(domains)
+
+	if len(ids) == 0 {
+		log.Printf("[WARN] No TLS activations found for (%s), removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	// The configuration and mutual authentication can differ between
+	// activations, so only fill them in when they are unset.
+	if len(domains) > 0 {
+		first := activations[domains[0]]
+		if d.Get("configuration_id").(string) == "" && first.Configuration != nil {
+			if err := d.Set("configuration_id", first.Configuration.ID); err != nil {
+				return diag.FromErr(err)
+			}
+		}
+		if mtlsID == "" && first.MutualAuthentication != nil {
+			if err := d.Set("mutual_authentication_id", first.MutualAuthentication.ID); err != nil {
+				return diag.FromErr(err)
+			}
+		}
+	}
+
+	if err := d.Set("activations", ids); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("domains", domains); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// resourceFastlyTLSActivationsUpdate creates, updates and deletes activations
+// so each domain is activated with certificate_id. Activations that are moved
+// to a new certificate are updated in place, so TLS is never disabled on the
+// domain. If some operations of an update fail, the state records the ones
+// that succeeded and the next apply retries the rest. If some operations of
+// a create fail, resourceFastlyTLSActivationsCreate rolls back the rest.
+func resourceFastlyTLSActivationsUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	certificateID := d.Get("certificate_id").(string)
+	mtlsID := d.Get("mutual_authentication_id").(string)
+
+	var configuration *fastly.TLSConfiguration
+	if v, ok := d.GetOk("configuration_id"); ok {
+		configuration = &fastly.TLSConfiguration{ID: v.(string)}
+	}
+
+	// activations is unknown in the plan whenever domains change.
+	recorded, _ := d.GetChange("activations")
+	current := map[string]string{}
+	for domain, id := range recorded.(map[string]any) {
+		current[domain] = id.(string)
+	}
+	synced := map[string]bool{}
+	if !d.HasChanges("certificate_id", "mutual_authentication_id") {
+		o, _ := d.GetChange("domains")
+		for _, domain := range o.(*schema.Set).List() {
+			synced[domain.(string)] = true
+		}
+	}
+	var desired []string
+	for _, domain := range d.Get("domains").(*schema.Set).List() {
+		desired = append(desired, domain.(string))
+	}
+
+	changes := planTLSActivationChanges(current, synced, desired)
+	ids := maps.Clone(current)
+	log.Printf("[DEBUG] TLS activations for (%s): creating %d, updating %d, deleting %d", d.Id(), len(changes.create), len(changes.update), len(changes.delete))
+
+	var mu sync.Mutex
+	done := map[string]bool{}
+	record := func(domain, id string) {
+		mu.Lock()
+		defer mu.Unlock()
+		if id == "" {
+			delete(current, domain)
+		} else {
+			current[domain] = id
+		}
+		done[domain] = true
+	}
+
+	update := func(id string) error {
+		input := &fastly.UpdateTLSActivationInput{
+			ID:          id,
+			Certificate: &fastly.CustomTLSCertificate{ID: certificateID},
+		}
+		if mtlsID != "" {
+			input.MutualAuthentication = &fastly.TLSMutualAuthentication{ID: mtlsID}
+		}
+		_, err := conn.UpdateTLSActivation(ctx, input)
+		return err
+	}
+
+	// Updates and creates go first, so that a domain moved to a new
+	// certificate is never left without TLS.
+	errUpdate := runTLSActivationOperations(changes.update, func(domain string) error {
+		if err := update(ids[domain]); err != nil {
+			return fmt.Errorf("error updating TLS activation for %q: %w", domain, err)
+		}
+		record(domain, ids[domain])
+		return nil
+	})
+
+	errCreate := runTLSActivationOperations(changes.create, func(domain string) error {
+		a, err := conn.CreateTLSActivation(ctx, &fastly.CreateTLSActivationInput{
+			Certificate:   &fastly.CustomTLSCertificate{ID: certificateID},
+			Configuration: configuration,
+			Domain:        &fastly.TLSDomain{ID: domain},
+		})
+		if err != nil {
+			return fmt.Errorf("error creating TLS activation for %q: %w", domain, err)
+		}
+		// Setting the mutual_authentication_id is only possible through an
+		// update via PATCH. See resourceFastlyTLSActivationCreate.
+		if mtlsID != "" {
+			if err := update(a.ID); err != nil {
+				mu.Lock()
+				current[domain] = a.ID
+				mu.Unlock()
+				return fmt.Errorf("error setting mutual authentication on TLS activation for %q: %w", domain, err)
+			}
+		}
+		record(domain, a.ID)
+		return nil
+	})
+
+	errDelete := runTLSActivationOperations(changes.delete, func(domain string) error {
+		err := conn.DeleteTLSActivation(ctx, &fastly.DeleteTLSActivationInput{ID: ids[domain]})
+		if err != nil {
+			if httpErr, ok := err.(*fastly.HTTPError); !ok || !httpErr.IsNotFound() {
+				return fmt.Errorf("error deleting TLS activation for %q: %w", domain, err)
+			}
+		}
+		record(domain, "")
+		return nil
+	})
+
+	if err := errors.Join(errUpdate, errCreate, errDelete); err != nil {
+		// Only record the domains that are now activated as configured, so
+		// that the next plan shows the remaining changes.
+		var domains []string
+		for domain := range current {
+			if done[domain] || (synced[domain] && d.Get("domains").(*schema.Set).Contains(domain)) {
+				domains = append(domains, domain)
+			}
+		}
+		sort.Strings(domains)
+		if setErr := d.Set("domains", domains); setErr != nil {
+			log.Printf("[WARN] Error recording domains for (%s): %s", d.Id(), setErr)
+		}
+		if setErr := d.Set("activations", current); setErr != nil {
+			log.Printf("[WARN] Error recording activations for (%s): %s", d.Id(), setErr)
+		}
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("activations", current); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceFastlyTLSActivationsRead(ctx, d, meta)
+}
+
+func resourceFastlyTLSActivationsDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var ids []string
+	for _, id := range d.Get("activations").(map[string]any) {
+		ids = append(ids, id.(string))
+	}
+	sort.Strings(ids)
+
+	if err := deleteTLSActivations(ctx, meta.(*APIClient).conn, ids); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// deleteTLSActivations deletes the TLS activations with the given IDs,
+// ignoring those that no longer exist.
+func deleteTLSActivations(ctx context.Context, conn *fastly.Client, ids []string) error {
+	return runTLSActivationOperations(ids, func(id string) error {
+		err := conn.DeleteTLSActivation(ctx, &fastly.DeleteTLSActivationInput{ID: id})
+		if err != nil {
+			if httpErr, ok := err.(*fastly.HTTPError); ok && httpErr.IsNotFound() {
+				log.Printf("[WARN] Error deleting TLS activation (%s), not found", id)
+				return nil
+			}
+			return fmt.Errorf("error deleting TLS activation (%s): %w", id, err)
+		}
+		return nil
+	})
+}
+
+// resourceFastlyTLSActivationsImport imports every activation of the
+// certificate with the given ID.
+func resourceFastlyTLSActivationsImport(ctx context.Context, d *schema.ResourceData, meta any) ([]*schema.ResourceData, error) {
+	conn := meta.(*APIClient).conn
+
+	activations, err := listTLSActivationsByDomain(ctx, conn, d.Id())
+	if err != nil {
+		return nil, err
+	}
+	if len(activations) == 0 {
+		return nil, fmt.Errorf("no TLS activations found for certificate (%s)", d.Id())
+	}
+
+	ids := map[string]string{}
+	for domain, a := range activations {
+		ids[domain] = a.ID
+	}
+	if err := d.Set("activations", ids); err != nil {
+		return nil, err
+	}
+	if err := d.Set("certificate_id", d.Id()); err != nil {
+		return nil, err
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// validateTLSActivationsDomains checks that the certificate covers all of the
+// domains being activated.
+func validateTLSActivationsDomains(ctx context.Context, d *schema.ResourceDiff, meta any) error {
+	if !d.HasChanges("certificate_id", "domains") || !d.NewValueKnown("certificate_id") || !d.NewValueKnown("domains") {
+		return nil
+	}
+	client, ok := meta.(*APIClient)
+	if !ok {
+		return nil
+	}
+
+	var domains []string
+	for _, domain := range d.Get("domains").(*schema.Set).List() {
+		domains = append(domains, domain.(string))
+	}
+	return checkCertificateCoversDomains(ctx, client.conn, d.Get("certificate_id").(string), domains)
+}
+
+// tlsActivationChanges are the operations needed to activate a set of
+// domains. Each is a sorted list of domains.
+type tlsActivationChanges struct {
+	create []string
+	delete []string
+	update []string
+}
+
+// planTLSActivationChanges works out the operations needed to go from the
+// current activations, a map of domain to activation ID, to the desired
+// domains. Domains that are already activated are updated unless they are in
+// synced.
+func planTLSActivationChanges(current map[string]string, synced map[string]bool, desired []string) tlsActivationChanges {
+	var c tlsActivationChanges
+	want := map[string]bool{}
+	for _, domain := range desired {
+		want[domain] = true
+		if _, ok := current[domain]; !ok {
+			c.create = append(c.create, domain)
+		} else if !synced[domain] {
+			c.update = append(c.update, domain)
+		}
+	}
+	for domain := range current {
+		if !want[domain] {
+			c.delete = append(c.delete, domain)
+		}
+	}
+	sort.Strings(c.create)
+	sort.Strings(c.delete)
+	sort.Strings(c.update)
+	return c
+}
+
+// tlsActivationInSync reports whether the activation uses the certificate
+// and, when set, the mutual authentication.
+func tlsActivationInSync(a *fastly.TLSActivation, certificateID, mtlsID string) bool {
+	if a.Certificate == nil || a.Certificate.ID != certificateID {
+		return false
+	}
+	if mtlsID != "" && (a.MutualAuthentication == nil || a.MutualAuthentication.ID != mtlsID) {
+		return false
+	}
+	return true
+}
+
+// listTLSActivationsByDomain returns the activations of the certificate by
+// domain.
+func listTLSActivationsByDomain(ctx context.Context, conn *fastly.Client, certificateID string) (map[string]*fastly.TLSActivation, error) {
+	result := map[string]*fastly.TLSActivation{}
+	pageNumber := 1
+	for {
+		list, err := conn.ListTLSActivations(ctx, &fastly.ListTLSActivationsInput{
+			FilterTLSCertificateID: certificateID,
+			PageNumber:             pageNumber,
+			PageSize:               tlsActivationsPageSize,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error listing TLS activations for certificate (%s): %w", certificateID, err)
+		}
+		for _, a := range list {
+			if a.Domain != nil {
+				result[a.Domain.ID] = a
+			}
+		}
+		if len(list) < tlsActivationsPageSize {
+			break
+		}
+		pageNumber++
+	}
+	return result, nil
+}
+
+// runTLSActivationOperations calls fn for each item, running at most
+// tlsActivationsConcurrency at once, and returns all of the errors.
+func runTLSActivationOperations(items []string, fn func(string) error) error {
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs []error
+	)
+	sem := make(chan struct{}, tlsActivationsConcurrency)
+
+	for _, item := range items {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if err := fn(item); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errors.Join(errs...)
+}
@@ -0,0 +1,209 @@
+package fastly
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fastly/go-fastly/v17/fastly"
+)
+
+func TestPlanTLSActivationChanges(t *testing.T) {
+	current := map[string]string{
+		"a.example.com": "act-a",
+		"b.example.com": "act-b",
+		"c.example.com": "act-c",
+	}
+
+	for _, tc := range []struct {
+		name    string
+		synced  map[string]bool
+		desired []string
+		want    tlsActivationChanges
+	}{
+		{
+			name:    "no changes",
+			synced:  map[string]bool{"a.example.com": true, "b.example.com": true, "c.example.com": true},
+			desired: []string{"c.example.com", "a.example.com", "b.example.com"},
+		},
+		{
+			name:    "add and remove domains",
+			synced:  map[string]bool{"a.example.com": true, "b.example.com": true, "c.example.com": true},
+			desired: []string{"a.example.com", "e.example.com", "d.example.com"},
+			want: tlsActivationChanges{
+				create: []string{"d.example.com", "e.example.com"},
+				delete: []string{"b.example.com", "c.example.com"},
+			},
+		},
+		{
+			name:    "certificate swap",
+			desired: []string{"a.example.com", "b.example.com", "c.example.com"},
+			want: tlsActivationChanges{
+				update: []string{"a.example.com", "b.example.com", "c.example.com"},
+			},
+		},
+		{
+			name:    "drifted domain",
+			synced:  map[string]bool{"a.example.com": true, "c.example.com": true},
+			desired: []string{"a.example.com", "b.example.com"},
+			want: tlsActivationChanges{
+				delete: []string{"c.example.com"},
+				update: []string{"b.example.com"},
+			},
+		},
+	} {
+		if got := planTLSActivationChanges(current, tc.synced, tc.desired); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: expected %+v, got %+v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestAccFastlyTLSActivations_basic(t *testing.T) {
+	name := acctest.RandomWithPrefix(testResourcePrefix)
+	domains := []string{
+		fmt.Sprintf("a.%s.com", name),
+		fmt.Sprintf("b.%s.com", name),
+		fmt.Sprintf("c.%s.com", name),
+	}
+	key, cert, cert2, err := generateKeyAndMultipleCerts(domains...)
+	require.NoError(t, err)
+	key = strings.ReplaceAll(key, "\n", `\n`)
+	cert = strings.ReplaceAll(cert, "\n", `\n`)
+	cert2 = strings.ReplaceAll(cert2, "\n", `\n`)
+
+	updatedName := acctest.RandomWithPrefix(testResourcePrefix)
+
+	resourceName := "fastly_tls_activations.test"
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccFastlyTLSActivationsCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFastlyTLSActivationsConfig(name, key, name, cert, domains, domains[:2]),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(resourceName, "certificate_id", "fastly_tls_certificate.test", "id"),
+					resource.TestCheckResourceAttrSet(resourceName, "configuration_id"),
+					resource.TestCheckResourceAttr(resourceName, "domains.#", "2"),
+					resource.TestCheckResourceAttr(resourceName, "activations.%", "2"),
+					resource.TestCheckResourceAttrSet(resourceName, "activations."+domains[0]),
+					testAccFastlyTLSActivationsCheckExists(resourceName),
+				),
+			},
+			{
+				// The ID is the certificate ID until the certificate is swapped.
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				// Swapping the certificate keeps the existing activations.
+				Config: testAccFastlyTLSActivationsConfig(name, key, updatedName, cert2, domains, domains[1:]),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(resourceName, "certificate_id", "fastly_tls_certificate.test", "id"),
+					resource.TestCheckResourceAttr(resourceName, "domains.#", "2"),
+					resource.TestCheckResourceAttr(resourceName, "activations.%", "2"),
+					resource.TestCheckNoResourceAttr(resourceName, "activations."+domains[0]),
+					testAccFastlyTLSActivationsCheckExists(resourceName),
+				),
+			},
+		},
+	})
+}
+
+func testAccFastlyTLSActivationsConfig(serviceName, key, certName, cert string, serviceDomains, domains []string) string {
+	var domainBlocks strings.Builder
+	for _, domain := range serviceDomains {
+		fmt.Fprintf(&domainBlocks, "  domain {\n    name = %q\n  }\n\n", domain)
+	}
+
+	return fmt.Sprintf(`
+resource "fastly_service_vcl" "test" {
+  name = "%s"
+
+%s  backend {
+    address = "127.0.0.1"
+    name    = "localhost"
+  }
+
+  force_destroy = true
+}
+
+resource "fastly_tls_private_key" "test" {
+  key_pem = "%s"
+  name = "%s"
+}
+
+resource "fastly_tls_certificate" "test" {
+  certificate_body = "%s"
+  name = "%s"
+  depends_on = [fastly_tls_private_key.test]
+}
+
+resource "fastly_tls_activations" "test" {
+  certificate_id = fastly_tls_certificate.test.id
+  domains = ["%s"]
+  depends_on = [fastly_service_vcl.test]
+}
+`, serviceName, domainBlocks.String(), key, serviceName, cert, certName, strings.Join(domains, `", "`))
+}
+
+func testAccFastlyTLSActivationsCheckExists(resourceName string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		conn := testAccProvider.Meta().(*APIClient).conn
+
+		r, ok := state.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("not found: %s", resourceName)
+		}
+		for key, id := range r.Primary.Attributes {
+			if !strings.HasPrefix(key, "activations.") || key == "activations.%" {
+				continue
+			}
+			activation, err := conn.GetTLSActivation(context.TODO(), &fastly.GetTLSActivationInput{
+				ID: id,
+			})
+			if err != nil {
+				return err
+			}
+			if activation.Certificate == nil || activation.Certificate.ID != r.Primary.Attributes["certificate_id"] {
+				return fmt.Errorf("TLS activation (%s) does not use certificate (%s)", id, r.Primary.Attributes["certificate_id"])
+			}
+		}
+		return nil
+	}
+}
+
+func testAccFastlyTLSActivationsCheckDestroy(state *terraform.State) error {
+	for _, resourceState := range state.RootModule().Resources {
+		if resourceState.Type != "fastly_tls_activations" {
+			continue
+		}
+
+		conn := testAccProvider.Meta().(*APIClient).conn
+		for key, id := range resourceState.Primary.Attributes {
+			if !strings.HasPrefix(key, "activations.") || key == "activations.%" {
+				continue
+			}
+			_, err := conn.GetTLSActivation(context.TODO(), &fastly.GetTLSActivationInput{
+				ID: id,
+			})
+			if err == nil {
+				return fmt.Errorf("[WARN] Tried disabling TLS activation (%s) but was still found", id)
+			}
+			if httpErr, ok := err.(*fastly.HTTPError); !ok || !httpErr.IsNotFound() {
+				return fmt.Errorf("[WARN] Error reading TLS activation (%s): %w", id, err)
+			}
+		}
+	}
+	return nil
+}
@@ -2,17 +2,26 @@ package fastly
 
 import (
 	"context"
+	"crypto/x509"
 	"fmt"
 	"log"
+	"sort"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
 	"github.com/fastly/go-fastly/v17/fastly"
 )
 
+// The values of the rotation attribute.
+const (
+	tlsCertificateRotationReplace = "replace"
+	tlsCertificateRotationUpdate  = "update"
+)
+
 func resourceFastlyTLSCertificate() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceFastlyTLSCertificateCreate,
@@ -22,6 +31,7 @@ func resourceFastlyTLSCertificate() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		CustomizeDiff: resourceFastlyTLSCertificateCustomizeDiff,
 		Schema: map[string]*schema.Schema{
 			"certificate_body": {
 				Type:             schema.TypeString,
@@ -40,6 +50,13 @@ func resourceFastlyTLSCertificate() *schema.Resource {
 				Computed:    true,
 				Elem:        &schema.Schema{Type: schema.TypeString},
 			},
+			"expiry_warning_days": {
+				Type:             schema.TypeInt,
+				Description:      "Show a warning when planning if the certificate expires within this many days. `0` disables the warning. Default `30`.",
+				Optional:         true,
+				Default:          30,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+			},
 			"issued_to": {
 				Type:        schema.TypeString,
 				Description: "The hostname for which a certificate was issued.",
@@ -56,11 +73,45 @@ func resourceFastlyTLSCertificate() *schema.Resource {
 				Optional:    true,
 				Computed:    true,
 			},
+			"not_after": {
+				Type:        schema.TypeString,
+				Description: "Timestamp (RFC 3339) after which the certificate is no longer valid, parsed from `certificate_body`.",
+				Computed:    true,
+			},
+			"not_before": {
+				Type:        schema.TypeString,
+				Description: "Timestamp (RFC 3339) before which the certificate is not yet valid, parsed from `certificate_body`.",
+				Computed:    true,
+			},
+			"previous_certificate_id": {
+				Type:        schema.TypeString,
+				Description: "The ID of the certificate being replaced by an unfinished rotation. The next apply moves any remaining TLS activations to this certificate and deletes the previous one.",
+				Computed:    true,
+			},
+			"public_key_sha1": {
+				Type:        schema.TypeString,
+				Description: "The SHA-1 of the certificate's public key, parsed from `certificate_body`. Matches the `public_key_sha1` of the `fastly_tls_private_key` that must be uploaded before the certificate.",
+				Computed:    true,
+			},
 			"replace": {
 				Type:        schema.TypeBool,
 				Description: "A recommendation from Fastly indicating the key associated with this certificate is in need of rotation.",
 				Computed:    true,
 			},
+			"replace_before_expiry": {
+				Type:             schema.TypeInt,
+				Description:      "If the certificate expires within this many days, fail the plan until `certificate_body` is updated with a renewed certificate. This ensures a certificate whose PEM source has not been renewed is noticed in time. `0` disables the check. Default `0`.",
+				Optional:         true,
+				Default:          0,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+			},
+			"rotation": {
+				Type:             schema.TypeString,
+				Description:      "How a change to `certificate_body` is applied. `update` updates the certificate in place. `replace` uploads a new certificate, moves every TLS activation of the previous certificate to it, checks the activations were moved, and then deletes the previous certificate, changing the ID of this resource. Default `update`.",
+				Optional:         true,
+				Default:          tlsCertificateRotationUpdate,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{tlsCertificateRotationReplace, tlsCertificateRotationUpdate}, false)),
+			},
 			"serial_number": {
 				Type:        schema.TypeString,
 				Description: "A value assigned by the issuer that is unique to a certificate.",
@@ -83,20 +134,12 @@ func resourceFastlyTLSCertificate() *schema.Resource {
 func resourceFastlyTLSCertificateCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	conn := meta.(*APIClient).conn
 
-	input := &fastly.CreateCustomTLSCertificateInput{
-		CertBlob: d.Get("certificate_body").(string),
-	}
-
-	if v, ok := d.GetOk("name"); ok {
-		input.Name = v.(string)
-	}
-
-	output, err := conn.CreateCustomTLSCertificate(ctx, input)
+	id, err := createCustomTLSCertificate(ctx, conn, d)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	d.SetId(output.ID)
+	d.SetId(id)
 
 	return resourceFastlyTLSCertificateRead(ctx, d, meta)
 }
@@ -155,12 +198,71 @@ func resourceFastlyTLSCertificateRead(ctx context.Context, d *schema.ResourceDat
 		return diag.FromErr(err)
 	}
 
+	// The validity window is parsed from certificate_body so that it matches
+	// the plan, and cross-checked against the certificate Fastly has. It is
+	// only read from the API when certificate_body is not known, e.g. after
+	// an import.
+	notBefore := cert.NotBefore.UTC().Format(time.RFC3339)
+	notAfter := cert.NotAfter.UTC().Format(time.RFC3339)
+	if body := d.Get("certificate_body").(string); body != "" {
+		chain, err := parseCertificateChain(body)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		leaf := chain[0]
+		if err := d.Set("public_key_sha1", certificatePublicKeySHA1(leaf)); err != nil {
+			return diag.FromErr(err)
+		}
+		localNotBefore := leaf.NotBefore.UTC().Format(time.RFC3339)
+		localNotAfter := leaf.NotAfter.UTC().Format(time.RFC3339)
+		if localNotBefore != notBefore || localNotAfter != notAfter {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("TLS certificate (%s) does not match certificate_body", cert.ID),
+				Detail: fmt.Sprintf(
+					"Fastly reports the certificate is valid from %s to %s, but the certificate in certificate_body is valid from %s to %s. The certificate may have been changed outside of Terraform.",
+					notBefore, notAfter, localNotBefore, localNotAfter,
+				),
+			})
+		}
+		notBefore, notAfter = localNotBefore, localNotAfter
+	}
+	if err := d.Set("not_before", notBefore); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("not_after", notAfter); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if previousID := d.Get("previous_certificate_id").(string); previousID != "" {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Rotation of TLS certificate (%s) is unfinished", previousID),
+			Detail:   fmt.Sprintf("The previous certificate (%s) has not been deleted, as not all of its TLS activations were moved to the new certificate (%s). The next apply resumes the rotation.", previousID, cert.ID),
+		})
+	}
+
 	return diags
 }
 
 func resourceFastlyTLSCertificateUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	conn := meta.(*APIClient).conn
 
+	// Resume a rotation that was interrupted by a failed apply.
+	if previousID, _ := d.GetChange("previous_certificate_id"); previousID.(string) != "" {
+		if err := finishTLSCertificateRotation(ctx, conn, d, previousID.(string)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if !d.HasChanges("certificate_body", "name") {
+		return resourceFastlyTLSCertificateRead(ctx, d, meta)
+	}
+
+	if d.HasChange("certificate_body") && d.Get("rotation").(string) == tlsCertificateRotationReplace {
+		return resourceFastlyTLSCertificateRotate(ctx, d, meta)
+	}
+
 	input := &fastly.UpdateCustomTLSCertificateInput{
 		ID:       d.Id(),
 		CertBlob: d.Get("certificate_body").(string),
@@ -188,5 +290,274 @@ func resourceFastlyTLSCertificateDelete(ctx context.Context, d *schema.ResourceD
 		return diag.FromErr(err)
 	}
 
+	// Clean up after an unfinished rotation.
+	if previousID := d.Get("previous_certificate_id").(string); previousID != "" {
+		if err := deleteCustomTLSCertificate(ctx, conn, previousID); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return nil
+}
+
+// resourceFastlyTLSCertificateRotate applies a change to certificate_body by
+// uploading a new certificate and moving the activations of the current one
+// to it. previous_certificate_id is recorded as soon as the new certificate
+// exists, so that if a later step fails the next apply resumes the rotation
+// rather than uploading another certificate.
+func resourceFastlyTLSCertificateRotate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	conn := meta.(*APIClient).conn
+
+	previousID := d.Id()
+	id, err := createCustomTLSCertificate(ctx, conn, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	log.Printf("[DEBUG] Rotating TLS certificate (%s) to (%s)", previousID, id)
+
+	d.SetId(id)
+	if err := d.Set("previous_certificate_id", previousID); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := finishTLSCertificateRotation(ctx, conn, d, previousID); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceFastlyTLSCertificateRead(ctx, d, meta)
+}
+
+// finishTLSCertificateRotation moves the TLS activations of the previous
+// certificate to the current one, reads them back to check they were moved,
+// and then deletes the previous certificate.
+func finishTLSCertificateRotation(ctx context.Context, conn *fastly.Client, d *schema.ResourceData, previousID string) error {
+	id := d.Id()
+
+	activations, err := listTLSActivationsByDomain(ctx, conn, previousID)
+	if err != nil {
+		return err
+	}
+	moved := make([]string, 0, len(activations))
+	mtls := map[string]*fastly.TLSMutualAuthentication{}
+	for _, a := range activations {
+		moved = append(moved, a.ID)
+		if a.MutualAuthentication != nil {
+			mtls[a.ID] = &fastly.TLSMutualAuthentication{ID: a.MutualAuthentication.ID}
+		}
+	}
+	sort.Strings(moved)
+
+	err = runTLSActivationOperations(moved, func(activationID string) error {
+		_, err := conn.UpdateTLSActivation(ctx, &fastly.UpdateTLSActivationInput{
+			ID:                   activationID,
+			Certificate:          &fastly.CustomTLSCertificate{ID: id},
+			MutualAuthentication: mtls[activationID],
+		})
+		if err != nil {
+			return fmt.Errorf("error moving TLS activation (%s) to certificate (%s): %w", activationID, id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("rotation of TLS certificate (%s) to (%s) is unfinished, the next apply resumes it: %w", previousID, id, err)
+	}
+
+	// Check the moved activations now use the new certificate, and that no
+	// activations were added to the previous certificate in the meantime.
+	err = runTLSActivationOperations(moved, func(activationID string) error {
+		a, err := conn.GetTLSActivation(ctx, &fastly.GetTLSActivationInput{ID: activationID})
+		if err != nil {
+			return fmt.Errorf("error reading TLS activation (%s): %w", activationID, err)
+		}
+		if a.Certificate == nil || a.Certificate.ID != id {
+			return fmt.Errorf("TLS activation (%s) does not use certificate (%s)", activationID, id)
+		}
+		return nil
+	})
+	if err == nil {
+		var remaining map[string]*fastly.TLSActivation
+		if remaining, err = listTLSActivationsByDomain(ctx, conn, previousID); err == nil && len(remaining) > 0 {
+			domains := make([]string, 0, len(remaining))
+			for domain := range remaining {
+				domains = append(domains, domain)
+			}
+			sort.Strings(domains)
+			err = fmt.Errorf("TLS activations for %s still use certificate (%s)", quoteNames(domains), previousID)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("rotation of TLS certificate (%s) to (%s) is unfinished, the next apply resumes it: %w", previousID, id, err)
+	}
+
+	if err := deleteCustomTLSCertificate(ctx, conn, previousID); err != nil {
+		return fmt.Errorf("rotation of TLS certificate (%s) to (%s) is unfinished, the next apply resumes it: %w", previousID, id, err)
+	}
+	log.Printf("[DEBUG] Rotated TLS certificate (%s) to (%s), moving %d activations", previousID, id, len(moved))
+
+	return d.Set("previous_certificate_id", "")
+}
+
+// createCustomTLSCertificate uploads the certificate in certificate_body and
+// returns its ID.
+func createCustomTLSCertificate(ctx context.Context, conn *fastly.Client, d *schema.ResourceData) (string, error) {
+	input := &fastly.CreateCustomTLSCertificateInput{
+		CertBlob: d.Get("certificate_body").(string),
+	}
+
+	if v, ok := d.GetOk("name"); ok {
+		input.Name = v.(string)
+	}
+
+	output, err := conn.CreateCustomTLSCertificate(ctx, input)
+	if err != nil {
+		// Fastly rejects a certificate whose private key has not been
+		// uploaded, with an error that does not say which key is missing.
+		if keyErr := checkCertificatePrivateKey(ctx, conn, input.CertBlob); keyErr != nil {
+			return "", fmt.Errorf("%w: %s", err, keyErr)
+		}
+		return "", err
+	}
+	return output.ID, nil
+}
+
+// deleteCustomTLSCertificate deletes a certificate, ignoring one that has
+// already been deleted.
+func deleteCustomTLSCertificate(ctx context.Context, conn *fastly.Client, id string) error {
+	err := conn.DeleteCustomTLSCertificate(ctx, &fastly.DeleteCustomTLSCertificateInput{
+		ID: id,
+	})
+	if err != nil {
+		if httpErr, ok := err.(*fastly.HTTPError); ok && httpErr.IsNotFound() {
+			log.Printf("[WARN] TLS certificate (%s) not found, it may already have been deleted", id)
+			return nil
+		}
+		return fmt.Errorf("error deleting TLS certificate (%s): %w", id, err)
+	}
 	return nil
 }
+
+// resourceFastlyTLSCertificateCustomizeDiff plans the certificate's validity
+// window from certificate_body, and warns about or fails the plan for
+// certificates that are about to expire.
+func resourceFastlyTLSCertificateCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta any) error {
+	// Plan a change to resume an unfinished rotation.
+	if previousID := d.Get("previous_certificate_id").(string); previousID != "" {
+		addPlanWarning(ctx, "TLS certificate rotation will be resumed",
+			fmt.Sprintf("The rotation from certificate (%s) is unfinished. Applying moves its remaining TLS activations to certificate (%s) and deletes it.", previousID, d.Id()))
+		if err := d.SetNewComputed("previous_certificate_id"); err != nil {
+			return err
+		}
+	}
+
+	if !d.NewValueKnown("certificate_body") {
+		for _, key := range []string{"not_after", "not_before", "public_key_sha1"} {
+			if err := d.SetNewComputed(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	chain, err := parseCertificateChain(d.Get("certificate_body").(string))
+	if err != nil {
+		return fmt.Errorf("invalid certificate_body: %w", err)
+	}
+	leaf := chain[0]
+	notAfter := leaf.NotAfter.UTC().Format(time.RFC3339)
+
+	if d.HasChange("certificate_body") {
+		if err := d.SetNew("not_before", leaf.NotBefore.UTC().Format(time.RFC3339)); err != nil {
+			return err
+		}
+		if err := d.SetNew("not_after", notAfter); err != nil {
+			return err
+		}
+		if err := d.SetNew("public_key_sha1", certificatePublicKeySHA1(leaf)); err != nil {
+			return err
+		}
+		if err := validateTLSCertificateBody(ctx, chain, meta); err != nil {
+			return err
+		}
+	} else if d.Id() != "" {
+		// Uploading the same certificate again would not renew it, so the
+		// plan fails rather than planning a change that cannot succeed.
+		if err := checkReplaceBeforeExpiry(d.Get("certificate_body").(string), d.Get("replace_before_expiry").(int), time.Now()); err != nil {
+			return err
+		}
+	}
+
+	if days := d.Get("expiry_warning_days").(int); certificateExpiresWithin(leaf, days, time.Now()) {
+		addPlanWarning(ctx, "TLS certificate expires soon",
+			fmt.Sprintf("The certificate for %s expires at %s, within expiry_warning_days (%d days). Update certificate_body with a renewed certificate.", describeCertificate(leaf), notAfter, days))
+	}
+
+	return nil
+}
+
+// validateTLSCertificateBody checks a new certificate_body before it is sent
+// to Fastly, so mistakes are reported at plan time with clear errors.
+func validateTLSCertificateBody(ctx context.Context, chain []*x509.Certificate, meta any) error {
+	if err := checkCertificateChainOrder(chain); err != nil {
+		return err
+	}
+
+	if warning := verifyCertificateChain(chain, nil, time.Now()); warning != "" {
+		addPlanWarning(ctx, "TLS certificate is not issued by a trusted CA", warning)
+	}
+
+	// The private key may be uploaded by a fastly_tls_private_key in the same
+	// apply, so a missing key is only a warning at plan time.
+	if client, ok := meta.(*APIClient); ok {
+		if err := checkCertificatePrivateKey(ctx, client.conn, "", chain...); err != nil {
+			addPlanWarning(ctx, "TLS private key not found", fmt.Sprintf("The certificate will be rejected unless a fastly_tls_private_key uploads its key in this apply: %s.", err))
+		}
+	}
+
+	return nil
+}
+
+// checkCertificatePrivateKey returns an error unless the private key matching
+// the leaf certificate has been uploaded to Fastly. The certificate is parsed
+// from body unless chain is given.
+func checkCertificatePrivateKey(ctx context.Context, conn *fastly.Client, body string, chain ...*x509.Certificate) error {
+	if len(chain) == 0 {
+		var err error
+		if chain, err = parseCertificateChain(body); err != nil {
+			return err
+		}
+	}
+	publicKeySHA1 := certificatePublicKeySHA1(chain[0])
+
+	keys, err := listTLSPrivateKeys(ctx, conn, func(key *fastly.PrivateKey) bool {
+		return key.PublicKeySHA1 == publicKeySHA1
+	})
+	if err != nil {
+		// Leave it to the API to reject the certificate.
+		log.Printf("[WARN] Unable to list TLS private keys: %s", err)
+		return nil
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("no TLS private key matching the certificate for %s (public_key_sha1 %q) has been uploaded to Fastly, upload it with fastly_tls_private_key first", describeCertificate(chain[0]), publicKeySHA1)
+	}
+	return nil
+}
+
+// checkReplaceBeforeExpiry returns an error if the certificate in body
+// expires within replace_before_expiry days of now.
+func checkReplaceBeforeExpiry(body string, days int, now time.Time) error {
+	if days <= 0 {
+		return nil
+	}
+	chain, err := parseCertificateChain(body)
+	if err != nil {
+		return err
+	}
+	leaf := chain[0]
+	if !certificateExpiresWithin(leaf, days, now) {
+		return nil
+	}
+	return fmt.Errorf(
+		"the certificate for %s expires at %s, within replace_before_expiry (%d days). Update certificate_body with a renewed certificate",
+		describeCertificate(leaf), leaf.NotAfter.UTC().Format(time.RFC3339), days,
+	)
+}
@@ -50,6 +50,8 @@ func TestAccFastlyTLSCertificate_withName(t *testing.T) {
 					resource.TestCheckResourceAttrSet(resourceName, "serial_number"),
 					resource.TestCheckResourceAttrSet(resourceName, "signature_algorithm"),
 					resource.TestCheckResourceAttr(resourceName, "domains.#", "1"),
+					resource.TestCheckResourceAttrSet(resourceName, "not_before"),
+					resource.TestCheckResourceAttrSet(resourceName, "not_after"),
 					testAccTLSCertificateExists(resourceName),
 				),
 			},
@@ -61,7 +63,7 @@ func TestAccFastlyTLSCertificate_withName(t *testing.T) {
 				ResourceName:            resourceName,
 				ImportState:             true,
 				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"certificate_body"},
+				ImportStateVerifyIgnore: []string{"certificate_body", "expiry_warning_days", "public_key_sha1", "replace_before_expiry", "rotation"},
 			},
 		},
 	})
@@ -101,6 +103,68 @@ func TestAccFastlyTLSCertificate_withoutName(t *testing.T) {
 	})
 }
 
+func TestAccFastlyTLSCertificate_rotation(t *testing.T) {
+	name := acctest.RandomWithPrefix(testResourcePrefix)
+	domain := fmt.Sprintf("%s.com", name)
+
+	key, cert, cert2, err := generateKeyAndMultipleCerts(domain)
+	require.NoError(t, err)
+
+	var previousID string
+	resourceName := "fastly_tls_certificate.test"
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckTLSCertificateDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTLSCertificateRotation(name, key, cert, domain),
+				Check: resource.ComposeTestCheckFunc(
+					testAccTLSCertificateExists(resourceName),
+					func(s *terraform.State) error {
+						previousID = s.RootModule().Resources[resourceName].Primary.ID
+						return nil
+					},
+				),
+			},
+			{
+				Config: testAccTLSCertificateRotation(name, key, cert2, domain),
+				Check: resource.ComposeTestCheckFunc(
+					testAccTLSCertificateExists(resourceName),
+					resource.TestCheckResourceAttr(resourceName, "previous_certificate_id", ""),
+					func(s *terraform.State) error {
+						id := s.RootModule().Resources[resourceName].Primary.ID
+						if id == previousID {
+							return fmt.Errorf("expected a new certificate, got the previous certificate (%s)", id)
+						}
+
+						conn := testAccProvider.Meta().(*APIClient).conn
+						activation, err := conn.GetTLSActivation(context.TODO(), &fastly.GetTLSActivationInput{
+							ID: s.RootModule().Resources["fastly_tls_activation.test"].Primary.ID,
+						})
+						if err != nil {
+							return err
+						}
+						if activation.Certificate == nil || activation.Certificate.ID != id {
+							return fmt.Errorf("expected TLS activation to use certificate (%s)", id)
+						}
+
+						_, err = conn.GetCustomTLSCertificate(context.TODO(), &fastly.GetCustomTLSCertificateInput{
+							ID: previousID,
+						})
+						if err == nil {
+							return fmt.Errorf("expected previous certificate (%s) to be deleted", previousID)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
 func testAccTLSCertificateExists(resourceName string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		r, ok := s.RootModule().Resources[resourceName]
@@ -120,6 +184,47 @@ func testAccTLSCertificateExists(resourceName string) resource.TestCheckFunc {
 	}
 }
 
+func testAccTLSCertificateRotation(name, key, cert, domain string) string {
+	return fmt.Sprintf(`
+resource "fastly_service_vcl" "test" {
+  name = "%[1]s"
+
+  domain {
+    name = "%[4]s"
+  }
+
+  backend {
+    address = "127.0.0.1"
+    name    = "localhost"
+  }
+
+  force_destroy = true
+}
+
+resource "fastly_tls_private_key" "key" {
+  name = "%[1]s"
+  key_pem = <<EOF
+%[2]s
+EOF
+}
+
+resource "fastly_tls_certificate" "test" {
+  name = "%[1]s"
+  rotation = "replace"
+  certificate_body = <<EOF
+%[3]s
+EOF
+  depends_on = [fastly_tls_private_key.key]
+}
+
+resource "fastly_tls_activation" "test" {
+  certificate_id = fastly_tls_certificate.test.id
+  domain = "%[4]s"
+  depends_on = [fastly_service_vcl.test]
+}
+`, name, key, cert, domain)
+}
+
 func testAccTLSCertificateWithName(keyName string, key string, certName string, cert string) string {
 	return fmt.Sprintf(`
 resource "fastly_tls_private_key" "key" {
@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"log"
 	"sort"
+	"time"
 
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
 	gofastly "github.com/fastly/go-fastly/v17/fastly"
 )
@@ -22,6 +24,7 @@ func resourceFastlyTLSMutualAuthentication() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		CustomizeDiff: resourceFastlyTLSMutualAuthenticationCustomizeDiff,
 		Schema: map[string]*schema.Schema{
 			"activation_ids": {
 				Type:        schema.TypeSet,
@@ -32,10 +35,12 @@ func resourceFastlyTLSMutualAuthentication() *schema.Resource {
 				},
 			},
 			"cert_bundle": {
-				Type:        schema.TypeString,
-				Description: "One or more certificates. Enter each individual certificate blob on a new line. Must be PEM-formatted.",
-				Required:    true,
+				Type:             schema.TypeString,
+				Description:      "One or more CA certificates. Enter each individual certificate blob on a new line. Must be PEM-formatted.",
+				Required:         true,
+				ValidateDiagFunc: validatePEMBlocks("CERTIFICATE"),
 			},
+			"certificates": mtlsBundleCertificatesSchema(),
 			"created_at": {
 				Type:        schema.TypeString,
 				Description: "Date and time in ISO 8601 format.",
@@ -47,6 +52,13 @@ func resourceFastlyTLSMutualAuthentication() *schema.Resource {
 				Optional:    true,
 				Computed:    true,
 			},
+			"expiry_warning_days": {
+				Type:             schema.TypeInt,
+				Description:      "Show a warning when planning if a certificate in `cert_bundle` expires within this many days. `0` disables the warning. Default `30`.",
+				Optional:         true,
+				Default:          30,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+			},
 			"include": {
 				Type:        schema.TypeString,
 				Description: "A comma-separated list used by the Terraform provider during a state refresh to return more data related to your mutual authentication from the Fastly API (permitted values: `tls_activations`).",
@@ -158,6 +170,17 @@ func resourceFastlyTLSMutualAuthenticationRead(ctx context.Context, d *schema.Re
 		return diag.FromErr(err)
 	}
 
+	// The bundle is not returned by the API, e.g. after an import.
+	if bundle := d.Get("cert_bundle").(string); bundle != "" {
+		certs, err := parseCertificateChain(bundle)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("certificates", flattenMTLSBundleCertificates(certs)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	var activations []string
 	for _, a := range tma.Activations {
 		activations = append(activations, a.ID)
@@ -256,3 +279,37 @@ func resourceFastlyTLSMutualAuthenticationDelete(ctx context.Context, d *schema.
 	}
 	return nil
 }
+
+// resourceFastlyTLSMutualAuthenticationCustomizeDiff parses cert_bundle into
+// certificates, rejecting bundles that Fastly would accept but that cannot
+// authenticate clients, and warns about CA certificates that expire soon.
+func resourceFastlyTLSMutualAuthenticationCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, _ any) error {
+	if !d.NewValueKnown("cert_bundle") {
+		return d.SetNewComputed("certificates")
+	}
+
+	certs, err := parseCertificateChain(d.Get("cert_bundle").(string))
+	if err != nil {
+		return fmt.Errorf("invalid cert_bundle: %w", err)
+	}
+
+	now := time.Now()
+	if d.HasChange("cert_bundle") {
+		if err := checkMTLSBundle(certs, now); err != nil {
+			return err
+		}
+		if err := d.SetNew("certificates", flattenMTLSBundleCertificates(certs)); err != nil {
+			return err
+		}
+	}
+
+	days := d.Get("expiry_warning_days").(int)
+	for _, cert := range certs {
+		if cert.IsCA && certificateExpiresWithin(cert, days, now) {
+			addPlanWarning(ctx, "mTLS CA certificate expires soon",
+				fmt.Sprintf("The CA certificate %s in cert_bundle expires at %s, within expiry_warning_days (%d days). Clients with certificates issued by it will be rejected once it expires.", describeCertificate(cert), cert.NotAfter.UTC().Format(time.RFC3339), days))
+		}
+	}
+
+	return nil
+}
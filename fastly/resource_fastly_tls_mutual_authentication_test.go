@@ -65,7 +65,7 @@ func TestAccFastlyMTLS_basic(t *testing.T) {
 				ImportState:       true,
 				ImportStateVerify: true,
 				// These attributes are not stored on the Fastly API and must be ignored.
-				ImportStateVerifyIgnore: []string{"cert_bundle", "activation_id"},
+				ImportStateVerifyIgnore: []string{"cert_bundle", "activation_id", "certificates", "expiry_warning_days"},
 			},
 		},
 	})
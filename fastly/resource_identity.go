@@ -0,0 +1,77 @@
+package fastly
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceWithIdentity adds a resource identity containing the resource `id`
+// to res. Identities allow Terraform to import resources by identity and are
+// required for resources returned by list resources (see list_resources.go).
+//
+// The Create, Read and Update functions are wrapped to keep the identity in
+// sync with the resource ID, and the importer is wrapped so that an import by
+// identity behaves exactly like an import by ID.
+func resourceWithIdentity(res *schema.Resource) *schema.Resource {
+	res.Identity = &schema.ResourceIdentity{
+		SchemaFunc: func() map[string]*schema.Schema {
+			return map[string]*schema.Schema{
+				"id": {
+					Type:              schema.TypeString,
+					RequiredForImport: true,
+					Description:       "The ID of the resource.",
+				},
+			}
+		},
+	}
+
+	res.CreateContext = withIdentity(res.CreateContext)
+	res.ReadContext = withIdentity(res.ReadContext)
+	res.UpdateContext = withIdentity(res.UpdateContext)
+
+	if res.Importer != nil && res.Importer.StateContext != nil {
+		importer := res.Importer.StateContext
+		res.Importer.StateContext = func(ctx context.Context, d *schema.ResourceData, meta any) ([]*schema.ResourceData, error) {
+			if d.Id() == "" {
+				identity, err := d.Identity()
+				if err != nil {
+					return nil, fmt.Errorf("error getting identity: %w", err)
+				}
+				id, ok := identity.Get("id").(string)
+				if !ok || id == "" {
+					return nil, fmt.Errorf("expected identity to contain a non-empty id")
+				}
+				d.SetId(id)
+			}
+			return importer(ctx, d, meta)
+		}
+	}
+
+	return res
+}
+
+// withIdentity wraps a CRUD function so that the resource identity is set
+// from the resource ID after it succeeds.
+func withIdentity[F ~func(context.Context, *schema.ResourceData, any) diag.Diagnostics](fn F) F {
+	if fn == nil {
+		return nil
+	}
+	return func(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+		diags := fn(ctx, d, meta)
+		if diags.HasError() || d.Id() == "" {
+			return diags
+		}
+
+		identity, err := d.Identity()
+		if err != nil {
+			return append(diags, diag.FromErr(err)...)
+		}
+		if err := identity.Set("id", d.Id()); err != nil {
+			return append(diags, diag.FromErr(err)...)
+		}
+		return diags
+	}
+}
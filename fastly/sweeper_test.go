@@ -8,6 +8,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 
+	"github.com/fastly/terraform-provider-fastly/fastly/fakeapi"
 	"github.com/fastly/terraform-provider-fastly/version"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -21,6 +22,24 @@ var sweeperClients map[string]*fastly.Client
 
 func TestMain(m *testing.M) {
 	sweeperClients = make(map[string]*fastly.Client)
+
+	// When FASTLY_TEST_FAKE_API is set, the acceptance tests run against an
+	// in-memory fake of the Fastly API instead of api.fastly.com. The
+	// provider picks the fake up through the FASTLY_API_URL default of
+	// `base_url`.
+	// resource.TestMain exits the process, so the fake runs the tests itself
+	// to be able to close the server afterwards. There is nothing to sweep
+	// in the fake.
+	if os.Getenv("FASTLY_TEST_FAKE_API") != "" {
+		srv := fakeapi.NewServer()
+		_ = os.Setenv("FASTLY_API_URL", srv.URL)
+		_ = os.Setenv("FASTLY_API_KEY", fakeapi.APIKey)
+
+		code := m.Run()
+		srv.Close()
+		os.Exit(code)
+	}
+
 	resource.TestMain(m)
 }
 
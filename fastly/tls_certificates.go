@@ -0,0 +1,145 @@
+package fastly
+
+import (
+	"bytes"
+	"crypto/sha1" // #nosec G505
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// parseCertificateChain parses the PEM-encoded certificates in body, in the
+// order they appear, so the leaf certificate comes first.
+func parseCertificateChain(body string) ([]*x509.Certificate, error) {
+	var chain []*x509.Certificate
+	rest := []byte(body)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse certificate %d in the chain: %w", len(chain)+1, err)
+		}
+		chain = append(chain, cert)
+	}
+	if len(chain) == 0 {
+		return nil, errors.New("no PEM-formatted certificates found")
+	}
+	return chain, nil
+}
+
+// describeCertificate names a certificate for use in diagnostics.
+func describeCertificate(cert *x509.Certificate) string {
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+	if len(cert.DNSNames) > 0 {
+		return cert.DNSNames[0]
+	}
+	return "serial " + cert.SerialNumber.String()
+}
+
+// certificateExpiresWithin reports whether cert expires within the given
+// number of days of now. It is false when days is not positive.
+func certificateExpiresWithin(cert *x509.Certificate, days int, now time.Time) bool {
+	return days > 0 && cert.NotAfter.Before(now.AddDate(0, 0, days))
+}
+
+// certificatePublicKeySHA1 returns the hex-encoded SHA-1 of the certificate's
+// DER-encoded public key, which is how Fastly identifies the matching private
+// key (see public_key_sha1 on fastly_tls_private_key).
+func certificatePublicKeySHA1(cert *x509.Certificate) string {
+	sum := sha1.Sum(cert.RawSubjectPublicKeyInfo) // #nosec G401
+	return hex.EncodeToString(sum[:])
+}
+
+// checkCertificateChainOrder returns an error unless each certificate in the
+// chain is issued by the one following it.
+func checkCertificateChainOrder(chain []*x509.Certificate) error {
+	for i := 0; i+1 < len(chain); i++ {
+		if err := chain[i].CheckSignatureFrom(chain[i+1]); err != nil {
+			return fmt.Errorf(
+				"certificate %d (%s) in certificate_body is not issued by certificate %d (%s). The certificates must be ordered from the leaf certificate to the root: %w",
+				i+1, describeCertificate(chain[i]), i+2, describeCertificate(chain[i+1]), err,
+			)
+		}
+	}
+	return nil
+}
+
+// verifyCertificateChain checks that the chain leads to a trusted root, using
+// the system roots when roots is nil, and returns a warning explaining why it
+// does not. Failures are not errors: a private CA is unknown to the system
+// roots but accepted by Fastly, and the system roots of the machine running
+// Terraform may differ from those of clients.
+func verifyCertificateChain(chain []*x509.Certificate, roots *x509.CertPool, now time.Time) (warning string) {
+	leaf := chain[0]
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	// Expiry is checked separately (see expiry_warning_days), so verify the
+	// chain at a time the leaf certificate is valid.
+	if now.Before(leaf.NotBefore) {
+		now = leaf.NotBefore
+	}
+	if now.After(leaf.NotAfter) {
+		now = leaf.NotAfter
+	}
+
+	_, err := leaf.Verify(x509.VerifyOptions{
+		CurrentTime:   now,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		Roots:         roots,
+	})
+	if err == nil {
+		return ""
+	}
+
+	top := chain[len(chain)-1]
+	if isSelfSignedCertificate(top) {
+		return fmt.Sprintf("The certificate chain ends with %s, which is self-signed but not a trusted root. Clients will only trust the certificate if they trust this CA.", describeCertificate(top))
+	}
+	var unknownAuthority x509.UnknownAuthorityError
+	if errors.As(err, &unknownAuthority) {
+		return fmt.Sprintf("The certificate chain in certificate_body may be incomplete: %s is issued by %q, which is not a trusted root. Unless it is a CA trusted by clients, add its certificate to certificate_body.", describeCertificate(top), top.Issuer.String())
+	}
+	return fmt.Sprintf("Unable to verify the certificate chain in certificate_body: %s.", err)
+}
+
+// isSelfSignedCertificate reports whether cert is signed by its own key.
+func isSelfSignedCertificate(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignatureFrom(cert) == nil
+}
+
+// certificateCoversDomain reports whether domain matches one of the names in
+// a certificate's Subject Alternative Names. A wildcard name such as
+// *.example.com matches exactly one label, so it covers www.example.com but
+// neither example.com nor a.b.example.com.
+func certificateCoversDomain(names []string, domain string) bool {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSuffix(name, "."))
+		if name == domain {
+			return true
+		}
+		if suffix, ok := strings.CutPrefix(name, "*."); ok {
+			if label, rest, found := strings.Cut(domain, "."); found && label != "" && rest == suffix {
+				return true
+			}
+		}
+	}
+	return false
+}
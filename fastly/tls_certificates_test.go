@@ -0,0 +1,133 @@
+package fastly
+
+import (
+	"crypto/sha1" // #nosec G505
+	"crypto/x509"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCertificateChain(t *testing.T) {
+	_, cert, caPEM, err := generateKeyAndCertWithCA("www.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain, err := parseCertificateChain(cert + "\n" + caPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chain) != 2 {
+		t.Fatalf("expected 2 certificates, got %d", len(chain))
+	}
+	if got := describeCertificate(chain[0]); got != "www.example.com" {
+		t.Errorf("expected the leaf certificate first, got %q", got)
+	}
+
+	if _, err := parseCertificateChain("not a certificate"); err == nil {
+		t.Error("expected an error for a body without certificates")
+	}
+}
+
+func TestCheckReplaceBeforeExpiry(t *testing.T) {
+	_, cert, err := generateKeyAndCert("www.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Test certificates are valid for 90 days.
+	now := time.Now()
+
+	if err := checkReplaceBeforeExpiry(cert, 0, now); err != nil {
+		t.Errorf("expected no error when disabled, got %s", err)
+	}
+	if err := checkReplaceBeforeExpiry(cert, 30, now); err != nil {
+		t.Errorf("expected no error 90 days before expiry, got %s", err)
+	}
+	err = checkReplaceBeforeExpiry(cert, 30, now.AddDate(0, 0, 70))
+	if err == nil || !strings.Contains(err.Error(), "within replace_before_expiry (30 days)") {
+		t.Errorf("expected an error 20 days before expiry, got %v", err)
+	}
+}
+
+func TestCertificateChainChecks(t *testing.T) {
+	_, cert, caPEM, err := generateKeyAndCertWithCA("www.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain, err := parseCertificateChain(cert + "\n" + caPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, ca := chain[0], chain[1]
+
+	if err := checkCertificateChainOrder(chain); err != nil {
+		t.Errorf("expected an ordered chain, got %s", err)
+	}
+	if err := checkCertificateChainOrder([]*x509.Certificate{ca, leaf}); err == nil {
+		t.Error("expected an error for a chain in the wrong order")
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	for _, tc := range []struct {
+		name        string
+		chain       []*x509.Certificate
+		roots       *x509.CertPool
+		wantWarning bool
+	}{
+		{name: "trusted root", chain: chain, roots: roots},
+		{name: "trusted root not in chain", chain: chain[:1], roots: roots},
+		{name: "untrusted self-signed root", chain: chain, roots: x509.NewCertPool(), wantWarning: true},
+		{name: "missing intermediate", chain: chain[:1], roots: x509.NewCertPool(), wantWarning: true},
+	} {
+		warning := verifyCertificateChain(tc.chain, tc.roots, time.Now())
+		if (warning != "") != tc.wantWarning {
+			t.Errorf("%s: unexpected warning %q", tc.name, warning)
+		}
+	}
+}
+
+func TestCertificatePublicKeySHA1(t *testing.T) {
+	_, cert, err := generateKeyAndCert("www.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain, err := parseCertificateChain(cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(chain[0].PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha1.Sum(der) // #nosec G401
+	if want, got := hex.EncodeToString(sum[:]), certificatePublicKeySHA1(chain[0]); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestCertificateCoversDomain(t *testing.T) {
+	names := []string{"example.com", "*.example.net", "API.example.org"}
+
+	for domain, want := range map[string]bool{
+		"example.com":       true,
+		"www.example.com":   false,
+		"www.example.net":   true,
+		"WWW.Example.NET":   true,
+		"example.net":       false,
+		"a.b.example.net":   false,
+		"api.example.org":   true,
+		"api.example.org.":  true,
+		"api2.example.org":  false,
+		".example.net":      false,
+		"wwwexample.net":    false,
+		"www.example.net.x": false,
+	} {
+		if got := certificateCoversDomain(names, domain); got != want {
+			t.Errorf("expected %q covered to be %t, got %t", domain, want, got)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package fastly
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+
+	gofastly "github.com/fastly/go-fastly/v17/fastly"
+)
+
+// tokenScopes are the scopes an API token can be granted.
+var tokenScopes = []string{"global", "global:read", "purge_all", "purge_select"}
+
+// impliedTokenScopes maps token scopes to the narrower scopes they include.
+var impliedTokenScopes = map[string][]string{
+	"global":    {"global:read"},
+	"purge_all": {"purge_select"},
+}
+
+// tokenIdentity describes the API token used by the provider and the user it
+// belongs to.
+type tokenIdentity struct {
+	CustomerID string
+	Login      string
+	Name       string
+	Role       string
+	UserID     string
+
+	TokenExpiresAt *time.Time
+	TokenID        string
+	TokenName      string
+	TokenScopes    []string
+}
+
+// fetchTokenIdentity calls the token-self and current-user endpoints.
+func fetchTokenIdentity(ctx context.Context, conn *gofastly.Client) (*tokenIdentity, error) {
+	token, err := conn.GetTokenSelf(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching the API token: %w", err)
+	}
+	user, err := conn.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching the current user: %w", err)
+	}
+
+	id := &tokenIdentity{
+		CustomerID:     gofastly.ToValue(user.CustomerID),
+		Login:          gofastly.ToValue(user.Login),
+		Name:           gofastly.ToValue(user.Name),
+		Role:           gofastly.ToValue(user.Role),
+		UserID:         gofastly.ToValue(user.UserID),
+		TokenExpiresAt: token.ExpiresAt,
+		TokenID:        gofastly.ToValue(token.TokenID),
+		TokenName:      gofastly.ToValue(token.Name),
+	}
+	if token.Scope != nil {
+		id.TokenScopes = strings.Fields(string(*token.Scope))
+	}
+	return id, nil
+}
+
+// currentIdentity returns the identity of the API token. It is only fetched
+// once per provider instance, and is shared by configure-time verification and
+// the fastly_current_user data source. Errors are not cached.
+func (c *APIClient) currentIdentity(ctx context.Context) (*tokenIdentity, error) {
+	c.identityMu.Lock()
+	defer c.identityMu.Unlock()
+
+	if c.identity != nil {
+		return c.identity, nil
+	}
+
+	id, err := fetchTokenIdentity(ctx, c.conn)
+	if err != nil {
+		return nil, err
+	}
+	c.identity = id
+	return id, nil
+}
+
+// verify checks the token belongs to the expected customer, has the required
+// scopes, and is not about to expire. A token that expires within
+// expiryWarning of now is reported as a warning.
+func (id *tokenIdentity) verify(expectedCustomerID string, requiredScopes []string, expiryWarning time.Duration, now time.Time) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if expectedCustomerID != "" && id.CustomerID != expectedCustomerID {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "API token belongs to an unexpected Fastly customer",
+			Detail: fmt.Sprintf(
+				"The API token belongs to user %q of customer %q, but expected_customer_id is %q. Check that the provider is configured with the token for the right account.",
+				id.Login, id.CustomerID, expectedCustomerID,
+			),
+		})
+	}
+
+	if missing := id.missingScopes(requiredScopes); len(missing) > 0 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "API token is missing required scopes",
+			Detail: fmt.Sprintf(
+				"The API token %q is missing the required scopes %s. Its scopes are %s.",
+				id.TokenName, strings.Join(missing, ", "), strings.Join(id.TokenScopes, ", "),
+			),
+		})
+	}
+
+	if id.TokenExpiresAt != nil && expiryWarning > 0 {
+		if remaining := id.TokenExpiresAt.Sub(now); remaining < expiryWarning {
+			detail := fmt.Sprintf("The API token %q expires in %s, at %s.", id.TokenName, remaining.Round(time.Minute), id.TokenExpiresAt.UTC().Format(time.RFC3339))
+			if remaining <= 0 {
+				detail = fmt.Sprintf("The API token %q expired at %s.", id.TokenName, id.TokenExpiresAt.UTC().Format(time.RFC3339))
+			}
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "API token expires soon",
+				Detail:   detail + " Create a new token on the Personal API Tokens page: https://manage.fastly.com/account/personal/tokens",
+			})
+		}
+	}
+
+	return diags
+}
+
+// missingScopes returns the required scopes the token does not have, taking
+// scopes that include narrower ones into account.
+func (id *tokenIdentity) missingScopes(required []string) []string {
+	granted := map[string]bool{}
+	for _, scope := range id.TokenScopes {
+		granted[scope] = true
+		for _, implied := range impliedTokenScopes[scope] {
+			granted[implied] = true
+		}
+	}
+
+	var missing []string
+	for _, scope := range required {
+		if !granted[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
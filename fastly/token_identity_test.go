@@ -0,0 +1,86 @@
+package fastly
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+func TestTokenIdentityMissingScopes(t *testing.T) {
+	for _, tc := range []struct {
+		granted  []string
+		required []string
+		want     []string
+	}{
+		{granted: []string{"global"}, required: []string{"global"}},
+		{granted: []string{"global"}, required: []string{"global:read"}},
+		{granted: []string{"purge_all"}, required: []string{"purge_select"}},
+		{granted: []string{"global:read"}, required: []string{"global"}, want: []string{"global"}},
+		{granted: []string{"purge_select", "global:read"}, required: []string{"purge_all", "global", "global:read"}, want: []string{"global", "purge_all"}},
+		{granted: nil, required: []string{"purge_select"}, want: []string{"purge_select"}},
+	} {
+		id := &tokenIdentity{TokenScopes: tc.granted}
+		if diff := cmp.Diff(tc.want, id.missingScopes(tc.required)); diff != "" {
+			t.Errorf("unexpected missing scopes for %v required %v (-want +got):\n%s", tc.granted, tc.required, diff)
+		}
+	}
+}
+
+func TestTokenIdentityVerify(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(48 * time.Hour)
+	expired := now.Add(-time.Hour)
+
+	for _, tc := range []struct {
+		name               string
+		expiresAt          *time.Time
+		expectedCustomerID string
+		requiredScopes     []string
+		expiryWarning      time.Duration
+		want               []diag.Severity
+		wantSummary        string
+	}{
+		{name: "nothing to verify"},
+		{name: "expected customer", expectedCustomerID: "cust123"},
+		{name: "unexpected customer", expectedCustomerID: "other", want: []diag.Severity{diag.Error}, wantSummary: "unexpected Fastly customer"},
+		{name: "granted scopes", requiredScopes: []string{"global:read"}},
+		{name: "missing scopes", requiredScopes: []string{"purge_all"}, want: []diag.Severity{diag.Error}, wantSummary: "missing required scopes"},
+		{name: "expires outside the window", expiresAt: &expiresAt, expiryWarning: 24 * time.Hour},
+		{name: "expires inside the window", expiresAt: &expiresAt, expiryWarning: 72 * time.Hour, want: []diag.Severity{diag.Warning}, wantSummary: "expires soon"},
+		{name: "expired", expiresAt: &expired, expiryWarning: time.Hour, want: []diag.Severity{diag.Warning}, wantSummary: "expires soon"},
+		{name: "no expiry", expiryWarning: 72 * time.Hour},
+		{
+			name:               "several problems",
+			expectedCustomerID: "other",
+			requiredScopes:     []string{"purge_all"},
+			expiresAt:          &expiresAt,
+			expiryWarning:      72 * time.Hour,
+			want:               []diag.Severity{diag.Error, diag.Error, diag.Warning},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			id := &tokenIdentity{
+				CustomerID:     "cust123",
+				Login:          "user@example.com",
+				TokenExpiresAt: tc.expiresAt,
+				TokenName:      "terraform",
+				TokenScopes:    []string{"global"},
+			}
+			diags := id.verify(tc.expectedCustomerID, tc.requiredScopes, tc.expiryWarning, now)
+
+			var got []diag.Severity
+			for _, d := range diags {
+				got = append(got, d.Severity)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("unexpected diagnostics (-want +got):\n%s", diff)
+			}
+			if tc.wantSummary != "" && !strings.Contains(diags[0].Summary, tc.wantSummary) {
+				t.Errorf("expected summary containing %q, got %q", tc.wantSummary, diags[0].Summary)
+			}
+		})
+	}
+}
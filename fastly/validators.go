@@ -3,7 +3,9 @@ package fastly
 import (
 	"encoding/pem"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -225,6 +227,74 @@ func validatePEMBlocks(pemType string) schema.SchemaValidateDiagFunc {
 	})
 }
 
+func validateActivationWindowTime() schema.SchemaValidateDiagFunc {
+	return func(i any, p cty.Path) diag.Diagnostics {
+		v, ok := i.(string)
+		if !ok {
+			return diag.Errorf("expected type of %q to be string", renderAttributePath(p))
+		}
+		if _, err := parseActivationWindowTime(v); err != nil {
+			return diag.FromErr(err)
+		}
+		return nil
+	}
+}
+
+func validateTimezone() schema.SchemaValidateDiagFunc {
+	return func(i any, p cty.Path) diag.Diagnostics {
+		v, ok := i.(string)
+		if !ok {
+			return diag.Errorf("expected type of %q to be string", renderAttributePath(p))
+		}
+		if _, err := time.LoadLocation(v); err != nil {
+			return diag.Errorf("expected %q to be an IANA time zone such as Europe/London, got %q", renderAttributePath(p), v)
+		}
+		return nil
+	}
+}
+
+func validateDuration() schema.SchemaValidateDiagFunc {
+	return func(i any, p cty.Path) diag.Diagnostics {
+		v, ok := i.(string)
+		if !ok {
+			return diag.Errorf("expected type of %q to be string", renderAttributePath(p))
+		}
+		if d, err := time.ParseDuration(v); err != nil || d < 0 {
+			return diag.Errorf("expected %q to be a positive duration such as 168h, got %q", renderAttributePath(p), v)
+		}
+		return nil
+	}
+}
+
+// validateNGWAFRuleExpression returns a schema validation function that
+// checks whether a string is a valid NGWAF rule expression, reporting the
+// position of any syntax error.
+func validateNGWAFRuleExpression() schema.SchemaValidateDiagFunc {
+	return func(i any, p cty.Path) diag.Diagnostics {
+		v, ok := i.(string)
+		if !ok {
+			return diag.Errorf("expected type of %q to be string", renderAttributePath(p))
+		}
+		if _, err := parseNGWAFRuleExpression(v); err != nil {
+			return diag.Errorf("invalid %s at %s", renderAttributePath(p), err)
+		}
+		return nil
+	}
+}
+
+func validateNGWAFWorkspaceSnapshot() schema.SchemaValidateDiagFunc {
+	return func(i any, p cty.Path) diag.Diagnostics {
+		v, ok := i.(string)
+		if !ok {
+			return diag.Errorf("expected type of %q to be string", renderAttributePath(p))
+		}
+		if _, err := parseNGWAFWorkspaceSnapshot(v); err != nil {
+			return diag.Errorf("%s: %s", renderAttributePath(p), err)
+		}
+		return nil
+	}
+}
+
 func validateStringTrimmed(i any, path cty.Path) diag.Diagnostics {
 	v := i.(string)
 	attr := path[len(path)-1].(cty.GetAttrStep)
@@ -234,3 +304,97 @@ func validateStringTrimmed(i any, path cty.Path) diag.Diagnostics {
 
 	return nil
 }
+
+// checkShieldPOP checks a `shield` value against the Fastly POPs returned by
+// GET /datacenters. It returns an error for values that do not name a shield
+// POP, suggesting the closest shield names, and a warning for values that
+// name a POP which is not available for shielding.
+func checkShieldPOP(pops []gofastly.Datacenter, shield string) (warning string, err error) {
+	var shields []string
+	for _, pop := range pops {
+		name := gofastly.ToValue(pop.Shield)
+		if name == shield {
+			return "", nil
+		}
+		if name != "" {
+			shields = append(shields, name)
+		}
+	}
+
+	for _, pop := range pops {
+		if !strings.EqualFold(gofastly.ToValue(pop.Code), shield) {
+			continue
+		}
+		if name := gofastly.ToValue(pop.Shield); name != "" {
+			return "", fmt.Errorf("%q is a POP code, not a shield name: use %q for the %s POP", shield, name, gofastly.ToValue(pop.Name))
+		}
+		return fmt.Sprintf("The %s POP (%s) is not available for shielding, so the Fastly API is likely to reject it.", gofastly.ToValue(pop.Name), shield), nil
+	}
+
+	err = fmt.Errorf("%q is not a known shield POP", shield)
+	if suggestions := closestMatches(shield, shields); len(suggestions) > 0 {
+		err = fmt.Errorf("%w, did you mean %s?", err, strings.Join(suggestions, ", "))
+	}
+	return "", err
+}
+
+// closestMatches returns up to three of candidates that are closest to
+// value by edit distance, ignoring candidates that are too different to be a
+// plausible typo.
+func closestMatches(value string, candidates []string) []string {
+	type suggestion struct {
+		name     string
+		distance int
+	}
+
+	limit := max(2, len(value)/3)
+
+	var suggestions []suggestion
+	for _, c := range candidates {
+		if d := levenshteinDistance(strings.ToLower(value), strings.ToLower(c)); d <= limit {
+			suggestions = append(suggestions, suggestion{c, d})
+		}
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].distance != suggestions[j].distance {
+			return suggestions[i].distance < suggestions[j].distance
+		}
+		return suggestions[i].name < suggestions[j].name
+	})
+
+	var result []string
+	for i, s := range suggestions {
+		if i == 3 {
+			break
+		}
+		result = append(result, fmt.Sprintf("%q", s.name))
+	}
+	return result
+}
+
+// levenshteinDistance returns the number of single character insertions,
+// deletions and substitutions needed to turn a into b.
+func levenshteinDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr := make([]int, len(rb)+1)
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev = curr
+	}
+
+	return prev[len(rb)]
+}
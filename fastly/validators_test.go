@@ -427,3 +427,38 @@ func TestValidateMultiplePEMBlocks(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckShieldPOP(t *testing.T) {
+	pops := []gofastly.Datacenter{
+		{Code: gofastly.ToPointer("IAD"), Name: gofastly.ToPointer("Ashburn"), Shield: gofastly.ToPointer("iad-va-us")},
+		{Code: gofastly.ToPointer("BWI"), Name: gofastly.ToPointer("Baltimore"), Shield: gofastly.ToPointer("bwi-va-us")},
+		{Code: gofastly.ToPointer("LHR"), Name: gofastly.ToPointer("London"), Shield: gofastly.ToPointer("london-uk")},
+		{Code: gofastly.ToPointer("XYZ"), Name: gofastly.ToPointer("Nowhere")},
+	}
+
+	for name, testcase := range map[string]struct {
+		shield      string
+		wantWarning bool
+		wantError   string
+	}{
+		"valid shield":   {shield: "iad-va-us"},
+		"typo":           {shield: "iad-va-uk", wantError: `"iad-va-uk" is not a known shield POP, did you mean "iad-va-us"?`},
+		"unknown":        {shield: "mars-1", wantError: `"mars-1" is not a known shield POP`},
+		"pop code":       {shield: "lhr", wantError: `"lhr" is a POP code, not a shield name: use "london-uk" for the London POP`},
+		"not a shield":   {shield: "XYZ", wantWarning: true},
+		"case sensitive": {shield: "IAD-VA-US", wantError: `"IAD-VA-US" is not a known shield POP, did you mean "iad-va-us", "bwi-va-us"?`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			warning, err := checkShieldPOP(pops, testcase.shield)
+			if (warning != "") != testcase.wantWarning {
+				t.Errorf("unexpected warning: %q", warning)
+			}
+			switch {
+			case testcase.wantError == "" && err != nil:
+				t.Errorf("unexpected error: %s", err)
+			case testcase.wantError != "" && (err == nil || err.Error() != testcase.wantError):
+				t.Errorf("expected error %q, got %v", testcase.wantError, err)
+			}
+		})
+	}
+}
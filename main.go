@@ -2,23 +2,40 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
+	"io"
 	"log"
+	"os"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
 
+	gofastly "github.com/fastly/go-fastly/v17/fastly"
+
 	"github.com/fastly/terraform-provider-fastly/fastly"
+	"github.com/fastly/terraform-provider-fastly/version"
 )
 
 const noLogPrefix = 0
 
 func main() {
+	// The export subcommand runs standalone and never serves the plugin.
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		os.Exit(runExport(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	var debugMode bool
 
 	flag.BoolVar(&debugMode, "debug", false, "set to true to run the provider with support for debuggers like delve")
 	flag.Parse()
 
-	opts := &plugin.ServeOpts{ProviderFunc: fastly.Provider}
+	providerServer, err := fastly.ProviderServerFactory(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := &plugin.ServeOpts{GRPCProviderFunc: providerServer}
 
 	// Prevent logger from prepending date/time to logs, which breaks log-level parsing/filtering
 	log.SetFlags(noLogPrefix)
@@ -29,3 +46,67 @@ func main() {
 
 	plugin.Serve(opts)
 }
+
+// runExport implements `terraform-provider-fastly export`, which writes HCL
+// for existing Fastly services to stdout and returns the process exit code.
+func runExport(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(stderr, "Usage: terraform-provider-fastly export [flags] [SERVICE_ID...]")
+		_, _ = fmt.Fprintln(stderr)
+		_, _ = fmt.Fprintln(stderr, "Writes fastly_service_vcl/fastly_service_compute resources, their import blocks")
+		_, _ = fmt.Fprintln(stderr, "and companion dictionary item, ACL entry and dynamic snippet resources to stdout.")
+		_, _ = fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+
+	baseURL := os.Getenv("FASTLY_API_URL")
+	if baseURL == "" {
+		baseURL = gofastly.DefaultEndpoint
+	}
+
+	var opts fastly.ExportOptions
+	// The environment variable is read after parsing rather than used as the
+	// flag's default, so that the usage message does not print the key.
+	apiKey := fs.String("api-key", "", "Fastly API key (default $FASTLY_API_KEY)")
+	url := fs.String("base-url", baseURL, "Fastly API URL (default $FASTLY_API_URL)")
+	fs.BoolVar(&opts.All, "all", false, "export every service the API key can read")
+	fs.IntVar(&opts.Version, "version", 0, "export a specific service version instead of the active version")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	opts.ServiceIDs = fs.Args()
+	if *apiKey == "" {
+		*apiKey = os.Getenv("FASTLY_API_KEY")
+	}
+
+	if !opts.All && len(opts.ServiceIDs) == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	ctx := context.Background()
+	config := fastly.Config{
+		APIKey:    *apiKey,
+		BaseURL:   *url,
+		UserAgent: fmt.Sprintf("%s/%s", fastly.TerraformProviderProductUserAgent, version.ProviderVersion),
+		Context:   ctx,
+	}
+
+	client, diags := config.Client()
+	if diags.HasError() {
+		for _, d := range diags {
+			_, _ = fmt.Fprintf(stderr, "Error: %s\n", d.Summary)
+		}
+		return 1
+	}
+
+	if err := fastly.Export(ctx, client, stdout, opts); err != nil {
+		_, _ = fmt.Fprintf(stderr, "Error: %s\n", err)
+		return 1
+	}
+
+	return 0
+}